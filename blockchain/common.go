@@ -3,7 +3,10 @@
 package blockchain
 
 import (
+	"encoding/json"
 	"errors"
+
+	"github.com/smartcontractkit/chainlink/core/utils"
 )
 
 var (
@@ -11,7 +14,19 @@ var (
 	ErrSubscriberType = errors.New("unknown subscriber type")
 )
 
+// Params are the keeper job params, the optional per-job
+// options are left unchanged or defaulted when nil. A null
+// PayloadTemplate resets it to the default payload.
 type Params struct {
-	Address string `json:"address"`
-	From    string `json:"from"`
+	Address            string          `json:"address"`
+	From               string          `json:"from"`
+	TurnTaking         string          `json:"turnTaking,omitempty"`
+	GasBuffer          *uint32         `json:"gasBuffer,omitempty"`
+	MaxGasPrice        *utils.Big      `json:"maxGasPrice,omitempty"`
+	AllowedUpkeeps     []uint64        `json:"allowedUpkeeps,omitempty"`
+	DeniedUpkeeps      []uint64        `json:"deniedUpkeeps,omitempty"`
+	DryRun             *bool           `json:"dryRun,omitempty"`
+	CheckConfirmations *uint32         `json:"checkConfirmations,omitempty"`
+	PayloadTemplate    json.RawMessage `json:"payloadTemplate,omitempty"`
+	JobEndpoint        string          `json:"jobEndpoint,omitempty"`
 }
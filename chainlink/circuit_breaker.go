@@ -0,0 +1,178 @@
+package chainlink
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/smartcontractkit/chainlink/core/logger"
+)
+
+var (
+	promCircuitState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "keeper_chainlink_circuit_state",
+		Help: "The state of the circuit breaker around each Chainlink node, 1 for the current state",
+	}, []string{"node", "state"})
+	promCircuitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "keeper_chainlink_circuit_rejections",
+		Help: "The number of requests to each Chainlink node failed fast by the open circuit breaker",
+	}, []string{"node"})
+)
+
+// ErrCircuitOpen is returned instead of calling the Chainlink node while the circuit is open
+var ErrCircuitOpen = errors.New("circuit breaker open, the Chainlink node is unavailable")
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState string
+
+const (
+	// CircuitClosed lets every request through
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen fails every request fast
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe through, which closes the circuit if it succeeds
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitBreaker is a Client which stops calling the Chainlink node after threshold
+// consecutive failures. While open, requests fail with ErrCircuitOpen until the
+// openTimeout elapses, then a single probe is let through to decide whether to close
+// the circuit again. Responses with a status code below 500 don't count as failures.
+type CircuitBreaker interface {
+	Client
+	State() (CircuitState, time.Time)
+}
+
+// NewCircuitBreaker wraps the client of the named node with a CircuitBreaker,
+// a zero threshold never opens it
+func NewCircuitBreaker(name string, client Client, threshold uint, openTimeout time.Duration) CircuitBreaker {
+	cb := &circuitBreaker{
+		name:        name,
+		client:      client,
+		threshold:   threshold,
+		openTimeout: openTimeout,
+	}
+	cb.setState(CircuitClosed)
+	return cb
+}
+
+type circuitBreaker struct {
+	name        string
+	client      Client
+	threshold   uint
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures uint
+	// openedAt is when the circuit last opened
+	openedAt time.Time
+	probing  bool
+}
+
+func (cb *circuitBreaker) TriggerJob(jobId string, jobEndpoint JobEndpoint, idempotencyKey string, data []byte) (string, error) {
+	if !cb.allow() {
+		return "", ErrCircuitOpen
+	}
+	runID, err := cb.client.TriggerJob(jobId, jobEndpoint, idempotencyKey, data)
+	cb.record(err)
+	return runID, err
+}
+
+func (cb *circuitBreaker) RunStatus(jobId string, jobEndpoint JobEndpoint, runID string) (Run, error) {
+	if !cb.allow() {
+		return Run{}, ErrCircuitOpen
+	}
+	run, err := cb.client.RunStatus(jobId, jobEndpoint, runID)
+	cb.record(err)
+	return run, err
+}
+
+// HasAPIToken doesn't call the Chainlink node, so it isn't failed fast while the circuit is open
+func (cb *circuitBreaker) HasAPIToken(jobId string) bool {
+	return cb.client.HasAPIToken(jobId)
+}
+
+// State returns the current state of the circuit and the time it last opened
+func (cb *circuitBreaker) State() (CircuitState, time.Time) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state, cb.openedAt
+}
+
+// allow returns whether a request can be sent to the node, moving
+// an open circuit to half open once the open timeout has elapsed
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		if time.Since(cb.openedAt) < cb.openTimeout {
+			break
+		}
+		cb.setState(CircuitHalfOpen)
+		cb.probing = true
+		return true
+	case CircuitHalfOpen:
+		if cb.probing {
+			break
+		}
+		cb.probing = true
+		return true
+	default:
+		return true
+	}
+	promCircuitRejections.WithLabelValues(cb.name).Inc()
+	return false
+}
+
+// record updates the circuit with the outcome of a request
+func (cb *circuitBreaker) record(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if !isNodeFailure(err) {
+		cb.failures = 0
+		cb.probing = false
+		if cb.state != CircuitClosed {
+			logger.Infow("Chainlink node circuit breaker closed", "node", cb.name)
+			cb.setState(CircuitClosed)
+		}
+		return
+	}
+
+	cb.failures++
+	if cb.state == CircuitHalfOpen || (cb.threshold > 0 && cb.failures >= cb.threshold && cb.state == CircuitClosed) {
+		logger.Warnw("Chainlink node circuit breaker opened", "node", cb.name, "failures", cb.failures, "openTimeout", cb.openTimeout, "error", err)
+		cb.probing = false
+		cb.openedAt = time.Now()
+		cb.setState(CircuitOpen)
+	}
+}
+
+func (cb *circuitBreaker) setState(state CircuitState) {
+	cb.state = state
+	for _, s := range []CircuitState{CircuitClosed, CircuitOpen, CircuitHalfOpen} {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		promCircuitState.WithLabelValues(cb.name, string(s)).Set(value)
+	}
+}
+
+// isNodeFailure returns whether the error means that the node is unhealthy,
+// as opposed to rejecting the request itself
+func isNodeFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var statusErr StatusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package chainlink
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient answers every request with err, counting the calls
+type fakeClient struct {
+	err   error
+	calls int
+}
+
+func (c *fakeClient) TriggerJob(string, JobEndpoint, string, []byte) (string, error) {
+	c.calls++
+	return "", c.err
+}
+
+func (c *fakeClient) RunStatus(string, JobEndpoint, string) (Run, error) {
+	c.calls++
+	return Run{}, c.err
+}
+
+func (c *fakeClient) HasAPIToken(string) bool {
+	return true
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	node := &fakeClient{err: errors.New("connection refused")}
+	cb := NewCircuitBreaker("test", node, 3, 50*time.Millisecond)
+
+	t.Run("opens after consecutive failures", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			_, err := cb.TriggerJob(jobId, "", "", nil)
+			require.Equal(t, node.err, err)
+		}
+		state, openedAt := cb.State()
+		assert.Equal(t, CircuitOpen, state)
+		assert.False(t, openedAt.IsZero())
+	})
+
+	t.Run("fails fast while open", func(t *testing.T) {
+		_, err := cb.TriggerJob(jobId, "", "", nil)
+		require.Equal(t, ErrCircuitOpen, err)
+		_, err = cb.RunStatus(jobId, "", "1")
+		require.Equal(t, ErrCircuitOpen, err)
+		assert.Equal(t, 3, node.calls)
+	})
+
+	t.Run("reopens if the probe fails", func(t *testing.T) {
+		time.Sleep(60 * time.Millisecond)
+		_, err := cb.TriggerJob(jobId, "", "", nil)
+		require.Equal(t, node.err, err)
+		assert.Equal(t, 4, node.calls)
+		state, _ := cb.State()
+		assert.Equal(t, CircuitOpen, state)
+	})
+
+	t.Run("closes if the probe succeeds", func(t *testing.T) {
+		time.Sleep(60 * time.Millisecond)
+		node.err = StatusCodeError{StatusCode: http.StatusNotFound}
+		_, err := cb.TriggerJob(jobId, "", "", nil)
+		require.True(t, IsJobNotFound(err))
+		state, _ := cb.State()
+		assert.Equal(t, CircuitClosed, state)
+	})
+}
+
+func TestCircuitBreaker_HalfOpenLetsOneProbeThrough(t *testing.T) {
+	node := &fakeClient{err: StatusCodeError{StatusCode: http.StatusBadGateway}}
+	cb := NewCircuitBreaker("test", node, 1, 0).(*circuitBreaker)
+
+	_, _ = cb.TriggerJob(jobId, "", "", nil)
+	require.True(t, cb.allow())
+	state, _ := cb.State()
+	assert.Equal(t, CircuitHalfOpen, state)
+	assert.False(t, cb.allow())
+}
+
+func TestCircuitBreaker_ZeroThresholdNeverOpens(t *testing.T) {
+	node := &fakeClient{err: errors.New("connection refused")}
+	cb := NewCircuitBreaker("test", node, 0, time.Hour)
+	for i := 0; i < 10; i++ {
+		_, _ = cb.TriggerJob(jobId, "", "", nil)
+	}
+	state, _ := cb.State()
+	assert.Equal(t, CircuitClosed, state)
+	assert.Equal(t, 10, node.calls)
+}
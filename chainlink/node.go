@@ -5,13 +5,18 @@ package chainlink
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/avast/retry-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/smartcontractkit/chainlink/core/logger"
 )
 
@@ -20,8 +25,69 @@ import (
 const (
 	externalInitiatorAccessKeyHeader = "X-Chainlink-EA-AccessKey"
 	externalInitiatorSecretHeader    = "X-Chainlink-EA-Secret"
+	idempotencyKeyHeader             = "Idempotency-Key"
+	apiKeyHeader                     = "X-API-KEY"
+	apiSecretHeader                  = "X-API-SECRET"
 )
 
+var (
+	promTriggerJobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "keeper_trigger_job_duration_seconds",
+		Help: "The latency of job run trigger requests to the Chainlink node, by response status",
+	}, []string{"status"})
+	promTriggerJobRetries = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "keeper_trigger_job_retries",
+		Help: "The number of failed job run trigger attempts, each of which is retried until the attempts are exhausted",
+	})
+)
+
+// StatusCodeError is returned when the Chainlink node responds to
+// a job run trigger with an error status code.
+type StatusCodeError struct {
+	StatusCode int
+}
+
+func (e StatusCodeError) Error() string {
+	return fmt.Sprintf("received faulty status code: %v", e.StatusCode)
+}
+
+// IsJobNotFound returns whether the error means that the job
+// doesn't exist on the Chainlink node (anymore).
+func IsJobNotFound(err error) bool {
+	var statusErr StatusCodeError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	return statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusGone
+}
+
+// JobEndpoint selects the Chainlink node API used to trigger job runs
+type JobEndpoint string
+
+const (
+	// LegacyJobEndpoint triggers runs of JSON job specs through /v2/specs/:id/runs
+	LegacyJobEndpoint JobEndpoint = "legacy"
+	// WebhookJobEndpoint triggers runs of v2 webhook jobs through /v2/jobs/:id/runs
+	WebhookJobEndpoint JobEndpoint = "webhook"
+)
+
+// ParseJobEndpoint returns the JobEndpoint with the given name
+func ParseJobEndpoint(name string) (JobEndpoint, error) {
+	switch endpoint := JobEndpoint(name); endpoint {
+	case LegacyJobEndpoint, WebhookJobEndpoint:
+		return endpoint, nil
+	default:
+		return "", fmt.Errorf("unknown job endpoint %q, must be %s or %s", name, LegacyJobEndpoint, WebhookJobEndpoint)
+	}
+}
+
+func (e JobEndpoint) runsPath(jobID string) string {
+	if e == WebhookJobEndpoint {
+		return fmt.Sprintf("/v2/jobs/%s/runs", jobID)
+	}
+	return fmt.Sprintf("/v2/specs/%s/runs", jobID)
+}
+
 type RetryConfig struct {
 	Timeout  time.Duration
 	Attempts uint
@@ -29,83 +95,142 @@ type RetryConfig struct {
 }
 
 type Client interface {
-	TriggerJob(jobId string, data []byte) error
+	TriggerJob(jobId string, jobEndpoint JobEndpoint, idempotencyKey string, data []byte) (runID string, err error)
+	RunStatus(jobId string, jobEndpoint JobEndpoint, runID string) (Run, error)
+	// HasAPIToken returns whether the status of the job's runs can be read
+	HasAPIToken(jobId string) bool
 }
 
+// NewClient returns a Client triggering job runs through the defaultJobEndpoint
+// unless another endpoint is given for a job. The status of runs is read with
+// the apiToken. Requests over HTTPS use the tlsConfig, or the default TLS config
+// if it is nil.
 func NewClient(
 	accessKey string,
 	accessSecret string,
 	endpoint url.URL,
+	defaultJobEndpoint JobEndpoint,
+	authMode AuthMode,
+	apiToken APIToken,
+	tlsConfig *tls.Config,
 	retry RetryConfig,
 ) Client {
+	var transport http.RoundTripper
+	if tlsConfig != nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = tlsConfig
+		transport = t
+	}
 	return client{
-		accessKey:    accessKey,
-		accessSecret: accessSecret,
-		endpoint:     endpoint,
-		retry:        retry,
+		accessKey:          accessKey,
+		accessSecret:       accessSecret,
+		endpoint:           endpoint,
+		defaultJobEndpoint: defaultJobEndpoint,
+		authMode:           authMode,
+		apiToken:           apiToken,
+		transport:          transport,
+		retry:              retry,
 	}
 }
 
 // Node encapsulates all the configuration
 // necessary to interact with a Chainlink node.
 type client struct {
-	accessKey    string
-	accessSecret string
-	endpoint     url.URL
-	retry        RetryConfig
+	accessKey          string
+	accessSecret       string
+	endpoint           url.URL
+	defaultJobEndpoint JobEndpoint
+	authMode           AuthMode
+	apiToken           APIToken
+	// transport is nil to use the default transport
+	transport http.RoundTripper
+	retry     RetryConfig
 }
 
-// TriggerJob wil send a job run trigger for the
-// provided jobId.
-func (cl client) TriggerJob(jobId string, data []byte) error {
+// TriggerJob wil send a job run trigger for the provided jobId through
+// the jobEndpoint, or the client's default endpoint if it is empty. Both
+// endpoints authenticate the external initiator with the same headers.
+// Every attempt is a new request, signed again in the HMAC auth mode.
+// The idempotencyKey, unless empty, is sent with every attempt so that the
+// node can discard retries of a trigger it already accepted.
+// The run ID is empty if it can't be found in the response.
+func (cl client) TriggerJob(jobId string, jobEndpoint JobEndpoint, idempotencyKey string, data []byte) (string, error) {
 	logger.Infof("Sending a job run trigger to %s for job %s\n", cl.endpoint.String(), jobId)
 
+	if jobEndpoint == "" {
+		jobEndpoint = cl.defaultJobEndpoint
+	}
 	u := cl.endpoint
-	u.Path = fmt.Sprintf("/v2/specs/%s/runs", jobId)
+	u.Path = jobEndpoint.runsPath(jobId)
 
-	request, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(data))
-	if err != nil {
-		return err
+	newRequest := func(ctx context.Context) (*http.Request, error) {
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Content-Type", "application/json")
+		if idempotencyKey != "" {
+			request.Header.Set(idempotencyKeyHeader, idempotencyKey)
+		}
+		return request, cl.authenticate(request, data)
 	}
 
-	request.Header.Set("Content-Type", "application/json")
-	request.Header.Add(externalInitiatorAccessKeyHeader, cl.accessKey)
-	request.Header.Add(externalInitiatorSecretHeader, cl.accessSecret)
-
-	_, statusCode, err := cl.retry.withRetry(&http.Client{}, request)
+	body, statusCode, err := cl.retry.withRetry(&http.Client{Transport: cl.transport}, newRequest)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if statusCode >= 400 {
-		return fmt.Errorf("received faulty status code: %v", statusCode)
+		return "", StatusCodeError{StatusCode: statusCode}
 	}
 
+	runID, err := parseRunID(body)
+	if err != nil {
+		logger.Warnf("unable to find the run ID of job %s in the response: %v", jobId, err)
+	}
+	return runID, nil
+}
+
+// authenticate sets the access key on the request along with the secret, or
+// in the HMAC auth mode with the signature of the request and its body
+func (cl client) authenticate(request *http.Request, body []byte) error {
+	request.Header.Add(externalInitiatorAccessKeyHeader, cl.accessKey)
+	if cl.authMode == HMACAuth {
+		return SignRequest(request, cl.accessSecret, body, time.Now())
+	}
+	request.Header.Add(externalInitiatorSecretHeader, cl.accessSecret)
 	return nil
 }
 
-func (config RetryConfig) withRetry(client *http.Client, request *http.Request) (responseBody []byte, statusCode int, err error) {
+// withRetry sends the request returned by newRequest until it succeeds or the attempts
+// are exhausted, a new request is created for every attempt with its own timeout
+func (config RetryConfig) withRetry(client *http.Client, newRequest func(context.Context) (*http.Request, error)) (responseBody []byte, statusCode int, err error) {
 	err = retry.Do(
 		func() error {
 			ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
 			defer cancel()
-			requestWithTimeout := request.Clone(ctx)
+			requestWithTimeout, e := newRequest(ctx)
+			if e != nil {
+				return retry.Unrecoverable(e)
+			}
 
 			start := time.Now()
 
 			r, e := client.Do(requestWithTimeout)
 			if e != nil {
+				promTriggerJobDuration.WithLabelValues("error").Observe(time.Since(start).Seconds())
 				logger.Errorf("job run trigger error making request: %v", e.Error())
 				return e
 			}
 			defer logger.ErrorIfCalling(r.Body.Close)
 			statusCode = r.StatusCode
 			elapsed := time.Since(start)
+			promTriggerJobDuration.WithLabelValues(strconv.Itoa(statusCode)).Observe(elapsed.Seconds())
 			logger.Debugw(fmt.Sprintf("job run trigger got %v in %s", statusCode, elapsed), "statusCode", statusCode, "timeElapsedSeconds", elapsed)
 
 			bz, e := ioutil.ReadAll(r.Body)
 			if e != nil {
-				logger.Errorf("job run trigger error reading body: %v", err.Error())
+				logger.Errorf("job run trigger error reading body: %v", e)
 				return e
 			}
 			elapsed = time.Since(start)
@@ -125,6 +250,7 @@ func (config RetryConfig) withRetry(client *http.Client, request *http.Request)
 		retry.Delay(config.Delay),
 		retry.Attempts(config.Attempts),
 		retry.OnRetry(func(n uint, err error) {
+			promTriggerJobRetries.Inc()
 			logger.Debugw("job run trigger error, will retry", "error", err.Error(), "attempt", n, "timeout", config.Timeout)
 		}),
 	)
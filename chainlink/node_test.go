@@ -11,6 +11,10 @@ import (
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -185,9 +189,131 @@ func TestNode_TriggerJob(t *testing.T) {
 					Delay:    100 * time.Millisecond,
 				},
 			}
-			if err := cl.TriggerJob(tt.args.jobId, tt.args.payload); (err != nil) != tt.wantErr {
+			if _, err := cl.TriggerJob(tt.args.jobId, "", "", tt.args.payload); (err != nil) != tt.wantErr {
 				t.Errorf("TriggerJob() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestNode_TriggerJob_JobEndpoints(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	cl := NewClient(accessKey, accessSecret, *u, WebhookJobEndpoint, StaticAuth, APIToken{}, nil, RetryConfig{Timeout: time.Second, Attempts: 1})
+
+	for _, jobEndpoint := range []JobEndpoint{"", LegacyJobEndpoint, WebhookJobEndpoint} {
+		_, err = cl.TriggerJob(jobId, jobEndpoint, "", testPayload)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"/v2/jobs/123/runs", "/v2/specs/123/runs", "/v2/jobs/123/runs"}, paths)
+}
+
+func TestParseJobEndpoint(t *testing.T) {
+	endpoint, err := ParseJobEndpoint("webhook")
+	require.NoError(t, err)
+	assert.Equal(t, WebhookJobEndpoint, endpoint)
+	endpoint, err = ParseJobEndpoint("legacy")
+	require.NoError(t, err)
+	assert.Equal(t, LegacyJobEndpoint, endpoint)
+	_, err = ParseJobEndpoint("")
+	assert.Error(t, err)
+}
+
+func TestNode_TriggerJob_CountsRetries(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	cl := client{
+		endpoint: *u,
+		retry: RetryConfig{
+			Timeout:  time.Second,
+			Attempts: 3,
+			Delay:    10 * time.Millisecond,
+		},
+	}
+
+	retriesBefore := testutil.ToFloat64(promTriggerJobRetries)
+	_, err = cl.TriggerJob(jobId, "", "", testPayload)
+	require.Error(t, err)
+	assert.Equal(t, float64(3), testutil.ToFloat64(promTriggerJobRetries)-retriesBefore)
+}
+
+func TestNode_TriggerJob_JobNotFound(t *testing.T) {
+	u, err := url.Parse(clMockUrl)
+	require.NoError(t, err)
+
+	cl := client{
+		accessKey:    accessKey,
+		accessSecret: accessSecret,
+		endpoint:     *u,
+		retry: RetryConfig{
+			Timeout:  time.Second,
+			Attempts: 1,
+		},
+	}
+
+	_, err = cl.TriggerJob("unknown", "", "", testPayload)
+	require.Error(t, err)
+	assert.True(t, IsJobNotFound(err))
+
+	_, err = cl.TriggerJob(jobIdWPayload, "", "", []byte(`weird payload`))
+	require.Error(t, err)
+	assert.False(t, IsJobNotFound(err))
+}
+
+func TestNode_TriggerJob_SendsIdempotencyKey(t *testing.T) {
+	var keys []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(idempotencyKeyHeader))
+		if len(keys) < 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	cl := NewClient(accessKey, accessSecret, *u, LegacyJobEndpoint, StaticAuth, APIToken{}, nil, RetryConfig{Timeout: time.Second, Attempts: 3, Delay: 10 * time.Millisecond})
+
+	_, err = cl.TriggerJob(jobId, "", "key-1", testPayload)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"key-1", "key-1"}, keys)
+
+	_, err = cl.TriggerJob(jobId, "", "", testPayload)
+	require.NoError(t, err)
+	assert.Equal(t, "", keys[2])
+}
+
+func TestNode_TriggerJob_TLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	retry := RetryConfig{Timeout: time.Second, Attempts: 1}
+
+	cl := NewClient(accessKey, accessSecret, *u, LegacyJobEndpoint, StaticAuth, APIToken{}, nil, retry)
+	_, err = cl.TriggerJob(jobId, "", "", testPayload)
+	assert.Error(t, err, "the test server certificate isn't trusted by default")
+
+	tlsConfig := ts.Client().Transport.(*http.Transport).TLSClientConfig
+	cl = NewClient(accessKey, accessSecret, *u, LegacyJobEndpoint, StaticAuth, APIToken{}, tlsConfig, retry)
+	_, err = cl.TriggerJob(jobId, "", "", testPayload)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,192 @@
+package chainlink
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+
+	"github.com/smartcontractkit/chainlink/core/logger"
+)
+
+// APIToken is the API token of a user of the Chainlink node. The node only accepts the
+// credentials of the external initiator to trigger runs, reading runs requires a token.
+type APIToken struct {
+	Key    string
+	Secret string
+}
+
+// ErrNoAPIToken is returned when reading a run without an API token for the node
+var ErrNoAPIToken = errors.New("no API token configured for the Chainlink node")
+
+// RunStatus is the state of a job run on the Chainlink node
+type RunStatus string
+
+const (
+	RunStatusPending   RunStatus = "pending"
+	RunStatusCompleted RunStatus = "completed"
+	RunStatusErrored   RunStatus = "errored"
+)
+
+// Terminal returns whether the run won't change state anymore
+func (s RunStatus) Terminal() bool {
+	return s == RunStatusCompleted || s == RunStatusErrored
+}
+
+// Run is the outcome of a job run, the TxHash is the hash of the
+// transaction sent by the run's ethtx task if it could be found
+type Run struct {
+	ID     string
+	Status RunStatus
+	TxHash string
+	Error  string
+}
+
+var txHashRegexp = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+
+// runsResponse is the JSON:API document returned for job runs, attributes
+// differ between legacy spec runs and webhook job runs
+type runsResponse struct {
+	Data struct {
+		ID         string          `json:"id"`
+		Attributes json.RawMessage `json:"attributes"`
+	} `json:"data"`
+}
+
+// legacyRunAttributes are the attributes of a legacy spec run
+type legacyRunAttributes struct {
+	Status string `json:"status"`
+	Result struct {
+		Data  map[string]interface{} `json:"data"`
+		Error *string                `json:"error"`
+	} `json:"result"`
+}
+
+// pipelineRunAttributes are the attributes of a webhook job's pipeline run
+type pipelineRunAttributes struct {
+	Outputs    []interface{} `json:"outputs"`
+	Errors     []*string     `json:"errors"`
+	FinishedAt *string       `json:"finishedAt"`
+}
+
+// HasAPIToken returns whether the client has an API token to read the status of runs
+func (cl client) HasAPIToken(string) bool {
+	return cl.apiToken.Key != "" && cl.apiToken.Secret != ""
+}
+
+// RunStatus fetches the status of a job run triggered through the jobEndpoint,
+// or the client's default endpoint if it is empty. It returns ErrNoAPIToken
+// without a request if the client has no API token.
+func (cl client) RunStatus(jobId string, jobEndpoint JobEndpoint, runID string) (Run, error) {
+	if !cl.HasAPIToken(jobId) {
+		return Run{}, ErrNoAPIToken
+	}
+	if jobEndpoint == "" {
+		jobEndpoint = cl.defaultJobEndpoint
+	}
+	u := cl.endpoint
+	if jobEndpoint == WebhookJobEndpoint {
+		u.Path = fmt.Sprintf("/v2/jobs/%s/runs/%s", jobId, runID)
+	} else {
+		u.Path = fmt.Sprintf("/v2/runs/%s", runID)
+	}
+
+	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return Run{}, err
+	}
+	request.Header.Set(apiKeyHeader, cl.apiToken.Key)
+	request.Header.Set(apiSecretHeader, cl.apiToken.Secret)
+
+	httpClient := &http.Client{Transport: cl.transport, Timeout: cl.retry.Timeout}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return Run{}, err
+	}
+	defer logger.ErrorIfCalling(response.Body.Close)
+	if response.StatusCode >= 400 {
+		return Run{}, StatusCodeError{StatusCode: response.StatusCode}
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return Run{}, err
+	}
+
+	if jobEndpoint == WebhookJobEndpoint {
+		return parsePipelineRun(body)
+	}
+	return parseLegacyRun(body)
+}
+
+func parseRunID(body []byte) (string, error) {
+	var response runsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", err
+	}
+	if response.Data.ID == "" {
+		return "", errors.New("missing data.id")
+	}
+	return response.Data.ID, nil
+}
+
+func parseLegacyRun(body []byte) (Run, error) {
+	var response runsResponse
+	var attributes legacyRunAttributes
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Run{}, err
+	}
+	if err := json.Unmarshal(response.Data.Attributes, &attributes); err != nil {
+		return Run{}, err
+	}
+
+	run := Run{ID: response.Data.ID, Status: RunStatusPending}
+	switch attributes.Status {
+	case "completed":
+		run.Status = RunStatusCompleted
+	case "errored", "cancelled":
+		run.Status = RunStatusErrored
+	}
+	if attributes.Result.Error != nil {
+		run.Error = *attributes.Result.Error
+	}
+	for _, key := range []string{"latestOutgoingTxHash", "result"} {
+		if hash, ok := attributes.Result.Data[key].(string); ok && txHashRegexp.MatchString(hash) {
+			run.TxHash = hash
+			break
+		}
+	}
+	return run, nil
+}
+
+func parsePipelineRun(body []byte) (Run, error) {
+	var response runsResponse
+	var attributes pipelineRunAttributes
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Run{}, err
+	}
+	if err := json.Unmarshal(response.Data.Attributes, &attributes); err != nil {
+		return Run{}, err
+	}
+
+	run := Run{ID: response.Data.ID, Status: RunStatusPending}
+	if attributes.FinishedAt == nil {
+		return run, nil
+	}
+	run.Status = RunStatusCompleted
+	for _, runErr := range attributes.Errors {
+		if runErr != nil && *runErr != "" {
+			run.Status = RunStatusErrored
+			run.Error = *runErr
+			break
+		}
+	}
+	for _, output := range attributes.Outputs {
+		if hash, ok := output.(string); ok && txHashRegexp.MatchString(hash) {
+			run.TxHash = hash
+			break
+		}
+	}
+	return run, nil
+}
@@ -0,0 +1,92 @@
+package chainlink
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const txHash = "0x1aa2a4c3a0d2b1c8dfc5dd6d7c3cf3a0a2b8a4b5c3c7e9d1f1e2d3c4b5a69788"
+
+func TestNode_TriggerJob_ReturnsRunID(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"data": {"type": "runs", "id": "run-1", "attributes": {}}}`))
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	cl := NewClient(accessKey, accessSecret, *u, LegacyJobEndpoint, StaticAuth, APIToken{}, nil, RetryConfig{Timeout: time.Second, Attempts: 1})
+
+	runID, err := cl.TriggerJob(jobId, "", "", testPayload)
+	require.NoError(t, err)
+	assert.Equal(t, "run-1", runID)
+}
+
+func TestNode_RunStatus(t *testing.T) {
+	responses := map[string]string{
+		"/v2/runs/legacy-pending":   `{"data": {"id": "legacy-pending", "attributes": {"status": "pending_outgoing_confirmations", "result": {"data": {}}}}}`,
+		"/v2/runs/legacy-completed": `{"data": {"id": "legacy-completed", "attributes": {"status": "completed", "result": {"data": {"result": "` + txHash + `"}, "error": null}}}}`,
+		"/v2/runs/legacy-errored":   `{"data": {"id": "legacy-errored", "attributes": {"status": "errored", "result": {"data": {}, "error": "out of gas"}}}}`,
+		"/v2/jobs/123/runs/1":       `{"data": {"id": "1", "attributes": {"outputs": [null], "errors": [null], "finishedAt": null}}}`,
+		"/v2/jobs/123/runs/2":       `{"data": {"id": "2", "attributes": {"outputs": ["` + txHash + `"], "errors": [null], "finishedAt": "2021-02-11T10:00:00Z"}}}`,
+		"/v2/jobs/123/runs/3":       `{"data": {"id": "3", "attributes": {"outputs": [null], "errors": ["reverted"], "finishedAt": "2021-02-11T10:00:00Z"}}}`,
+	}
+	apiToken := APIToken{Key: "api-key", Secret: "api-secret"}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// like the Chainlink node, runs are only shown to users
+		if r.Header.Get(apiKeyHeader) != apiToken.Key || r.Header.Get(apiSecretHeader) != apiToken.Secret {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(response))
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	cl := NewClient(accessKey, accessSecret, *u, LegacyJobEndpoint, StaticAuth, apiToken, nil, RetryConfig{Timeout: time.Second, Attempts: 1})
+
+	for _, test := range []struct {
+		jobEndpoint JobEndpoint
+		runID       string
+		want        Run
+	}{
+		{"", "legacy-pending", Run{ID: "legacy-pending", Status: RunStatusPending}},
+		{LegacyJobEndpoint, "legacy-completed", Run{ID: "legacy-completed", Status: RunStatusCompleted, TxHash: txHash}},
+		{LegacyJobEndpoint, "legacy-errored", Run{ID: "legacy-errored", Status: RunStatusErrored, Error: "out of gas"}},
+		{WebhookJobEndpoint, "1", Run{ID: "1", Status: RunStatusPending}},
+		{WebhookJobEndpoint, "2", Run{ID: "2", Status: RunStatusCompleted, TxHash: txHash}},
+		{WebhookJobEndpoint, "3", Run{ID: "3", Status: RunStatusErrored, Error: "reverted"}},
+	} {
+		run, err := cl.RunStatus(jobId, test.jobEndpoint, test.runID)
+		require.NoError(t, err)
+		assert.Equal(t, test.want, run)
+	}
+
+	_, err = cl.RunStatus(jobId, LegacyJobEndpoint, "unknown")
+	assert.True(t, IsJobNotFound(err))
+
+	cl = NewClient(accessKey, accessSecret, *u, LegacyJobEndpoint, StaticAuth, APIToken{Key: "api-key", Secret: "wrong"}, nil, RetryConfig{Timeout: time.Second, Attempts: 1})
+	_, err = cl.RunStatus(jobId, LegacyJobEndpoint, "legacy-pending")
+	assert.Equal(t, StatusCodeError{StatusCode: http.StatusUnauthorized}, err)
+
+	cl = NewClient(accessKey, accessSecret, *u, LegacyJobEndpoint, StaticAuth, APIToken{}, nil, RetryConfig{Timeout: time.Second, Attempts: 1})
+	_, err = cl.RunStatus(jobId, LegacyJobEndpoint, "legacy-pending")
+	assert.Equal(t, ErrNoAPIToken, err)
+}
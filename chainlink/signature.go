@@ -0,0 +1,122 @@
+package chainlink
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	TimestampHeader = "X-Chainlink-EA-Timestamp"
+	NonceHeader     = "X-Chainlink-EA-Nonce"
+	SignatureHeader = "X-Chainlink-EA-Signature"
+)
+
+// AuthMode selects how requests between the EI and a Chainlink node are authenticated
+type AuthMode string
+
+const (
+	// StaticAuth sends the access key and secret in the request headers
+	StaticAuth AuthMode = "static"
+	// HMACAuth sends the access key along with a timestamp, a nonce and the
+	// HMAC-SHA256 signature of the request, keyed with the secret
+	HMACAuth AuthMode = "hmac"
+)
+
+// ParseAuthMode returns the AuthMode with the given name
+func ParseAuthMode(name string) (AuthMode, error) {
+	switch mode := AuthMode(name); mode {
+	case StaticAuth, HMACAuth:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown auth mode %q, must be %s or %s", name, StaticAuth, HMACAuth)
+	}
+}
+
+// Signature returns the hex encoded HMAC-SHA256 of the request method, its path
+// including the query, the timestamp, the nonce and the body, keyed with the secret
+func Signature(secret, method, path, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n", method, path, timestamp, nonce)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest sets the timestamp, a random nonce and the signature of the request,
+// every attempt to send a request has to be signed again
+func SignRequest(request *http.Request, secret string, body []byte, now time.Time) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	request.Header.Set(TimestampHeader, timestamp)
+	request.Header.Set(NonceHeader, hex.EncodeToString(nonce))
+	request.Header.Set(SignatureHeader, Signature(secret, request.Method, request.URL.RequestURI(), timestamp, hex.EncodeToString(nonce), body))
+	return nil
+}
+
+// SignatureVerifier verifies signed requests, rejecting the ones with a timestamp
+// further than maxSkew from now and the ones replaying a nonce already seen
+type SignatureVerifier struct {
+	maxSkew time.Duration
+
+	mu sync.Mutex
+	// nonces are kept until their timestamp is outside of the skew window,
+	// after which requests replaying them are rejected for their timestamp
+	nonces map[string]time.Time
+}
+
+// NewSignatureVerifier returns a SignatureVerifier accepting timestamps within maxSkew of now
+func NewSignatureVerifier(maxSkew time.Duration) *SignatureVerifier {
+	return &SignatureVerifier{
+		maxSkew: maxSkew,
+		nonces:  make(map[string]time.Time),
+	}
+}
+
+// Verify returns an error unless the request is signed with the secret of the access key,
+// the nonce is only recorded once the signature is valid
+func (v *SignatureVerifier) Verify(accessKey, secret string, request *http.Request, body []byte, now time.Time) error {
+	timestamp := request.Header.Get(TimestampHeader)
+	nonce := request.Header.Get(NonceHeader)
+	signature := request.Header.Get(SignatureHeader)
+	if timestamp == "" || nonce == "" || signature == "" {
+		return errors.New("missing signature headers")
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q", timestamp)
+	}
+	signedAt := time.Unix(unix, 0)
+	if skew := now.Sub(signedAt); skew > v.maxSkew || skew < -v.maxSkew {
+		return fmt.Errorf("timestamp %s is outside of the %s skew window", signedAt.Format(time.RFC3339), v.maxSkew)
+	}
+
+	expected := Signature(secret, request.Method, request.URL.RequestURI(), timestamp, nonce, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("invalid signature")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for key, expiresAt := range v.nonces {
+		if now.After(expiresAt) {
+			delete(v.nonces, key)
+		}
+	}
+	key := accessKey + ":" + nonce
+	if _, seen := v.nonces[key]; seen {
+		return errors.New("replayed nonce")
+	}
+	v.nonces[key] = signedAt.Add(v.maxSkew)
+	return nil
+}
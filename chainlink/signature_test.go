@@ -0,0 +1,110 @@
+package chainlink
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedRequest(t *testing.T, secret string, body []byte, now time.Time) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "/jobs?page=1", bytes.NewReader(body))
+	require.NoError(t, SignRequest(request, secret, body, now))
+	return request
+}
+
+func TestSignatureVerifier(t *testing.T) {
+	now := time.Now()
+	verifier := NewSignatureVerifier(time.Minute)
+
+	t.Run("accepts a signed request once", func(t *testing.T) {
+		request := signedRequest(t, accessSecret, testPayload, now)
+		require.NoError(t, verifier.Verify(accessKey, accessSecret, request, testPayload, now))
+		assert.EqualError(t, verifier.Verify(accessKey, accessSecret, request, testPayload, now), "replayed nonce")
+	})
+
+	t.Run("scopes nonces to the access key", func(t *testing.T) {
+		request := signedRequest(t, accessSecret, testPayload, now)
+		require.NoError(t, verifier.Verify(accessKey, accessSecret, request, testPayload, now))
+		assert.NoError(t, verifier.Verify("other", accessSecret, request, testPayload, now))
+	})
+
+	t.Run("rejects tampered requests", func(t *testing.T) {
+		request := signedRequest(t, accessSecret, testPayload, now)
+		assert.EqualError(t, verifier.Verify(accessKey, "wrong", request, testPayload, now), "invalid signature")
+		assert.EqualError(t, verifier.Verify(accessKey, accessSecret, request, []byte(`{}`), now), "invalid signature")
+
+		request.URL.RawQuery = "page=2"
+		assert.EqualError(t, verifier.Verify(accessKey, accessSecret, request, testPayload, now), "invalid signature")
+
+		request = signedRequest(t, accessSecret, testPayload, now)
+		request.Method = http.MethodDelete
+		assert.EqualError(t, verifier.Verify(accessKey, accessSecret, request, testPayload, now), "invalid signature")
+	})
+
+	t.Run("rejects timestamps outside of the skew window", func(t *testing.T) {
+		stale := signedRequest(t, accessSecret, testPayload, now.Add(-2*time.Minute))
+		assert.Error(t, verifier.Verify(accessKey, accessSecret, stale, testPayload, now))
+		future := signedRequest(t, accessSecret, testPayload, now.Add(2*time.Minute))
+		assert.Error(t, verifier.Verify(accessKey, accessSecret, future, testPayload, now))
+	})
+
+	t.Run("rejects unsigned requests", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/jobs", nil)
+		assert.EqualError(t, verifier.Verify(accessKey, accessSecret, request, nil, now), "missing signature headers")
+	})
+
+	t.Run("forgets nonces once their timestamp expired", func(t *testing.T) {
+		request := signedRequest(t, accessSecret, testPayload, now)
+		require.NoError(t, verifier.Verify(accessKey, accessSecret, request, testPayload, now))
+		later := now.Add(2 * time.Minute)
+		require.NoError(t, verifier.Verify(accessKey, accessSecret, signedRequest(t, accessSecret, nil, later), nil, later))
+		assert.Len(t, verifier.nonces, 1)
+	})
+}
+
+func TestParseAuthMode(t *testing.T) {
+	mode, err := ParseAuthMode("hmac")
+	require.NoError(t, err)
+	assert.Equal(t, HMACAuth, mode)
+	mode, err = ParseAuthMode("static")
+	require.NoError(t, err)
+	assert.Equal(t, StaticAuth, mode)
+	_, err = ParseAuthMode("")
+	assert.Error(t, err)
+}
+
+func TestNode_TriggerJob_SignsEveryAttempt(t *testing.T) {
+	verifier := NewSignatureVerifier(time.Minute)
+	var nonces []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, testPayload, body)
+		assert.Empty(t, r.Header.Get(externalInitiatorSecretHeader))
+		assert.NoError(t, verifier.Verify(r.Header.Get(externalInitiatorAccessKeyHeader), accessSecret, r, body, time.Now()))
+
+		nonces = append(nonces, r.Header.Get(NonceHeader))
+		if len(nonces) < 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	cl := NewClient(accessKey, accessSecret, *u, LegacyJobEndpoint, HMACAuth, APIToken{}, nil, RetryConfig{Timeout: time.Second, Attempts: 3, Delay: 10 * time.Millisecond})
+
+	_, err = cl.TriggerJob(jobId, "", "", testPayload)
+	require.NoError(t, err)
+	require.Len(t, nonces, 2)
+	assert.NotEqual(t, nonces[0], nonces[1])
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"testing"
 
+	"github.com/smartcontractkit/external-initiator/store"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
@@ -23,3 +24,26 @@ func Test_validateParams(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func Test_validatePairedParams(t *testing.T) {
+	pairs := [][2]string{{"key", "secret"}}
+
+	v := viper.New()
+	assert.NoError(t, validatePairedParams(v, pairs))
+	v.Set("key", "value")
+	assert.Error(t, validatePairedParams(v, pairs))
+	v.Set("secret", "value")
+	assert.NoError(t, validatePairedParams(v, pairs))
+	v.Set("key", "")
+	assert.Error(t, validatePairedParams(v, pairs))
+}
+
+func Test_runCallback_ReturnsValidationErrors(t *testing.T) {
+	v := viper.New()
+	v.Set("ci_accesskey", "key")
+	err := runCallback(v, func(Config, *store.Client) error {
+		t.Fatal("the runner must not run with an invalid config")
+		return nil
+	})
+	assert.Error(t, err)
+}
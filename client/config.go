@@ -10,12 +10,22 @@ import (
 type Config struct {
 	// The port for the EI API to listen on
 	Port int
+	// TLSCertFile and TLSKeyFile are the certificate and key the EI API is served with over HTTPS,
+	// plain HTTP is served without them
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile is the CA the client certificates required by the EI API have to be signed by
+	TLSClientCAFile string
 	// The URL of the ChainlinkURL Core Service
 	ChainlinkURL string
 	// InitiatorToChainlinkAccessKey is the access key to identity the node to ChainlinkURL
 	InitiatorToChainlinkAccessKey string
 	// InitiatorToChainlinkSecret is the secret to authenticate the node to ChainlinkURL
 	InitiatorToChainlinkSecret string
+	// ChainlinkAPIKey and ChainlinkAPISecret are the API token the status of job runs is read
+	// from ChainlinkURL with, the node doesn't accept the InitiatorToChainlink credentials for it
+	ChainlinkAPIKey    string
+	ChainlinkAPISecret string
 	// DatabaseURL Configures the URL for chainlink to connect to. This must be
 	// a properly formatted URL, with a valid scheme (postgres://).
 	DatabaseURL string
@@ -23,32 +33,103 @@ type Config struct {
 	ChainlinkToInitiatorAccessKey string
 	// The External Initiator secret, used for traffic flowing from Chainlink to this Service
 	ChainlinkToInitiatorSecret string
+	// InitiatorToChainlinkAuthMode is how requests to the Chainlink nodes are authenticated, static or hmac
+	InitiatorToChainlinkAuthMode string
+	// ChainlinkToInitiatorAuthMode is how requests from the Chainlink nodes are authenticated, static or hmac
+	ChainlinkToInitiatorAuthMode string
+	// SecretsKey is the hex encoded key the signing secrets of the credentials are
+	// encrypted with in the database, it is only read from the EI_SECRETS_KEY env
+	SecretsKey string
+	// AuthMaxSkew is the maximum difference between the timestamp of a signed request and the time it is received
+	AuthMaxSkew time.Duration
+	// ChainlinkTLSCAFile is the CA the certificates of the Chainlink nodes are verified with, instead of the system roots
+	ChainlinkTLSCAFile string
+	// ChainlinkTLSCertFile and ChainlinkTLSKeyFile are the client certificate and key presented to the Chainlink nodes
+	ChainlinkTLSCertFile string
+	ChainlinkTLSKeyFile  string
+	// ShutdownTimeout is the time the upkeeps being executed, and then the API requests being served, are waited for when shutting down
+	ShutdownTimeout time.Duration
 	// ChainlinkTimeout sets the timeout for job run triggers to the Chainlink node
 	ChainlinkTimeout time.Duration
-	// ChainlinkRetryAttempts sets the maximum number of attempts that will be made for job run triggers
-	ChainlinkRetryAttempts uint
-	// ChainlinkRetryDelay sets the delay between attempts for job run triggers
-	ChainlinkRetryDelay time.Duration
+	// ChainlinkJobEndpoint is the endpoint used to trigger job runs, unless set per job
+	ChainlinkJobEndpoint string
+	// ChainlinkCircuitThreshold is the number of consecutive failed requests after which the circuit breaker opens
+	ChainlinkCircuitThreshold uint
+	// ChainlinkCircuitOpenTimeout is the time the circuit breaker stays open before letting a probe through
+	ChainlinkCircuitOpenTimeout time.Duration
 	// The ethereum endpoint to use for keeper jobs
 	KeeperEthEndpoint string
 	// The interval at which to sync keeper registries
 	KeeperRegistrySyncInterval time.Duration
+	// ReadyMaxHeadAge is the maximum time since the last head before the service is reported as not ready
+	ReadyMaxHeadAge time.Duration
+	// ReadyMaxSyncAge is the maximum time since the last successful registry sync before the service is reported as not ready
+	ReadyMaxSyncAge time.Duration
+	// AllowManualPerform enables the API endpoint which performs upkeeps outside of the turn taking
+	AllowManualPerform bool
+	// OrphanedJobThreshold is the number of consecutive job run triggers answered with a 404 or 410 after which a job is orphaned
+	OrphanedJobThreshold uint
+	// OrphanedJobReconcileInterval is the interval at which orphaned jobs are marked or deleted
+	OrphanedJobReconcileInterval time.Duration
+	// DeleteOrphanedJobs deletes orphaned jobs instead of marking them
+	DeleteOrphanedJobs bool
+	// RunStatusPollInterval is the interval at which the status of pending job runs is polled
+	RunStatusPollInterval time.Duration
+	// RunStatusTimeout is the time after which job runs still pending are marked as timed out
+	RunStatusTimeout time.Duration
+	// TriggerDispatchInterval is the interval at which queued job run triggers are sent
+	TriggerDispatchInterval time.Duration
+	// TriggerBackoffMin is the delay before the first retry of a failed job run trigger
+	TriggerBackoffMin time.Duration
+	// TriggerBackoffMax caps the delay between retries of a failed job run trigger
+	TriggerBackoffMax time.Duration
+	// RestartBackoffMin is the delay before the first restart of a failed component
+	RestartBackoffMin time.Duration
+	// RestartBackoffMax caps the delay between restarts of a failed component
+	RestartBackoffMax time.Duration
 }
 
 // newConfigFromViper returns a Config based on the values supplied by viper.
 func newConfigFromViper(v *viper.Viper) Config {
 	return Config{
 		Port:                          v.GetInt("port"),
+		TLSCertFile:                   v.GetString("tls_cert"),
+		TLSKeyFile:                    v.GetString("tls_key"),
+		TLSClientCAFile:               v.GetString("tls_client_ca"),
 		ChainlinkURL:                  v.GetString("chainlinkurl"),
 		InitiatorToChainlinkAccessKey: v.GetString("ic_accesskey"),
 		InitiatorToChainlinkSecret:    v.GetString("ic_secret"),
+		ChainlinkAPIKey:               v.GetString("cl_api_key"),
+		ChainlinkAPISecret:            v.GetString("cl_api_secret"),
 		DatabaseURL:                   v.GetString("databaseurl"),
 		ChainlinkToInitiatorAccessKey: v.GetString("ci_accesskey"),
 		ChainlinkToInitiatorSecret:    v.GetString("ci_secret"),
+		InitiatorToChainlinkAuthMode:  v.GetString("ic_auth_mode"),
+		ChainlinkToInitiatorAuthMode:  v.GetString("ci_auth_mode"),
+		SecretsKey:                    v.GetString("secrets_key"),
+		AuthMaxSkew:                   v.GetDuration("auth_max_skew"),
+		ChainlinkTLSCAFile:            v.GetString("cl_tls_ca"),
+		ChainlinkTLSCertFile:          v.GetString("cl_tls_cert"),
+		ChainlinkTLSKeyFile:           v.GetString("cl_tls_key"),
+		ShutdownTimeout:               v.GetDuration("shutdown_timeout"),
 		ChainlinkTimeout:              v.GetDuration("cl_timeout"),
-		ChainlinkRetryAttempts:        v.GetUint("cl_retry_attempts"),
-		ChainlinkRetryDelay:           v.GetDuration("cl_retry_delay"),
+		ChainlinkJobEndpoint:          v.GetString("cl_job_endpoint"),
+		ChainlinkCircuitThreshold:     v.GetUint("cl_circuit_threshold"),
+		ChainlinkCircuitOpenTimeout:   v.GetDuration("cl_circuit_open_timeout"),
 		KeeperEthEndpoint:             v.GetString("keeper_eth_endpoint"),
 		KeeperRegistrySyncInterval:    v.GetDuration("keeper_registry_sync_interval"),
+		ReadyMaxHeadAge:               v.GetDuration("ready_max_head_age"),
+		ReadyMaxSyncAge:               v.GetDuration("ready_max_sync_age"),
+		AllowManualPerform:            v.GetBool("allow_manual_perform"),
+		OrphanedJobThreshold:          v.GetUint("orphaned_job_threshold"),
+		OrphanedJobReconcileInterval:  v.GetDuration("orphaned_job_reconcile_interval"),
+		DeleteOrphanedJobs:            v.GetBool("delete_orphaned_jobs"),
+		RunStatusPollInterval:         v.GetDuration("run_status_poll_interval"),
+		RunStatusTimeout:              v.GetDuration("run_status_timeout"),
+		TriggerDispatchInterval:       v.GetDuration("trigger_dispatch_interval"),
+		TriggerBackoffMin:             v.GetDuration("trigger_backoff_min"),
+		TriggerBackoffMax:             v.GetDuration("trigger_backoff_max"),
+		RestartBackoffMin:             v.GetDuration("restart_backoff_min"),
+		RestartBackoffMax:             v.GetDuration("restart_backoff_max"),
 	}
 }
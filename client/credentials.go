@@ -0,0 +1,172 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/smartcontractkit/external-initiator/keeper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// generateCredentialsCmd returns the command managing the credentials the
+// Chainlink nodes authenticate with. Credentials are rotated without downtime
+// by creating the new one, updating the node and revoking the old one.
+func generateCredentialsCmd() *cobra.Command {
+	credentialsCmd, v := newDatabaseCmd("credentials", "Manage the credentials the Chainlink nodes authenticate with")
+
+	createCmd := &cobra.Command{
+		Use:          "create",
+		Short:        "Add a credential to a Chainlink node, next to its active ones, reading its secret from stdin",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			nodeName, _ := cmd.Flags().GetString("node")
+			accessKey, _ := cmd.Flags().GetString("ci_accesskey")
+			expiresIn, _ := cmd.Flags().GetDuration("expires_in")
+			signing, _ := cmd.Flags().GetBool("hmac")
+			signingKey, err := signingSecretKey(v, signing)
+			if err != nil {
+				return err
+			}
+			secrets, err := readSecrets(cmd.InOrStdin(), cmd.ErrOrStderr(), "ci_secret")
+			if err != nil {
+				return err
+			}
+			secret := secrets[0]
+			return withKeeperStore(v, func(keeperStore keeper.Store) error {
+				return createCredential(keeperStore, nodeName, accessKey, secret, expiresIn, signingKey, cmd.OutOrStdout())
+			})
+		},
+	}
+	createCmd.Flags().String("node", keeper.DefaultNodeName, "The name of the Chainlink node")
+	createCmd.Flags().String("ci_accesskey", "", "The External Initiator access key, used for traffic flowing from the node to this Service")
+	createCmd.Flags().Duration("expires_in", 0, "The time after which the credential expires, 0 to never expire")
+	createCmd.Flags().Bool("hmac", false, hmacFlagUsage)
+
+	listCmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List the credentials with their status, without the secrets",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return withKeeperStore(v, func(keeperStore keeper.Store) error {
+				return listCredentials(keeperStore, cmd.OutOrStdout())
+			})
+		},
+	}
+
+	revokeCmd := &cobra.Command{
+		Use:          "revoke ACCESS_KEY",
+		Short:        "Revoke a credential, the node can no longer authenticate with it",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withKeeperStore(v, func(keeperStore keeper.Store) error {
+				return revokeCredential(keeperStore, args[0], cmd.OutOrStdout())
+			})
+		},
+	}
+
+	credentialsCmd.AddCommand(createCmd, listCmd, revokeCmd)
+	return credentialsCmd
+}
+
+// hmacFlagUsage describes the flag keeping the secret of a credential, which the hmac ci auth mode requires
+const hmacFlagUsage = "Keep the secret encrypted with the EI_SECRETS_KEY env to verify the signed requests of the node, required by the hmac ci_auth_mode"
+
+// signingSecretKey returns the key the secret of a credential created with the
+// hmac flag is encrypted with, nil if it isn't set
+func signingSecretKey(v *viper.Viper, signing bool) (*keeper.SecretKey, error) {
+	if !signing {
+		return nil, nil
+	}
+	key, err := keeper.ParseSecretKey(v.GetString("secrets_key"))
+	if err != nil {
+		return nil, err
+	} else if key == nil {
+		return nil, errors.New("EI_SECRETS_KEY must be set to keep the secret with --hmac")
+	}
+	return key, nil
+}
+
+// createCredential saves the credential, which keeps its secret encrypted
+// with the signingKey to verify signed requests unless it is nil
+func createCredential(keeperStore keeper.Store, nodeName, accessKey, secret string, expiresIn time.Duration, signingKey *keeper.SecretKey, out io.Writer) error {
+	var nodeID *uint32
+	if nodeName != keeper.DefaultNodeName {
+		node, err := keeperStore.NodeByName(nodeName)
+		if gorm.IsRecordNotFoundError(err) {
+			return fmt.Errorf("node %s not found", nodeName)
+		} else if err != nil {
+			return err
+		}
+		nodeID = &node.ID
+	}
+	var expiresAt *time.Time
+	if expiresIn > 0 {
+		at := time.Now().Add(expiresIn)
+		expiresAt = &at
+	}
+
+	credential, err := keeper.NewCredential(nodeID, accessKey, secret, expiresAt)
+	if err != nil {
+		return errors.Wrap(err, "invalid credential")
+	}
+	if signingKey != nil {
+		if err = credential.SetSigningSecret(signingKey, secret); err != nil {
+			return err
+		}
+	}
+	if _, err = keeperStore.CreateCredential(credential); err != nil {
+		return errors.Wrapf(err, "unable to create credential %s", accessKey)
+	}
+	_, err = fmt.Fprintf(out, "Created credential %s for node %s\n", accessKey, nodeName)
+	return err
+}
+
+func listCredentials(keeperStore keeper.Store, out io.Writer) error {
+	credentials, err := keeperStore.Credentials()
+	if err != nil {
+		return err
+	}
+	nodes, err := keeperStore.Nodes()
+	if err != nil {
+		return err
+	}
+	nodeNames := make(map[uint32]string, len(nodes))
+	for _, node := range nodes {
+		nodeNames[node.ID] = node.Name
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ACCESS KEY\tNODE\tSTATUS\tCREATED\tEXPIRES")
+	for _, credential := range credentials {
+		nodeName := keeper.DefaultNodeName
+		if credential.NodeID != nil {
+			nodeName = nodeNames[*credential.NodeID]
+		}
+		expires := "never"
+		if credential.ExpiresAt != nil {
+			expires = credential.ExpiresAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", credential.AccessKey, nodeName, credential.Status(now), credential.CreatedAt.Format(time.RFC3339), expires)
+	}
+	return w.Flush()
+}
+
+func revokeCredential(keeperStore keeper.Store, accessKey string, out io.Writer) error {
+	err := keeperStore.RevokeCredential(accessKey)
+	if gorm.IsRecordNotFoundError(err) {
+		return fmt.Errorf("credential %s not found or already revoked", accessKey)
+	} else if err != nil {
+		return errors.Wrapf(err, "unable to revoke credential %s", accessKey)
+	}
+	_, err = fmt.Fprintf(out, "Revoked credential %s\n", accessKey)
+	return err
+}
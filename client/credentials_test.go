@@ -0,0 +1,70 @@
+package client
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/external-initiator/keeper"
+	"github.com/smartcontractkit/external-initiator/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialsCommands(t *testing.T) {
+	dbClient, cleanup := store.SetupTestDB(t)
+	regStore := keeper.NewStore(dbClient.DB())
+	defer cleanup()
+
+	signingKey, err := keeper.ParseSecretKey(testSecretsKey)
+	require.NoError(t, err)
+	var out bytes.Buffer
+	node := keeper.Node{Name: "other", URL: "http://other:6688", InitiatorToChainlinkAccessKey: "otherIcKey", InitiatorToChainlinkSecret: "otherIcSecret"}
+	require.NoError(t, addNode(regStore, node, "otherKey", "otherSecret", nil, &out))
+
+	t.Run("creates credentials", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, createCredential(regStore, "other", "rotatedKey", "rotatedSecret", time.Hour, nil, &out))
+		assert.Equal(t, "Created credential rotatedKey for node other\n", out.String())
+		require.NoError(t, createCredential(regStore, keeper.DefaultNodeName, "defaultKey", "defaultSecret", 0, signingKey, &out))
+
+		credential, err := regStore.CredentialByAccessKey("rotatedKey")
+		require.NoError(t, err)
+		require.NotNil(t, credential.ExpiresAt)
+		assert.True(t, credential.Authenticate("rotatedSecret", time.Now()))
+		assert.Empty(t, credential.EncryptedSigningSecret)
+		credential, err = regStore.CredentialByAccessKey("defaultKey")
+		require.NoError(t, err)
+		assert.Nil(t, credential.NodeID)
+		assert.Nil(t, credential.ExpiresAt)
+		assert.NotContains(t, string(credential.EncryptedSigningSecret), "defaultSecret")
+		signingSecret, ok := credential.SigningSecret(signingKey)
+		require.True(t, ok)
+		assert.Equal(t, "defaultSecret", signingSecret)
+	})
+
+	t.Run("rejects unknown nodes and duplicate access keys", func(t *testing.T) {
+		assert.EqualError(t, createCredential(regStore, "unknown", "newKey", "newSecret", 0, nil, &out), "node unknown not found")
+		assert.Error(t, createCredential(regStore, "other", "otherKey", "newSecret", 0, nil, &out))
+		assert.Error(t, createCredential(regStore, "other", "newKey", "", 0, nil, &out))
+	})
+
+	t.Run("revokes credentials", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, revokeCredential(regStore, "otherKey", &out))
+		assert.Equal(t, "Revoked credential otherKey\n", out.String())
+		assert.Error(t, revokeCredential(regStore, "otherKey", &out))
+	})
+
+	t.Run("lists credentials without secrets", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, listCredentials(regStore, &out))
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 4)
+		assert.Regexp(t, `^otherKey\s+other\s+revoked`, lines[1])
+		assert.Regexp(t, `^rotatedKey\s+other\s+active`, lines[2])
+		assert.Regexp(t, `^defaultKey\s+default\s+active\s+\S+\s+never`, lines[3])
+		assert.NotContains(t, out.String(), "Secret")
+	})
+}
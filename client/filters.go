@@ -0,0 +1,131 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/external-initiator/keeper"
+)
+
+// CreateUpkeepFilterReq holds the payload expected for upkeep filter creations.
+// Field is one of upkeep_id, target or admin.
+type CreateUpkeepFilterReq struct {
+	Action keeper.FilterAction `json:"action"`
+	Field  keeper.FilterField  `json:"field"`
+	Value  string              `json:"value"`
+}
+
+// upkeepFilterPresenter is the API representation of a registry's upkeep filter.
+type upkeepFilterPresenter struct {
+	ID         uint32    `json:"id"`
+	RegistryID uint32    `json:"registryId"`
+	Action     string    `json:"action"`
+	Field      string    `json:"field"`
+	Value      string    `json:"value"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// ShowUpkeepFilters returns the upkeep filters of a registry the requesting node has a job on.
+func (srv *HttpService) ShowUpkeepFilters(c *gin.Context) {
+	reg, ok := srv.findNodeRegistry(c)
+	if !ok {
+		return
+	}
+	filters, err := srv.Store.UpkeepFilters(reg.ID)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+
+	presenters := make([]upkeepFilterPresenter, len(filters))
+	for idx, filter := range filters {
+		presenters[idx] = presentUpkeepFilter(filter)
+	}
+	c.JSON(http.StatusOK, presenters)
+}
+
+// CreateUpkeepFilter expects a CreateUpkeepFilterReq payload and adds the
+// filter to the registry, it applies from the next eligible upkeeps lookup.
+func (srv *HttpService) CreateUpkeepFilter(c *gin.Context) {
+	reg, ok := srv.findNodeRegistry(c)
+	if !ok {
+		return
+	}
+
+	var req CreateUpkeepFilterReq
+	if err := c.BindJSON(&req); err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusBadRequest, nil)
+		return
+	}
+	filter, err := keeper.NewUpkeepFilter(reg.ID, req.Action, req.Field, req.Value)
+	if err != nil {
+		jsonError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	filters, err := srv.Store.UpkeepFilters(reg.ID)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+	for _, existing := range filters {
+		if existing.Action == filter.Action && existing.Field == filter.Field && existing.Value == filter.Value {
+			jsonError(c, http.StatusConflict, fmt.Errorf("filter already exists with id %d", existing.ID))
+			return
+		}
+	}
+
+	filter, err = srv.Store.CreateUpkeepFilter(filter)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+	logger.Infow("Created upkeep filter", "registry", reg.Address.Hex(), "action", filter.Action, "field", filter.Field, "value", filter.Value)
+	c.JSON(http.StatusCreated, presentUpkeepFilter(filter))
+}
+
+// DeleteUpkeepFilter removes a filter from the registry.
+func (srv *HttpService) DeleteUpkeepFilter(c *gin.Context) {
+	reg, ok := srv.findNodeRegistry(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("filterId"), 10, 32)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusBadRequest, nil)
+		return
+	}
+
+	err = srv.Store.DeleteUpkeepFilter(reg.ID, uint32(id))
+	if gorm.IsRecordNotFoundError(err) {
+		c.JSON(http.StatusNotFound, nil)
+		return
+	} else if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+	logger.Infow("Deleted upkeep filter", "registry", reg.Address.Hex(), "id", id)
+	c.JSON(http.StatusOK, nil)
+}
+
+func presentUpkeepFilter(filter keeper.UpkeepFilter) upkeepFilterPresenter {
+	return upkeepFilterPresenter{
+		ID:         filter.ID,
+		RegistryID: filter.RegistryID,
+		Action:     string(filter.Action),
+		Field:      string(filter.Field),
+		Value:      filter.Value,
+		CreatedAt:  filter.CreatedAt,
+	}
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createFilterRequest(t *testing.T, srv *HttpService, registryID uint32, requestData CreateUpkeepFilterReq) *httptest.ResponseRecorder {
+	requestBytes, err := json.Marshal(requestData)
+	require.NoError(t, err)
+
+	request := httptest.NewRequest("POST", fmt.Sprintf("/registries/%d/filters", registryID), bytes.NewReader(requestBytes))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Add(ExternalInitiatorAccessKeyHeader, key)
+	request.Header.Add(ExternalInitiatorSecretHeader, secret)
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, request)
+	return w
+}
+
+func TestUpkeepFiltersController(t *testing.T) {
+	db, srv, _, cleanup := setupRegistriesController(t)
+	defer cleanup()
+
+	reg, _ := createSyncedRegistry(t, db)
+	target := "0x00000000000000000000000000000000000000aa"
+
+	w := createFilterRequest(t, srv, reg.ID, CreateUpkeepFilterReq{Action: "deny", Field: "target", Value: target})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created upkeepFilterPresenter
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, reg.ID, created.RegistryID)
+	assert.Equal(t, "deny", created.Action)
+	assert.Equal(t, "target", created.Field)
+
+	t.Run("rejects duplicates", func(t *testing.T) {
+		w := createFilterRequest(t, srv, reg.ID, CreateUpkeepFilterReq{Action: "deny", Field: "target", Value: strings.ToUpper(target[2:])})
+		require.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("rejects invalid filters", func(t *testing.T) {
+		for _, req := range []CreateUpkeepFilterReq{
+			{Action: "skip", Field: "target", Value: target},
+			{Action: "allow", Field: "checkData", Value: "0x"},
+			{Action: "allow", Field: "upkeep_id", Value: "-1"},
+			{Action: "allow", Field: "admin", Value: "0x1234"},
+		} {
+			w := createFilterRequest(t, srv, reg.ID, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, "%+v", req)
+		}
+	})
+
+	w = createFilterRequest(t, srv, reg.ID, CreateUpkeepFilterReq{Action: "allow", Field: "upkeep_id", Value: "7"})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = authenticatedGet(srv, fmt.Sprintf("/registries/%d/filters", reg.ID))
+	require.Equal(t, http.StatusOK, w.Code)
+	var filters []upkeepFilterPresenter
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &filters))
+	require.Len(t, filters, 2)
+	assert.Equal(t, created.ID, filters[0].ID)
+	assert.Equal(t, "7", filters[1].Value)
+
+	w = authenticatedRequest(srv, "DELETE", fmt.Sprintf("/registries/%d/filters/%d", reg.ID, created.ID))
+	require.Equal(t, http.StatusOK, w.Code)
+	w = authenticatedRequest(srv, "DELETE", fmt.Sprintf("/registries/%d/filters/%d", reg.ID, created.ID))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = authenticatedGet(srv, fmt.Sprintf("/registries/%d/filters", reg.ID+1))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
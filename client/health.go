@@ -0,0 +1,173 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smartcontractkit/external-initiator/chainlink"
+	"github.com/smartcontractkit/external-initiator/keeper"
+)
+
+// ReadinessCheck is a named check run by the /ready endpoint.
+// Check returns an error describing why the component isn't ready.
+type ReadinessCheck struct {
+	Name  string
+	Check func() error
+}
+
+type componentStatus struct {
+	Ready bool   `json:"ready"`
+	Error string `json:"error,omitempty"`
+}
+
+// ShowHealth is the liveness probe, it returns the following as long
+// as the web server is able to respond, along with the state of the
+// supervised components when the Supervisor is set:
+//  {"status": "ok", "components": {"executer": {"state": "running", ...}}}
+func (srv *HttpService) ShowHealth(c *gin.Context) {
+	if srv.Supervisor == nil {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "components": srv.Supervisor.Status()})
+}
+
+// ShowReadiness runs every readiness check and returns the result per
+// component. The status is 503 if any of the checks fail.
+func (srv *HttpService) ShowReadiness(c *gin.Context) {
+	status := http.StatusOK
+	components := make(map[string]componentStatus, len(srv.ReadinessChecks))
+	for _, check := range srv.ReadinessChecks {
+		if err := check.Check(); err != nil {
+			status = http.StatusServiceUnavailable
+			components[check.Name] = componentStatus{Ready: false, Error: err.Error()}
+		} else {
+			components[check.Name] = componentStatus{Ready: true}
+		}
+	}
+	c.JSON(status, components)
+}
+
+// databaseCheck pings the database.
+func databaseCheck(store keeper.Store) ReadinessCheck {
+	return ReadinessCheck{
+		Name: "database",
+		Check: func() error {
+			return store.DB().DB().Ping()
+		},
+	}
+}
+
+// headsCheck fails if the executer hasn't received a head within maxAge.
+func headsCheck(executer keeper.UpkeepExecuter, maxAge time.Duration) ReadinessCheck {
+	return ReadinessCheck{
+		Name: "heads",
+		Check: func() error {
+			status := executer.Status()
+			return checkAge("head received", status.LastHeadAt, status.StartedAt, maxAge)
+		},
+	}
+}
+
+// registrySyncCheck fails if no full registry sync succeeded within maxAge.
+func registrySyncCheck(synchronizer keeper.RegistrySynchronizer, maxAge time.Duration) ReadinessCheck {
+	return ReadinessCheck{
+		Name: "registrySync",
+		Check: func() error {
+			status := synchronizer.Status()
+			return checkAge("successful registry sync", status.LastSyncAt, status.StartedAt, maxAge)
+		},
+	}
+}
+
+// jobTriggerCheck fails if the most recent attempt to send a job run trigger
+// failed. Not having triggered any job yet doesn't count as a failure.
+func jobTriggerCheck(dispatcher keeper.TriggerDispatcher) ReadinessCheck {
+	return ReadinessCheck{
+		Name: "chainlink",
+		Check: func() error {
+			status := dispatcher.Status()
+			if status.LastTriggerFailureAt.After(status.LastTriggerAt) {
+				return fmt.Errorf("last job run trigger failed at %s", status.LastTriggerFailureAt.Format(time.RFC3339))
+			}
+			return nil
+		},
+	}
+}
+
+// circuitBreakerCheck fails unless the circuit breakers around the Chainlink nodes are
+// closed. Half open circuits fail too, until a probe reaches the node.
+func circuitBreakerCheck(router keeper.NodeRouter) ReadinessCheck {
+	return ReadinessCheck{
+		Name: "chainlink_circuit",
+		Check: func() error {
+			breakers := router.CircuitBreakers()
+			names := make([]string, 0, len(breakers))
+			for name := range breakers {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			var failures []string
+			for _, name := range names {
+				state, openedAt := breakers[name].State()
+				if state != chainlink.CircuitClosed {
+					failures = append(failures, fmt.Sprintf("%s circuit breaker %s, opened at %s", name, state, openedAt.Format(time.RFC3339)))
+				}
+			}
+			if len(failures) > 0 {
+				return errors.New(strings.Join(failures, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+// supervisorCheck fails unless every component of the supervisor is running
+func supervisorCheck(supervisor *Supervisor) ReadinessCheck {
+	return ReadinessCheck{
+		Name: "components",
+		Check: func() error {
+			status := supervisor.Status()
+			names := make([]string, 0, len(status))
+			for name := range status {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			var failures []string
+			for _, name := range names {
+				if health := status[name]; health.State != ComponentRunning {
+					failures = append(failures, fmt.Sprintf("%s is %s", name, health.State))
+				}
+			}
+			if len(failures) > 0 {
+				return errors.New(strings.Join(failures, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+// checkAge fails if last is older than maxAge. Components which haven't
+// seen the event yet are measured from the time they started.
+func checkAge(event string, last, startedAt time.Time, maxAge time.Duration) error {
+	if startedAt.IsZero() {
+		return fmt.Errorf("not started")
+	}
+	if last.IsZero() {
+		if time.Since(startedAt) > maxAge {
+			return fmt.Errorf("no %s since starting at %s", event, startedAt.Format(time.RFC3339))
+		}
+		return nil
+	}
+	if age := time.Since(last); age > maxAge {
+		return fmt.Errorf("last %s was %s ago", event, age.Round(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/external-initiator/chainlink"
+	"github.com/smartcontractkit/external-initiator/internal/mocks"
+	"github.com/smartcontractkit/external-initiator/keeper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeExecuter struct {
+	keeper.UpkeepExecuter
+	status keeper.ExecuterStatus
+}
+
+func (e fakeExecuter) Status() keeper.ExecuterStatus {
+	return e.status
+}
+
+type fakeDispatcher struct {
+	keeper.TriggerDispatcher
+	status keeper.DispatcherStatus
+}
+
+func (d fakeDispatcher) Status() keeper.DispatcherStatus {
+	return d.status
+}
+
+type fakeRouter struct {
+	keeper.NodeRouter
+	breakers map[string]chainlink.CircuitBreaker
+}
+
+func (r fakeRouter) CircuitBreakers() map[string]chainlink.CircuitBreaker {
+	return r.breakers
+}
+
+func TestReadinessController(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Checks     []ReadinessCheck
+		StatusCode int
+	}{
+		{
+			"Is ready",
+			[]ReadinessCheck{
+				{Name: "a", Check: func() error { return nil }},
+				{Name: "b", Check: func() error { return nil }},
+			},
+			http.StatusOK,
+		},
+		{
+			"Is not ready",
+			[]ReadinessCheck{
+				{Name: "a", Check: func() error { return nil }},
+				{Name: "b", Check: func() error { return errors.New("b is down") }},
+			},
+			http.StatusServiceUnavailable,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			srv := &HttpService{ReadinessChecks: test.Checks}
+			srv.createRouter()
+
+			req := httptest.NewRequest("GET", "/ready", nil)
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, req)
+			assert.Equal(t, test.StatusCode, w.Code)
+
+			var respJSON map[string]componentStatus
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &respJSON))
+			require.Len(t, respJSON, len(test.Checks))
+			for _, check := range test.Checks {
+				err := check.Check()
+				assert.Equal(t, err == nil, respJSON[check.Name].Ready)
+				if err != nil {
+					assert.Equal(t, err.Error(), respJSON[check.Name].Error)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckAge(t *testing.T) {
+	now := time.Now()
+	assert.Error(t, checkAge("head", time.Time{}, time.Time{}, time.Minute))
+	assert.NoError(t, checkAge("head", time.Time{}, now, time.Minute))
+	assert.Error(t, checkAge("head", time.Time{}, now.Add(-2*time.Minute), time.Minute))
+	assert.NoError(t, checkAge("head", now.Add(-30*time.Second), now.Add(-2*time.Minute), time.Minute))
+	assert.Error(t, checkAge("head", now.Add(-90*time.Second), now.Add(-2*time.Minute), time.Minute))
+}
+
+func TestJobTriggerCheck(t *testing.T) {
+	now := time.Now()
+	check := jobTriggerCheck(fakeDispatcher{})
+	assert.NoError(t, check.Check())
+
+	check = jobTriggerCheck(fakeDispatcher{status: keeper.DispatcherStatus{LastTriggerAt: now, LastTriggerFailureAt: now.Add(-time.Second)}})
+	assert.NoError(t, check.Check())
+
+	check = jobTriggerCheck(fakeDispatcher{status: keeper.DispatcherStatus{LastTriggerAt: now.Add(-time.Second), LastTriggerFailureAt: now}})
+	assert.Error(t, check.Check())
+}
+
+func TestCircuitBreakerCheck(t *testing.T) {
+	node := new(mocks.ChainlinkClient)
+	node.On("TriggerJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("", errors.New("connection refused"))
+	breaker := chainlink.NewCircuitBreaker("other", node, 1, time.Hour)
+	closed := chainlink.NewCircuitBreaker(keeper.DefaultNodeName, new(mocks.ChainlinkClient), 1, time.Hour)
+
+	check := circuitBreakerCheck(fakeRouter{breakers: map[string]chainlink.CircuitBreaker{
+		keeper.DefaultNodeName: closed,
+		"other":                breaker,
+	}})
+	assert.NoError(t, check.Check())
+
+	_, _ = breaker.TriggerJob("1", "", "", nil)
+	err := check.Check()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "other circuit breaker open")
+	assert.NotContains(t, err.Error(), keeper.DefaultNodeName)
+}
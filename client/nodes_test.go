@@ -0,0 +1,69 @@
+package client
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/smartcontractkit/external-initiator/keeper"
+	"github.com/smartcontractkit/external-initiator/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodesCommands(t *testing.T) {
+	dbClient, cleanup := store.SetupTestDB(t)
+	regStore := keeper.NewStore(dbClient.DB())
+	defer cleanup()
+
+	node := keeper.Node{
+		Name:                          "other",
+		URL:                           "http://other:6688",
+		InitiatorToChainlinkAccessKey: "otherIcKey",
+		InitiatorToChainlinkSecret:    "otherIcSecret",
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, addNode(regStore, node, "otherKey", "otherSecret", nil, &out))
+	assert.Equal(t, "Added node other\n", out.String())
+
+	credential, err := regStore.CredentialByAccessKey("otherKey")
+	require.NoError(t, err)
+	assert.NotNil(t, credential.NodeID)
+
+	t.Run("rejects invalid nodes", func(t *testing.T) {
+		invalid := node
+		invalid.Name = keeper.DefaultNodeName
+		assert.Error(t, addNode(regStore, invalid, "anotherKey", "anotherSecret", nil, &out))
+		assert.Error(t, addNode(regStore, keeper.Node{Name: "another", URL: node.URL, InitiatorToChainlinkAccessKey: "a", InitiatorToChainlinkSecret: "b"}, "", "", nil, &out))
+	})
+
+	t.Run("rejects duplicate names", func(t *testing.T) {
+		assert.Error(t, addNode(regStore, node, "anotherKey", "anotherSecret", nil, &out))
+	})
+
+	t.Run("lists the nodes", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, listNodes(regStore, &out))
+		assert.Contains(t, out.String(), "other")
+		assert.Contains(t, out.String(), node.URL)
+	})
+
+	t.Run("removes the node", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, removeNode(regStore, "other", &out))
+		assert.Equal(t, "Removed node other\n", out.String())
+		assert.EqualError(t, removeNode(regStore, "other", &out), "node other not found")
+	})
+}
+
+func TestReadSecrets(t *testing.T) {
+	var prompt bytes.Buffer
+	secrets, err := readSecrets(strings.NewReader("icSecret\n ciSecret \r\n"), &prompt, "ic_secret", "ci_secret")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"icSecret", "ciSecret"}, secrets)
+	assert.Equal(t, "ic_secret: ci_secret: \n", prompt.String())
+
+	_, err = readSecrets(strings.NewReader("icSecret\n"), &prompt, "ic_secret", "ci_secret")
+	assert.EqualError(t, err, "ci_secret must be given on stdin")
+}
@@ -0,0 +1,416 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/external-initiator/keeper"
+)
+
+const (
+	defaultPageSize = 25
+	maxPageSize     = 1000
+)
+
+// paginatedResponse wraps a page of results with the information
+// needed to request the remaining pages.
+type paginatedResponse struct {
+	Data  interface{} `json:"data"`
+	Count int         `json:"count"`
+	Page  int         `json:"page"`
+	Size  int         `json:"size"`
+}
+
+// registryPresenter is the API representation of a synced keeper registry.
+type registryPresenter struct {
+	ID                uint32                `json:"id"`
+	Address           string                `json:"address"`
+	Version           string                `json:"version"`
+	TurnTaking        string                `json:"turnTaking"`
+	BlockCountPerTurn uint32                `json:"blockCountPerTurn"`
+	CheckGas          uint32                `json:"checkGas"`
+	NumKeepers        uint32                `json:"numKeepers"`
+	Paused            bool                  `json:"paused"`
+	Memberships       []membershipPresenter `json:"memberships"`
+}
+
+// membershipPresenter is the API representation of one of our keepers on a registry.
+type membershipPresenter struct {
+	ID          uint32 `json:"id"`
+	ReferenceID string `json:"referenceId"`
+	JobID       string `json:"jobId"`
+	From        string `json:"from"`
+	KeeperIndex uint32 `json:"keeperIndex"`
+	Orphaned    bool   `json:"orphaned"`
+}
+
+// upkeepPresenter is the API representation of a synced upkeep. NextEligibleBlock is the
+// earliest block at which any of our keepers can perform the upkeep, it is omitted when
+// the registry's turn taking strategy can't predict it.
+type upkeepPresenter struct {
+	UpkeepID            uint64  `json:"upkeepId"`
+	RegistryID          uint32  `json:"registryId"`
+	ExecuteGas          uint32  `json:"executeGas"`
+	CheckData           string  `json:"checkData"`
+	PositioningConstant uint32  `json:"positioningConstant"`
+	Target              string  `json:"target"`
+	Admin               string  `json:"admin"`
+	Paused              bool    `json:"paused"`
+	NextEligibleBlock   *uint64 `json:"nextEligibleBlock,omitempty"`
+}
+
+// ShowRegistries returns a page of the registries the requesting node has a job on.
+func (srv *HttpService) ShowRegistries(c *gin.Context) {
+	page, size, err := parsePagination(c)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusBadRequest, nil)
+		return
+	}
+
+	nodeID := requestNodeID(c)
+	registries, count, err := srv.Store.PaginatedRegistries(nodeID, (page-1)*size, size)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+
+	registryIDs := make([]uint32, len(registries))
+	for idx, reg := range registries {
+		registryIDs[idx] = reg.ID
+	}
+	memberships, err := srv.Store.MembershipsForRegistries(registryIDs)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+
+	memberships = nodeMemberships(memberships, nodeID)
+
+	presenters := make([]registryPresenter, len(registries))
+	for idx, reg := range registries {
+		presenters[idx] = presentRegistry(reg, memberships)
+	}
+	c.JSON(http.StatusOK, paginatedResponse{Data: presenters, Count: count, Page: page, Size: size})
+}
+
+// ShowRegistry returns the registry with the id provided as parameter in the request,
+// along with the requesting node's memberships on it.
+func (srv *HttpService) ShowRegistry(c *gin.Context) {
+	reg, ok := srv.findRegistry(c)
+	if !ok {
+		return
+	}
+	memberships, ok := srv.findNodeMemberships(c, reg)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, presentRegistry(reg, memberships))
+}
+
+// ShowUpkeeps returns a page of the upkeeps synced from a registry.
+func (srv *HttpService) ShowUpkeeps(c *gin.Context) {
+	reg, ok := srv.findRegistry(c)
+	if !ok {
+		return
+	}
+	memberships, ok := srv.findNodeMemberships(c, reg)
+	if !ok {
+		return
+	}
+
+	page, size, err := parsePagination(c)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusBadRequest, nil)
+		return
+	}
+
+	upkeeps, count, err := srv.Store.PaginatedUpkeeps(reg.ID, (page-1)*size, size)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+
+	blockNumber := srv.latestBlockNumber()
+	presenters := make([]upkeepPresenter, len(upkeeps))
+	for idx, upkeep := range upkeeps {
+		presenters[idx] = presentUpkeep(upkeep, reg, memberships, blockNumber)
+	}
+	c.JSON(http.StatusOK, paginatedResponse{Data: presenters, Count: count, Page: page, Size: size})
+}
+
+// ShowUpkeep returns a single upkeep synced from a registry.
+func (srv *HttpService) ShowUpkeep(c *gin.Context) {
+	reg, ok := srv.findRegistry(c)
+	if !ok {
+		return
+	}
+	memberships, ok := srv.findNodeMemberships(c, reg)
+	if !ok {
+		return
+	}
+
+	upkeep, ok := srv.findUpkeep(c, reg)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, presentUpkeep(upkeep, reg, memberships, srv.latestBlockNumber()))
+}
+
+// PauseRegistry stops performing upkeeps on a registry until it is resumed.
+// The registry and its upkeeps keep being synced while paused. The paused state
+// belongs to the registry, so the keepers of every node on it stop performing.
+func (srv *HttpService) PauseRegistry(c *gin.Context) {
+	srv.setRegistryPaused(c, true)
+}
+
+// ResumeRegistry resumes performing upkeeps on a paused registry.
+func (srv *HttpService) ResumeRegistry(c *gin.Context) {
+	srv.setRegistryPaused(c, false)
+}
+
+// PauseUpkeep stops performing a single upkeep until it is resumed, by the keepers of every node.
+func (srv *HttpService) PauseUpkeep(c *gin.Context) {
+	srv.setUpkeepPaused(c, true)
+}
+
+// ResumeUpkeep resumes performing a paused upkeep.
+func (srv *HttpService) ResumeUpkeep(c *gin.Context) {
+	srv.setUpkeepPaused(c, false)
+}
+
+func (srv *HttpService) setRegistryPaused(c *gin.Context, paused bool) {
+	reg, ok := srv.findRegistry(c)
+	if !ok {
+		return
+	}
+	memberships, ok := srv.findNodeMemberships(c, reg)
+	if !ok {
+		return
+	}
+	if err := srv.Store.SetRegistryPaused(reg.ID, paused); err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+	logger.Infow("Updated registry paused state", "registry", reg.Address.Hex(), "paused", paused)
+	reg.Paused = paused
+	c.JSON(http.StatusOK, presentRegistry(reg, memberships))
+}
+
+func (srv *HttpService) setUpkeepPaused(c *gin.Context, paused bool) {
+	reg, ok := srv.findRegistry(c)
+	if !ok {
+		return
+	}
+	memberships, ok := srv.findNodeMemberships(c, reg)
+	if !ok {
+		return
+	}
+	upkeep, ok := srv.findUpkeep(c, reg)
+	if !ok {
+		return
+	}
+	if err := srv.Store.SetUpkeepPaused(reg.ID, upkeep.UpkeepID, paused); err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+	logger.Infow("Updated upkeep paused state", "registry", reg.Address.Hex(), "upkeepID", upkeep.UpkeepID, "paused", paused)
+	upkeep.Paused = paused
+	c.JSON(http.StatusOK, presentUpkeep(upkeep, reg, memberships, srv.latestBlockNumber()))
+}
+
+// findRegistry loads the registry referenced by the id param, writing
+// the error response and returning false if it can't be found.
+func (srv *HttpService) findRegistry(c *gin.Context) (reg keeper.Registry, ok bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusBadRequest, nil)
+		return reg, false
+	}
+
+	reg, err = srv.Store.RegistryByID(uint32(id))
+	if gorm.IsRecordNotFoundError(err) {
+		c.JSON(http.StatusNotFound, nil)
+		return reg, false
+	} else if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return reg, false
+	}
+	return reg, true
+}
+
+// findNodeRegistry loads the registry referenced by the id param if the requesting
+// node has a job on it, writing the error response and returning false otherwise.
+func (srv *HttpService) findNodeRegistry(c *gin.Context) (keeper.Registry, bool) {
+	reg, ok := srv.findRegistry(c)
+	if !ok {
+		return reg, false
+	}
+	if _, ok = srv.findNodeMemberships(c, reg); !ok {
+		return reg, false
+	}
+	return reg, true
+}
+
+// findUpkeep loads the upkeep referenced by the upkeepId param, writing
+// the error response and returning false if it can't be found.
+func (srv *HttpService) findUpkeep(c *gin.Context, reg keeper.Registry) (upkeep keeper.Registration, ok bool) {
+	upkeepID, err := strconv.ParseUint(c.Param("upkeepId"), 10, 64)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusBadRequest, nil)
+		return upkeep, false
+	}
+
+	upkeep, err = srv.Store.UpkeepByID(reg.ID, upkeepID)
+	if gorm.IsRecordNotFoundError(err) {
+		c.JSON(http.StatusNotFound, nil)
+		return upkeep, false
+	} else if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return upkeep, false
+	}
+	return upkeep, true
+}
+
+// findMemberships loads the memberships of a registry, writing the
+// error response and returning false if they can't be loaded.
+func (srv *HttpService) findMemberships(c *gin.Context, reg keeper.Registry) ([]keeper.Membership, bool) {
+	memberships, err := srv.Store.MembershipsForRegistries([]uint32{reg.ID})
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return nil, false
+	}
+	return memberships, true
+}
+
+// findNodeMemberships loads the memberships of a registry created by the requesting
+// node, writing the error response and returning false if it has none.
+func (srv *HttpService) findNodeMemberships(c *gin.Context, reg keeper.Registry) ([]keeper.Membership, bool) {
+	memberships, ok := srv.findNodeMemberships(c, reg)
+	if !ok {
+		return nil, false
+	}
+	memberships = nodeMemberships(memberships, requestNodeID(c))
+	if len(memberships) == 0 {
+		c.JSON(http.StatusNotFound, nil)
+		return nil, false
+	}
+	return memberships, true
+}
+
+// nodeMemberships picks the memberships created by the node, nil for the default node
+func nodeMemberships(memberships []keeper.Membership, nodeID *uint32) []keeper.Membership {
+	var picked []keeper.Membership
+	for _, membership := range memberships {
+		if membership.BelongsTo(nodeID) {
+			picked = append(picked, membership)
+		}
+	}
+	return picked
+}
+
+// latestBlockNumber returns the current block number, or nil if it can't be fetched,
+// in which case next eligible blocks are left out of the response.
+func (srv *HttpService) latestBlockNumber() *uint64 {
+	if srv.EthClient == nil {
+		return nil
+	}
+	head, err := srv.EthClient.HeaderByNumber(context.Background(), nil)
+	if err != nil || head == nil {
+		logger.Warnf("unable to fetch latest head: %v", err)
+		return nil
+	}
+	blockNumber := uint64(head.Number)
+	return &blockNumber
+}
+
+func parsePagination(c *gin.Context) (page, size int, err error) {
+	page, err = strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		return 0, 0, errors.New("invalid page param")
+	}
+	size, err = strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(defaultPageSize)))
+	if err != nil || size < 1 || size > maxPageSize {
+		return 0, 0, errors.New("invalid size param")
+	}
+	return page, size, nil
+}
+
+// presentRegistry presents the registry along with its memberships, which
+// are picked from memberships of any registries
+func presentRegistry(reg keeper.Registry, memberships []keeper.Membership) registryPresenter {
+	presenter := registryPresenter{
+		ID:                reg.ID,
+		Address:           reg.Address.Hex(),
+		Version:           string(reg.Version),
+		TurnTaking:        string(reg.TurnTaking),
+		BlockCountPerTurn: reg.BlockCountPerTurn,
+		CheckGas:          reg.CheckGas,
+		NumKeepers:        reg.NumKeepers,
+		Paused:            reg.Paused,
+		Memberships:       []membershipPresenter{},
+	}
+	for _, membership := range memberships {
+		if membership.RegistryID != reg.ID {
+			continue
+		}
+		membershipPresenter := membershipPresenter{
+			ID:          membership.ID,
+			ReferenceID: membership.ReferenceID,
+			From:        membership.From.Hex(),
+			KeeperIndex: membership.KeeperIndex,
+			Orphaned:    membership.Orphaned,
+		}
+		if membership.JobID != nil {
+			membershipPresenter.JobID = membership.JobID.String()
+		}
+		presenter.Memberships = append(presenter.Memberships, membershipPresenter)
+	}
+	return presenter
+}
+
+func presentUpkeep(upkeep keeper.Registration, reg keeper.Registry, memberships []keeper.Membership, blockNumber *uint64) upkeepPresenter {
+	presenter := upkeepPresenter{
+		UpkeepID:            upkeep.UpkeepID,
+		RegistryID:          upkeep.RegistryID,
+		ExecuteGas:          upkeep.ExecuteGas,
+		CheckData:           hexutil.Encode(upkeep.CheckData),
+		PositioningConstant: upkeep.PositioningConstant,
+		Target:              upkeep.Target.Hex(),
+		Admin:               upkeep.Admin.Hex(),
+		Paused:              upkeep.Paused,
+	}
+	if blockNumber == nil {
+		return presenter
+	}
+	turnTaker, err := reg.TurnTaker()
+	if err != nil {
+		logger.Error(err)
+		return presenter
+	}
+	for _, membership := range memberships {
+		membership.Registry = reg
+		next, ok := turnTaker.NextEligibleBlock(upkeep, membership, *blockNumber)
+		if ok && (presenter.NextEligibleBlock == nil || next < *presenter.NextEligibleBlock) {
+			presenter.NextEligibleBlock = &next
+		}
+	}
+	return presenter
+}
@@ -0,0 +1,358 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/smartcontractkit/chainlink/core/store/models"
+	"github.com/smartcontractkit/external-initiator/chainlink"
+	"github.com/smartcontractkit/external-initiator/eitest"
+	"github.com/smartcontractkit/external-initiator/internal/mocks"
+	"github.com/smartcontractkit/external-initiator/keeper"
+	"github.com/smartcontractkit/external-initiator/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRegistriesController(t *testing.T) (*gorm.DB, *HttpService, *mocks.EthClient, func()) {
+	dbClient, cleanup := store.SetupTestDB(t)
+	ethMock := new(mocks.EthClient)
+	srv := &HttpService{
+		AccessKey: key,
+		Secret:    secret,
+		Store:     keeper.NewStore(dbClient.DB()),
+		EthClient: ethMock,
+	}
+	srv.createRouter()
+	return dbClient.DB(), srv, ethMock, cleanup
+}
+
+func createSyncedRegistry(t *testing.T, db *gorm.DB) (keeper.Registry, keeper.Membership) {
+	reg := keeper.NewRegistry(eitest.NewAddress())
+	reg.BlockCountPerTurn = 20
+	reg.CheckGas = 2_000_000
+	reg.NumKeepers = 5
+	require.NoError(t, db.Create(&reg).Error)
+	membership := keeper.NewMembership(reg, eitest.NewAddress(), models.NewID())
+	membership.KeeperIndex = 2
+	require.NoError(t, db.Create(&membership).Error)
+	return reg, membership
+}
+
+func authenticatedGet(srv *HttpService, path string) *httptest.ResponseRecorder {
+	return authenticatedRequest(srv, "GET", path)
+}
+
+func authenticatedRequest(srv *HttpService, method, path string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, nil)
+	request.Header.Add(ExternalInitiatorAccessKeyHeader, key)
+	request.Header.Add(ExternalInitiatorSecretHeader, secret)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, request)
+	return w
+}
+
+func TestShowRegistries(t *testing.T) {
+	db, srv, _, cleanup := setupRegistriesController(t)
+	defer cleanup()
+
+	for i := 0; i < 3; i++ {
+		createSyncedRegistry(t, db)
+	}
+
+	w := authenticatedGet(srv, "/registries?page=2&size=2")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data  []registryPresenter `json:"data"`
+		Count int                 `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.Count)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, uint32(20), response.Data[0].BlockCountPerTurn)
+	require.Len(t, response.Data[0].Memberships, 1)
+	assert.Equal(t, uint32(2), response.Data[0].Memberships[0].KeeperIndex)
+
+	w = authenticatedGet(srv, "/registries?size=0")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestShowRegistry(t *testing.T) {
+	db, srv, _, cleanup := setupRegistriesController(t)
+	defer cleanup()
+
+	reg, membership := createSyncedRegistry(t, db)
+
+	w := authenticatedGet(srv, fmt.Sprintf("/registries/%d", reg.ID))
+	require.Equal(t, http.StatusOK, w.Code)
+	var presenter registryPresenter
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &presenter))
+	assert.Equal(t, reg.Address.Hex(), presenter.Address)
+	require.Len(t, presenter.Memberships, 1)
+	assert.Equal(t, membership.JobID.String(), presenter.Memberships[0].JobID)
+	assert.Equal(t, membership.From.Hex(), presenter.Memberships[0].From)
+	assert.Equal(t, reg.CheckGas, presenter.CheckGas)
+
+	w = authenticatedGet(srv, fmt.Sprintf("/registries/%d", reg.ID+1))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = authenticatedGet(srv, "/registries/abc")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestShowUpkeeps(t *testing.T) {
+	db, srv, ethMock, cleanup := setupRegistriesController(t)
+	defer cleanup()
+
+	reg, _ := createSyncedRegistry(t, db)
+	for upkeepID := uint64(0); upkeepID < 3; upkeepID++ {
+		upkeep := keeper.Registration{
+			RegistryID:          reg.ID,
+			UpkeepID:            upkeepID,
+			ExecuteGas:          10_000,
+			CheckData:           common.Hex2Bytes("1234"),
+			PositioningConstant: 4,
+		}
+		require.NoError(t, db.Create(&upkeep).Error)
+	}
+
+	head := models.NewHead(big.NewInt(61), eitest.NewHash(), eitest.NewHash(), 1000)
+	ethMock.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&head, nil)
+
+	t.Run("lists upkeeps", func(t *testing.T) {
+		w := authenticatedGet(srv, fmt.Sprintf("/registries/%d/upkeeps", reg.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data  []upkeepPresenter `json:"data"`
+			Count int               `json:"count"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 3, response.Count)
+		require.Len(t, response.Data, 3)
+		assert.Equal(t, uint64(0), response.Data[0].UpkeepID)
+		assert.Equal(t, "0x1234", response.Data[0].CheckData)
+	})
+
+	t.Run("shows a single upkeep", func(t *testing.T) {
+		w := authenticatedGet(srv, fmt.Sprintf("/registries/%d/upkeeps/1", reg.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var presenter upkeepPresenter
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &presenter))
+		assert.Equal(t, uint64(1), presenter.UpkeepID)
+		assert.Equal(t, uint32(4), presenter.PositioningConstant)
+		require.NotNil(t, presenter.NextEligibleBlock)
+		assert.Equal(t, uint64(160), *presenter.NextEligibleBlock)
+	})
+
+	t.Run("404s on unknown upkeeps", func(t *testing.T) {
+		w := authenticatedGet(srv, fmt.Sprintf("/registries/%d/upkeeps/5", reg.ID))
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestPauseAndResume(t *testing.T) {
+	db, srv, ethMock, cleanup := setupRegistriesController(t)
+	defer cleanup()
+
+	reg, _ := createSyncedRegistry(t, db)
+	upkeep := keeper.Registration{
+		RegistryID: reg.ID,
+		UpkeepID:   0,
+		ExecuteGas: 10_000,
+	}
+	require.NoError(t, db.Create(&upkeep).Error)
+	ethMock.On("HeaderByNumber", mock.Anything, mock.Anything).Return(nil, errors.New("unavailable"))
+
+	t.Run("registries", func(t *testing.T) {
+		w := authenticatedRequest(srv, "POST", fmt.Sprintf("/registries/%d/pause", reg.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+		var presenter registryPresenter
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &presenter))
+		assert.True(t, presenter.Paused)
+
+		w = authenticatedGet(srv, fmt.Sprintf("/registries/%d", reg.ID))
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &presenter))
+		assert.True(t, presenter.Paused)
+
+		w = authenticatedRequest(srv, "POST", fmt.Sprintf("/registries/%d/resume", reg.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &presenter))
+		assert.False(t, presenter.Paused)
+
+		w = authenticatedRequest(srv, "POST", fmt.Sprintf("/registries/%d/pause", reg.ID+1))
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("upkeeps", func(t *testing.T) {
+		w := authenticatedRequest(srv, "POST", fmt.Sprintf("/registries/%d/upkeeps/0/pause", reg.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+		var presenter upkeepPresenter
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &presenter))
+		assert.True(t, presenter.Paused)
+
+		w = authenticatedRequest(srv, "POST", fmt.Sprintf("/registries/%d/upkeeps/0/resume", reg.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &presenter))
+		assert.False(t, presenter.Paused)
+
+		w = authenticatedRequest(srv, "POST", fmt.Sprintf("/registries/%d/upkeeps/1/pause", reg.ID))
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestRegistriesController_ScopedToNode(t *testing.T) {
+	db, srv, _, cleanup := setupRegistriesController(t)
+	defer cleanup()
+
+	credential, err := keeper.NewCredential(nil, "otherKey", "otherSecret", nil)
+	require.NoError(t, err)
+	node, err := srv.Store.CreateNode(keeper.Node{
+		Name:                          "other",
+		URL:                           "http://other:6688",
+		InitiatorToChainlinkAccessKey: "otherIcKey",
+		InitiatorToChainlinkSecret:    "otherIcSecret",
+	}, credential)
+	require.NoError(t, err)
+
+	reg, membership := createSyncedRegistry(t, db)
+	upkeep := keeper.Registration{RegistryID: reg.ID, UpkeepID: 0, ExecuteGas: 10_000}
+	require.NoError(t, db.Create(&upkeep).Error)
+	filter, err := srv.Store.CreateUpkeepFilter(keeper.UpkeepFilter{RegistryID: reg.ID, Action: keeper.DenyFilter, Field: keeper.UpkeepIDField, Value: "1"})
+	require.NoError(t, err)
+	dead := keeper.JobTrigger{MembershipID: membership.ID, IdempotencyKey: "dead", Payload: []byte(`{}`), Status: keeper.TriggerDead, NextAttemptAt: time.Now()}
+	require.NoError(t, db.Create(&dead).Error)
+	require.NoError(t, db.Create(&keeper.JobRun{MembershipID: membership.ID, RunID: "run", Status: chainlink.RunStatusPending}).Error)
+
+	t.Run("hides the registry of the default node's jobs", func(t *testing.T) {
+		for _, path := range []string{
+			fmt.Sprintf("/registries/%d/pause", reg.ID),
+			fmt.Sprintf("/registries/%d/resume", reg.ID),
+			fmt.Sprintf("/registries/%d/upkeeps/0/pause", reg.ID),
+			fmt.Sprintf("/registries/%d/upkeeps/0/resume", reg.ID),
+			fmt.Sprintf("/registries/%d/upkeeps/0/check?from=%s", reg.ID, membership.From.Hex()),
+			fmt.Sprintf("/registries/%d/filters", reg.ID),
+			fmt.Sprintf("/triggers/%d/replay", dead.ID),
+		} {
+			w := nodeRequest(t, srv, "POST", path, CreateUpkeepFilterReq{Action: keeper.AllowFilter, Field: keeper.UpkeepIDField, Value: "0"}, "otherKey", "otherSecret")
+			assert.Equal(t, http.StatusNotFound, w.Code, path)
+		}
+		for _, path := range []string{
+			fmt.Sprintf("/registries/%d", reg.ID),
+			fmt.Sprintf("/registries/%d/upkeeps", reg.ID),
+			fmt.Sprintf("/registries/%d/upkeeps/0", reg.ID),
+			fmt.Sprintf("/registries/%d/filters", reg.ID),
+		} {
+			w := nodeRequest(t, srv, "GET", path, nil, "otherKey", "otherSecret")
+			assert.Equal(t, http.StatusNotFound, w.Code, path)
+		}
+		w := nodeRequest(t, srv, "GET", "/registries", nil, "otherKey", "otherSecret")
+		require.Equal(t, http.StatusOK, w.Code)
+		var registries struct {
+			Count int `json:"count"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registries))
+		assert.Equal(t, 0, registries.Count)
+		w = nodeRequest(t, srv, "DELETE", fmt.Sprintf("/registries/%d/filters/%d", reg.ID, filter.ID), nil, "otherKey", "otherSecret")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		reg, err = srv.Store.RegistryByID(reg.ID)
+		require.NoError(t, err)
+		assert.False(t, reg.Paused)
+		eitest.AssertCount(t, db, keeper.UpkeepFilter{}, 1)
+		eitest.AssertCount(t, db, keeper.JobTrigger{}, 1)
+		require.NoError(t, db.First(&dead, dead.ID).Error)
+		assert.Equal(t, keeper.TriggerDead, dead.Status)
+	})
+
+	t.Run("lists the triggers and runs of the node's jobs", func(t *testing.T) {
+		var response struct {
+			Count int `json:"count"`
+		}
+		for _, path := range []string{"/triggers", "/runs"} {
+			w := nodeRequest(t, srv, "GET", path, nil, "otherKey", "otherSecret")
+			require.Equal(t, http.StatusOK, w.Code)
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, 0, response.Count, path)
+
+			w = authenticatedGet(srv, path)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, 1, response.Count, path)
+		}
+	})
+
+	t.Run("manages the registry of the node's jobs", func(t *testing.T) {
+		otherMembership := keeper.NewMembership(reg, eitest.NewAddress(), models.NewID())
+		otherMembership.NodeID = &node.ID
+		require.NoError(t, db.Create(&otherMembership).Error)
+
+		w := nodeRequest(t, srv, "GET", fmt.Sprintf("/registries/%d/filters", reg.ID), nil, "otherKey", "otherSecret")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		// only the node's own memberships are presented
+		for _, request := range []struct{ method, path string }{
+			{"GET", fmt.Sprintf("/registries/%d", reg.ID)},
+			{"POST", fmt.Sprintf("/registries/%d/resume", reg.ID)},
+		} {
+			w = nodeRequest(t, srv, request.method, request.path, nil, "otherKey", "otherSecret")
+			require.Equal(t, http.StatusOK, w.Code, request.path)
+			var presenter registryPresenter
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &presenter))
+			require.Len(t, presenter.Memberships, 1, request.path)
+			assert.Equal(t, otherMembership.JobID.String(), presenter.Memberships[0].JobID)
+		}
+		w = nodeRequest(t, srv, "GET", "/registries", nil, "otherKey", "otherSecret")
+		require.Equal(t, http.StatusOK, w.Code)
+		var registries struct {
+			Data []registryPresenter `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registries))
+		require.Len(t, registries.Data, 1)
+		require.Len(t, registries.Data[0].Memberships, 1)
+		assert.Equal(t, otherMembership.From.Hex(), registries.Data[0].Memberships[0].From)
+
+		w = nodeRequest(t, srv, "POST", fmt.Sprintf("/registries/%d/upkeeps/0/check?from=%s", reg.ID, membership.From.Hex()), nil, "otherKey", "otherSecret")
+		assert.Equal(t, http.StatusNotFound, w.Code, "the keeper of another node")
+	})
+}
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		query   string
+		page    int
+		size    int
+		wantErr bool
+	}{
+		{"", 1, defaultPageSize, false},
+		{"?page=3&size=10", 3, 10, false},
+		{"?page=0", 0, 0, true},
+		{"?size=-1", 0, 0, true},
+		{fmt.Sprintf("?size=%d", maxPageSize+1), 0, 0, true},
+		{"?page=abc", 0, 0, true},
+	}
+	for _, test := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/registries"+test.query, nil)
+		page, size, err := parsePagination(c)
+		if test.wantErr {
+			assert.Error(t, err, test.query)
+			continue
+		}
+		require.NoError(t, err, test.query)
+		assert.Equal(t, test.page, page)
+		assert.Equal(t, test.size, size)
+	}
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/external-initiator/chainlink"
+	"github.com/smartcontractkit/external-initiator/keeper"
+)
+
+// jobRunPresenter is the API representation of a job run triggered to perform an upkeep.
+type jobRunPresenter struct {
+	ID          uint32    `json:"id"`
+	JobID       string    `json:"jobId"`
+	RegistryID  uint32    `json:"registryId"`
+	From        string    `json:"from"`
+	UpkeepID    uint64    `json:"upkeepId"`
+	BlockNumber int64     `json:"blockNumber"`
+	RunID       string    `json:"runId"`
+	Status      string    `json:"status"`
+	TxHash      string    `json:"txHash,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// ShowJobRuns returns a page of the job runs triggered for the requesting node's
+// jobs, the most recent first.
+// The status query param filters the runs, e.g. status=errored lists the failed runs.
+func (srv *HttpService) ShowJobRuns(c *gin.Context) {
+	page, size, err := parsePagination(c)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusBadRequest, nil)
+		return
+	}
+
+	runs, count, err := srv.Store.PaginatedJobRuns(requestNodeID(c), chainlink.RunStatus(c.Query("status")), (page-1)*size, size)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+
+	presenters := make([]jobRunPresenter, len(runs))
+	for idx, run := range runs {
+		presenters[idx] = presentJobRun(run)
+	}
+	c.JSON(http.StatusOK, paginatedResponse{Data: presenters, Count: count, Page: page, Size: size})
+}
+
+func presentJobRun(run keeper.JobRun) jobRunPresenter {
+	presenter := jobRunPresenter{
+		ID:          run.ID,
+		RegistryID:  run.Membership.RegistryID,
+		From:        run.Membership.From.Hex(),
+		UpkeepID:    run.UpkeepID,
+		BlockNumber: run.BlockNumber,
+		RunID:       run.RunID,
+		Status:      string(run.Status),
+		TxHash:      run.TxHash,
+		Error:       run.Error,
+		CreatedAt:   run.CreatedAt,
+		UpdatedAt:   run.UpdatedAt,
+	}
+	if run.Membership.JobID != nil {
+		presenter.JobID = run.Membership.JobID.String()
+	}
+	return presenter
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/smartcontractkit/external-initiator/chainlink"
+	"github.com/smartcontractkit/external-initiator/keeper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowJobRuns(t *testing.T) {
+	db, srv, _, cleanup := setupRegistriesController(t)
+	defer cleanup()
+
+	reg, membership := createSyncedRegistry(t, db)
+	for _, status := range []chainlink.RunStatus{chainlink.RunStatusCompleted, chainlink.RunStatusErrored, chainlink.RunStatusPending} {
+		run := keeper.JobRun{MembershipID: membership.ID, UpkeepID: 3, BlockNumber: 40, RunID: string(status), Status: status}
+		if status == chainlink.RunStatusErrored {
+			run.Error = "out of gas"
+		}
+		require.NoError(t, db.Create(&run).Error)
+	}
+
+	w := authenticatedGet(srv, "/runs?status=errored")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data  []jobRunPresenter `json:"data"`
+		Count int               `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, "errored", response.Data[0].Status)
+	assert.Equal(t, "out of gas", response.Data[0].Error)
+	assert.Equal(t, membership.JobID.String(), response.Data[0].JobID)
+	assert.Equal(t, reg.ID, response.Data[0].RegistryID)
+	assert.Equal(t, uint64(3), response.Data[0].UpkeepID)
+
+	w = authenticatedGet(srv, "/runs")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.Count)
+	assert.Equal(t, "pending", response.Data[0].Status)
+}
@@ -6,8 +6,10 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
 	"github.com/smartcontractkit/chainlink/core/logger"
 	"github.com/smartcontractkit/chainlink/core/services/eth"
 	"github.com/smartcontractkit/external-initiator/chainlink"
@@ -21,69 +23,91 @@ type storeInterface interface {
 }
 
 // startService runs the Service in the background and gracefully stops when a
-// SIGINT is received.
+// SIGINT or SIGTERM is received. It returns the error if the Service can't be
+// started, leaving the database client to be closed by the caller.
 func startService(
 	config Config,
 	dbClient *store.Client,
-) {
+) error {
 	logger.Info("Starting External Initiator")
 
 	clUrl, err := url.Parse(normalizeLocalhost(config.ChainlinkURL))
 	if err != nil {
-		logger.Fatal(err)
+		return err
 	}
 
-	retryConfig := chainlink.RetryConfig{
-		Timeout:  config.ChainlinkTimeout,
-		Attempts: config.ChainlinkRetryAttempts,
-		Delay:    config.ChainlinkRetryDelay,
+	jobEndpoint, err := chainlink.ParseJobEndpoint(config.ChainlinkJobEndpoint)
+	if err != nil {
+		return err
+	}
+
+	authMode, err := chainlink.ParseAuthMode(config.InitiatorToChainlinkAuthMode)
+	if err != nil {
+		return err
+	}
+	if _, err = chainlink.ParseAuthMode(config.ChainlinkToInitiatorAuthMode); err != nil {
+		return err
+	}
+
+	tlsConfig, err := newChainlinkTLSConfig(config)
+	if err != nil {
+		return err
 	}
 
 	chainlinkClient := chainlink.NewClient(
 		config.InitiatorToChainlinkAccessKey,
 		config.InitiatorToChainlinkSecret,
 		*clUrl,
-		retryConfig,
+		jobEndpoint,
+		authMode,
+		chainlink.APIToken{Key: config.ChainlinkAPIKey, Secret: config.ChainlinkAPISecret},
+		tlsConfig,
+		chainlinkRetryConfig(config),
 	)
 
 	ethClient, err := eth.NewClient(config.KeeperEthEndpoint)
 	if err != nil {
-		logger.Fatal(err)
+		return err
 	}
 	err = ethClient.Dial(context.Background())
 	if err != nil {
-		logger.Fatal(err)
+		return err
 	}
 
 	srv := NewService(dbClient, chainlinkClient, ethClient, config)
-
-	go func() {
-		err := srv.Run()
-		if err != nil {
-			logger.Fatal(err)
-		}
-	}()
+	// the supervised components are restarted when they fail, instead of exiting
+	if err = srv.Run(); err != nil {
+		srv.Close()
+		return errors.Wrap(err, "unable to start the service")
+	}
 
 	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, os.Interrupt)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
 	<-sig
 	logger.Info("Shutting down...")
 	srv.Close()
-	os.Exit(0)
+	return nil
 }
 
 // Service holds the main process for running
 // the external initiator.
 type Service struct {
 	clNode               chainlink.Client
+	nodeRouter           keeper.NodeRouter
+	ethClient            eth.Client
 	keeperStore          keeper.Store
 	config               Config
 	upkeepExecuter       keeper.UpkeepExecuter
 	registrySynchronizer keeper.RegistrySynchronizer
+	jobReconciler        keeper.JobReconciler
+	triggerDispatcher    keeper.TriggerDispatcher
+	runPoller            keeper.RunPoller
+	supervisor           *Supervisor
 }
 
-// NewService returns a new instance of Service, using
-// the provided database client and Chainlink node config.
+// NewService returns a new instance of Service, using the provided database
+// client and default Chainlink node. The clients of the nodes saved in the
+// database are created from the config.
 func NewService(
 	dbClient storeInterface,
 	clNode chainlink.Client,
@@ -91,48 +115,132 @@ func NewService(
 	config Config,
 ) *Service {
 	keeperStore := keeper.NewStore(dbClient.DB())
-	upkeepExecuter := keeper.NewUpkeepExecuter(keeperStore, clNode, ethClient)
+	// requests to each node fail fast while it is unhealthy
+	defaultNode := chainlink.NewCircuitBreaker(keeper.DefaultNodeName, clNode, config.ChainlinkCircuitThreshold, config.ChainlinkCircuitOpenTimeout)
+	nodeRouter := keeper.NewNodeRouter(keeperStore, defaultNode, newNodeClient(config))
+	// job run triggers go through the reconciler to detect jobs deleted on the node
+	jobReconciler := keeper.NewJobReconciler(keeperStore, nodeRouter, config.OrphanedJobReconcileInterval, config.OrphanedJobThreshold, config.DeleteOrphanedJobs)
+	upkeepExecuter := keeper.NewUpkeepExecuter(keeperStore, ethClient, config.ShutdownTimeout)
+	// the executer queues job run triggers which are sent by the dispatcher
+	triggerBackoff := keeper.BackoffConfig{Min: config.TriggerBackoffMin, Max: config.TriggerBackoffMax}
+	triggerDispatcher := keeper.NewTriggerDispatcher(keeperStore, jobReconciler, config.TriggerDispatchInterval, triggerBackoff, func() int64 {
+		return upkeepExecuter.Status().LatestBlockNumber
+	})
 	registrySynchronizer := keeper.NewRegistrySynchronizer(keeperStore, ethClient, config.KeeperRegistrySyncInterval)
+	runPoller := keeper.NewRunPoller(keeperStore, nodeRouter, config.RunStatusPollInterval, config.RunStatusTimeout)
+	// every background component is restarted when it fails
+	supervisor := NewSupervisor(keeper.BackoffConfig{Min: config.RestartBackoffMin, Max: config.RestartBackoffMax})
 
 	return &Service{
 		keeperStore:          keeperStore,
 		clNode:               clNode,
+		nodeRouter:           nodeRouter,
+		ethClient:            ethClient,
 		config:               config,
 		upkeepExecuter:       upkeepExecuter,
 		registrySynchronizer: registrySynchronizer,
+		jobReconciler:        jobReconciler,
+		triggerDispatcher:    triggerDispatcher,
+		runPoller:            runPoller,
+		supervisor:           supervisor,
 	}
 }
 
 // Run loads subscriptions, validates and subscribes to them.
 func (srv *Service) Run() error {
-
-	err := srv.upkeepExecuter.Start()
+	tlsConfig, err := newServerTLSConfig(srv.config)
 	if err != nil {
 		return err
 	}
 
-	err = srv.registrySynchronizer.Start()
+	readinessChecks := []ReadinessCheck{
+		databaseCheck(srv.keeperStore),
+		headsCheck(srv.upkeepExecuter, srv.config.ReadyMaxHeadAge),
+		registrySyncCheck(srv.registrySynchronizer, srv.config.ReadyMaxSyncAge),
+		jobTriggerCheck(srv.triggerDispatcher),
+		circuitBreakerCheck(srv.nodeRouter),
+		supervisorCheck(srv.supervisor),
+	}
+	// signed requests are verified instead of the secret in the headers
+	var verifier *chainlink.SignatureVerifier
+	if srv.config.ChainlinkToInitiatorAuthMode == string(chainlink.HMACAuth) {
+		verifier = chainlink.NewSignatureVerifier(srv.config.AuthMaxSkew)
+	}
+	secretKey, err := keeper.ParseSecretKey(srv.config.SecretsKey)
 	if err != nil {
 		return err
 	}
-
-	go RunWebserver(srv.config.ChainlinkToInitiatorAccessKey, srv.config.ChainlinkToInitiatorSecret, srv.keeperStore, srv.config.Port)
+	if verifier != nil && secretKey == nil {
+		logger.Warn("EI_SECRETS_KEY is not set, only the ci credentials of the default node can sign requests")
+	}
+	// the components are stopped in this order, the executer is drained first
+	srv.supervisor.Add("executer", runService(srv.upkeepExecuter))
+	srv.supervisor.Add("webServer", func(ctx context.Context, ready func()) error {
+		server := NewWebServer(srv.config.ChainlinkToInitiatorAccessKey, srv.config.ChainlinkToInitiatorSecret, verifier, secretKey, srv.keeperStore, srv.ethClient, srv.upkeepExecuter, srv.registrySynchronizer, srv.config.AllowManualPerform, readinessChecks, srv.supervisor, tlsConfig, srv.config.Port)
+		return RunWebserver(ctx, server, srv.config.ShutdownTimeout, ready)
+	})
+	srv.supervisor.Add("registrySynchronizer", runService(srv.registrySynchronizer))
+	srv.supervisor.Add("jobReconciler", runService(srv.jobReconciler))
+	srv.supervisor.Add("triggerDispatcher", runService(srv.triggerDispatcher))
+	srv.supervisor.Add("runPoller", runService(srv.runPoller))
+	srv.supervisor.Start()
 
 	return nil
 }
 
-// Close shuts down any open subscriptions and closes
-// the database client.
+// Close stops the supervised components, so the executer stops accepting heads and
+// drains the upkeeps being executed, and the web server shuts down once the requests
+// being served are done. Then the other services are stopped. The executions and the
+// requests are each waited for up to the shutdown timeout. The database client is
+// left open, it is closed by the caller which opened it once the Service is closed.
+// Close can be called more than once.
 func (srv *Service) Close() {
-	srv.upkeepExecuter.Stop()
-	srv.registrySynchronizer.Stop()
+	srv.supervisor.Stop()
 
-	err := srv.keeperStore.Close()
-	if err != nil {
-		logger.Error(err)
+	logger.Info("All services stopped. Bye!")
+}
+
+// newNodeClient returns the factory of the clients of the nodes saved in the
+// database, they share the retry, TLS and circuit breaker config of the default node
+func newNodeClient(config Config) keeper.NodeClientFactory {
+	return func(node keeper.Node) (chainlink.CircuitBreaker, error) {
+		nodeURL, err := url.Parse(normalizeLocalhost(node.URL))
+		if err != nil {
+			return nil, err
+		}
+		jobEndpoint, err := chainlink.ParseJobEndpoint(config.ChainlinkJobEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		authMode, err := chainlink.ParseAuthMode(config.InitiatorToChainlinkAuthMode)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig, err := newChainlinkTLSConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		client := chainlink.NewClient(
+			node.InitiatorToChainlinkAccessKey,
+			node.InitiatorToChainlinkSecret,
+			*nodeURL,
+			jobEndpoint,
+			authMode,
+			chainlink.APIToken{Key: node.APIKey, Secret: node.APISecret},
+			tlsConfig,
+			chainlinkRetryConfig(config),
+		)
+		return chainlink.NewCircuitBreaker(node.Name, client, config.ChainlinkCircuitThreshold, config.ChainlinkCircuitOpenTimeout), nil
 	}
+}
 
-	logger.Info("All connections closed. Bye!")
+// chainlinkRetryConfig makes a single attempt per request, failed job run
+// triggers are retried by the TriggerDispatcher with its own backoff
+func chainlinkRetryConfig(config Config) chainlink.RetryConfig {
+	return chainlink.RetryConfig{
+		Timeout:  config.ChainlinkTimeout,
+		Attempts: 1,
+	}
 }
 
 func normalizeLocalhost(endpoint string) string {
@@ -0,0 +1,209 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/external-initiator/keeper"
+)
+
+// ComponentState is the state of a component run by the Supervisor
+type ComponentState string
+
+const (
+	ComponentStarting ComponentState = "starting"
+	ComponentRunning  ComponentState = "running"
+	// ComponentDegraded components failed and are waiting to be restarted
+	ComponentDegraded ComponentState = "degraded"
+	ComponentStopped  ComponentState = "stopped"
+)
+
+// ComponentHealth is the state of a component reported by the /health endpoint,
+// along with the error it last failed with while degraded
+type ComponentHealth struct {
+	State    ComponentState `json:"state"`
+	Error    string         `json:"error,omitempty"`
+	Restarts uint32         `json:"restarts"`
+	Since    time.Time      `json:"since"`
+}
+
+// runFunc runs a component until the context is canceled, calling ready once it
+// started. An error, or returning before the context is canceled, fails the component.
+type runFunc func(ctx context.Context, ready func()) error
+
+type component struct {
+	name   string
+	run    runFunc
+	cancel context.CancelFunc
+	chDone chan struct{}
+	health ComponentHealth
+}
+
+// Supervisor runs the components of the Service and restarts the ones which fail,
+// with exponential backoff between the restarts of a component.
+type Supervisor struct {
+	backoff    keeper.BackoffConfig
+	mu         sync.RWMutex
+	components []*component
+}
+
+// NewSupervisor returns a Supervisor which waits for the backoff before restarting
+// a failed component
+func NewSupervisor(backoff keeper.BackoffConfig) *Supervisor {
+	return &Supervisor{backoff: backoff}
+}
+
+// Add registers a component to run once the Supervisor is started, components are
+// stopped in the order they were added
+func (s *Supervisor) Add(name string, run runFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.components = append(s.components, &component{
+		name:   name,
+		run:    run,
+		health: ComponentHealth{State: ComponentStopped, Since: time.Now()},
+	})
+}
+
+// Start runs every component in the background, components which are already running are left running
+func (s *Supervisor) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.components {
+		if c.cancel != nil {
+			continue
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		c.cancel = cancel
+		c.chDone = make(chan struct{})
+		go s.supervise(ctx, c)
+	}
+}
+
+// Stop stops the components one after another, in the order they were added.
+// Stop can be called more than once, and the Supervisor started again once stopped.
+func (s *Supervisor) Stop() {
+	s.mu.RLock()
+	var running []*component
+	var cancels []context.CancelFunc
+	var chDones []chan struct{}
+	for _, c := range s.components {
+		if c.cancel != nil {
+			running = append(running, c)
+			cancels = append(cancels, c.cancel)
+			chDones = append(chDones, c.chDone)
+		}
+	}
+	s.mu.RUnlock()
+	for i, cancel := range cancels {
+		cancel()
+		<-chDones[i]
+		s.mu.Lock()
+		// unless the component was started again meanwhile
+		if running[i].chDone == chDones[i] {
+			running[i].cancel = nil
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Status returns the health of every component by name
+func (s *Supervisor) Status() map[string]ComponentHealth {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	status := make(map[string]ComponentHealth, len(s.components))
+	for _, c := range s.components {
+		status[c.name] = c.health
+	}
+	return status
+}
+
+func (s *Supervisor) supervise(ctx context.Context, c *component) {
+	defer close(c.chDone)
+
+	var failures uint32
+	for {
+		s.setState(c, ComponentStarting, nil)
+		err := runComponent(ctx, c.run, func() { s.setState(c, ComponentRunning, nil) })
+		if ctx.Err() != nil {
+			s.setState(c, ComponentStopped, nil)
+			return
+		}
+		// the backoff starts over once the component ran successfully
+		if s.state(c) == ComponentRunning {
+			failures = 0
+		}
+		failures++
+		if err == nil {
+			err = errors.New("exited unexpectedly")
+		}
+		delay := s.backoff.Delay(failures)
+		logger.Errorw("Component failed, restarting", "component", c.name, "err", err, "delay", delay)
+		s.setState(c, ComponentDegraded, err)
+
+		select {
+		case <-ctx.Done():
+			s.setState(c, ComponentStopped, nil)
+			return
+		case <-time.After(delay):
+		}
+		s.mu.Lock()
+		c.health.Restarts++
+		s.mu.Unlock()
+	}
+}
+
+// runComponent runs the component, recovering from its panics as failures
+func runComponent(ctx context.Context, run runFunc, ready func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return run(ctx, ready)
+}
+
+func (s *Supervisor) state(c *component) ComponentState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return c.health.State
+}
+
+func (s *Supervisor) setState(c *component, state ComponentState, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c.health.State = state
+	c.health.Since = time.Now()
+	c.health.Error = ""
+	if err != nil {
+		c.health.Error = err.Error()
+	}
+}
+
+// runService runs a background service of the keeper package, the supervisor
+// retries starting it until it succeeds, and stops and restarts it once it
+// reports a failure
+func runService(service interface {
+	Start() error
+	Stop()
+	Err() <-chan error
+}) runFunc {
+	return func(ctx context.Context, ready func()) error {
+		if err := service.Start(); err != nil {
+			return err
+		}
+		ready()
+		select {
+		case <-ctx.Done():
+			service.Stop()
+			return nil
+		case err := <-service.Err():
+			service.Stop()
+			return err
+		}
+	}
+}
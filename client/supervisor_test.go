@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/external-initiator/keeper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+func waitForState(t *testing.T, supervisor *Supervisor, name string, state ComponentState) ComponentHealth {
+	var health ComponentHealth
+	require.Eventually(t, func() bool {
+		health = supervisor.Status()[name]
+		return health.State == state
+	}, 3*time.Second, 10*time.Millisecond, "%s never became %s", name, state)
+	return health
+}
+
+func TestSupervisor_RestartsFailedComponents(t *testing.T) {
+	supervisor := NewSupervisor(keeper.BackoffConfig{Min: 50 * time.Millisecond, Max: 50 * time.Millisecond})
+
+	attempts := atomic.NewInt32(0)
+	chFail := make(chan struct{})
+	supervisor.Add("flaky", func(ctx context.Context, ready func()) error {
+		attempt := attempts.Inc()
+		if attempt == 1 {
+			return errors.New("connection refused")
+		}
+		ready()
+		select {
+		case <-chFail:
+			if attempt == 2 {
+				panic("lost connection")
+			}
+			<-ctx.Done()
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	})
+	supervisor.Start()
+	defer supervisor.Stop()
+
+	health := waitForState(t, supervisor, "flaky", ComponentRunning)
+	assert.Equal(t, uint32(1), health.Restarts)
+	assert.Empty(t, health.Error)
+
+	close(chFail)
+	require.Eventually(t, func() bool { return attempts.Load() == 3 }, 3*time.Second, 10*time.Millisecond)
+	health = waitForState(t, supervisor, "flaky", ComponentRunning)
+	assert.Equal(t, uint32(2), health.Restarts)
+
+	supervisor.Stop()
+	health = supervisor.Status()["flaky"]
+	assert.Equal(t, ComponentStopped, health.State)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestSupervisor_ReportsDegradedComponents(t *testing.T) {
+	supervisor := NewSupervisor(keeper.BackoffConfig{Min: time.Hour, Max: time.Hour})
+	supervisor.Add("broken", func(ctx context.Context, ready func()) error {
+		return errors.New("connection refused")
+	})
+	supervisor.Add("healthy", func(ctx context.Context, ready func()) error {
+		ready()
+		<-ctx.Done()
+		return nil
+	})
+	assert.Equal(t, ComponentStopped, supervisor.Status()["broken"].State)
+
+	supervisor.Start()
+	health := waitForState(t, supervisor, "broken", ComponentDegraded)
+	assert.Equal(t, "connection refused", health.Error)
+	waitForState(t, supervisor, "healthy", ComponentRunning)
+
+	err := supervisorCheck(supervisor).Check()
+	require.Error(t, err)
+	assert.Equal(t, "broken is degraded", err.Error())
+
+	srv := &HttpService{Supervisor: supervisor}
+	srv.createRouter()
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var respJSON struct {
+		Status     string                     `json:"status"`
+		Components map[string]ComponentHealth `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &respJSON))
+	assert.Equal(t, "ok", respJSON.Status)
+	assert.Equal(t, ComponentDegraded, respJSON.Components["broken"].State)
+	assert.Equal(t, ComponentRunning, respJSON.Components["healthy"].State)
+
+	// the backoff is interrupted
+	supervisor.Stop()
+	supervisor.Stop()
+	for _, health := range supervisor.Status() {
+		assert.Equal(t, ComponentStopped, health.State)
+	}
+}
+
+func TestSupervisor_StopsComponentsInOrder(t *testing.T) {
+	supervisor := NewSupervisor(keeper.BackoffConfig{})
+	stopped := make(chan string, 2)
+	for _, name := range []string{"first", "second"} {
+		name := name
+		supervisor.Add(name, func(ctx context.Context, ready func()) error {
+			ready()
+			<-ctx.Done()
+			stopped <- name
+			return nil
+		})
+	}
+	supervisor.Start()
+	waitForState(t, supervisor, "first", ComponentRunning)
+	waitForState(t, supervisor, "second", ComponentRunning)
+
+	supervisor.Stop()
+	assert.Equal(t, "first", <-stopped)
+	assert.Equal(t, "second", <-stopped)
+	assert.NoError(t, supervisorCheck(NewSupervisor(keeper.BackoffConfig{})).Check())
+}
+
+type fakeService struct {
+	starts *atomic.Int32
+	stops  *atomic.Int32
+	chErr  chan error
+}
+
+func (s fakeService) Start() error {
+	s.starts.Inc()
+	return nil
+}
+
+func (s fakeService) Stop() {
+	s.stops.Inc()
+}
+
+func (s fakeService) Err() <-chan error {
+	return s.chErr
+}
+
+func TestSupervisor_RestartsFailedServices(t *testing.T) {
+	supervisor := NewSupervisor(keeper.BackoffConfig{Min: 50 * time.Millisecond, Max: 50 * time.Millisecond})
+	service := fakeService{starts: atomic.NewInt32(0), stops: atomic.NewInt32(0), chErr: make(chan error, 1)}
+	supervisor.Add("service", runService(service))
+	supervisor.Start()
+	defer supervisor.Stop()
+
+	waitForState(t, supervisor, "service", ComponentRunning)
+	service.chErr <- errors.New("head subscription failed")
+	require.Eventually(t, func() bool { return service.starts.Load() == 2 }, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(1), service.stops.Load(), "the failed service is stopped before being started again")
+	health := waitForState(t, supervisor, "service", ComponentRunning)
+	assert.Equal(t, uint32(1), health.Restarts)
+
+	supervisor.Stop()
+	assert.Equal(t, int32(2), service.stops.Load())
+}
+
+func TestSupervisor_StartsAgainOnceStopped(t *testing.T) {
+	supervisor := NewSupervisor(keeper.BackoffConfig{Min: time.Hour, Max: time.Hour})
+	runs := atomic.NewInt32(0)
+	supervisor.Add("component", func(ctx context.Context, ready func()) error {
+		runs.Inc()
+		ready()
+		<-ctx.Done()
+		return nil
+	})
+
+	supervisor.Start()
+	waitForState(t, supervisor, "component", ComponentRunning)
+	supervisor.Stop()
+	assert.Equal(t, ComponentStopped, supervisor.Status()["component"].State)
+
+	supervisor.Start()
+	defer supervisor.Stop()
+	waitForState(t, supervisor, "component", ComponentRunning)
+	assert.Equal(t, int32(2), runs.Load())
+}
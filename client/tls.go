@@ -0,0 +1,150 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/smartcontractkit/chainlink/core/logger"
+)
+
+// newServerTLSConfig returns the TLS config of the API, or nil to serve plain HTTP
+// when no certificate is configured. Clients have to present a certificate signed
+// by the client CA when it is set.
+func newServerTLSConfig(config Config) (*tls.Config, error) {
+	if config.TLSCertFile == "" && config.TLSKeyFile == "" {
+		if config.TLSClientCAFile != "" {
+			return nil, errors.New("tls_client_ca requires tls_cert and tls_key")
+		}
+		return nil, nil
+	}
+	reloader, err := newCertificateReloader(config.TLSCertFile, config.TLSKeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load the TLS certificate")
+	}
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return reloader.Certificate()
+		},
+	}
+	if config.TLSClientCAFile != "" {
+		tlsConfig.ClientCAs, err = loadCertPool(config.TLSClientCAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to load the TLS client CA")
+		}
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// newChainlinkTLSConfig returns the TLS config of the requests to the Chainlink nodes,
+// or nil for the default config. The certificates of the nodes are verified with the
+// CA instead of the system roots when it is set, and the client certificate is
+// presented to the nodes requiring mutual TLS.
+func newChainlinkTLSConfig(config Config) (*tls.Config, error) {
+	if config.ChainlinkTLSCAFile == "" && config.ChainlinkTLSCertFile == "" && config.ChainlinkTLSKeyFile == "" {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if config.ChainlinkTLSCAFile != "" {
+		pool, err := loadCertPool(config.ChainlinkTLSCAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to load the Chainlink TLS CA")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if config.ChainlinkTLSCertFile != "" || config.ChainlinkTLSKeyFile != "" {
+		reloader, err := newCertificateReloader(config.ChainlinkTLSCertFile, config.ChainlinkTLSKeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to load the Chainlink TLS client certificate")
+		}
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return reloader.Certificate()
+		}
+	}
+	return tlsConfig, nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", file)
+	}
+	return pool, nil
+}
+
+// certificateReloader serves the certificate in certFile with its key in keyFile,
+// loading them again once either file changes on disk so that renewed
+// certificates are picked up without a restart
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mu          sync.Mutex
+	certificate *tls.Certificate
+	// modTime is the latest modification of the files when they were last loaded
+	modTime time.Time
+}
+
+// newCertificateReloader returns a certificateReloader, the files have to load initially
+func newCertificateReloader(certFile, keyFile string) (*certificateReloader, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("both the certificate and the key files are required")
+	}
+	reloader := &certificateReloader{certFile: certFile, keyFile: keyFile}
+	if err := reloader.reloadIfChanged(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// Certificate returns the certificate, reloading it if the files changed since it was
+// loaded. The previous certificate is kept while the files fail to load, e.g. halfway
+// through their replacement, until they change again.
+func (r *certificateReloader) Certificate() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.reloadIfChanged(); err != nil {
+		logger.Errorw("Unable to reload the TLS certificate, keeping the previous one", "certFile", r.certFile, "error", err)
+	}
+	return r.certificate, nil
+}
+
+func (r *certificateReloader) reloadIfChanged() error {
+	var modTime time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	if modTime.Equal(r.modTime) {
+		return nil
+	}
+	// the files aren't loaded again until their next change, even if they fail to load
+	r.modTime = modTime
+
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	if r.certificate != nil {
+		logger.Infow("Reloaded the TLS certificate", "certFile", r.certFile)
+	}
+	r.certificate = &certificate
+	return nil
+}
@@ -0,0 +1,174 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCertificate struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// writeCertificate writes a certificate for localhost to dir, signed by the parent or
+// self-signed if it is nil, along with its key
+func writeCertificate(t *testing.T, dir, name string, parent *testCertificate) testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:     []string{"localhost"},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certificate := testCertificate{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".pem"),
+		keyFile:  filepath.Join(dir, name+"-key.pem"),
+	}
+	require.NoError(t, ioutil.WriteFile(certificate.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(certificate.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certificate
+}
+
+// touch sets the modification time of the files, which may not change within the
+// resolution of the filesystem otherwise
+func touch(t *testing.T, modTime time.Time, files ...string) {
+	for _, file := range files {
+		require.NoError(t, os.Chtimes(file, modTime, modTime))
+	}
+}
+
+func TestCertificateReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	first := writeCertificate(t, dir, "server", nil)
+	reloader, err := newCertificateReloader(first.certFile, first.keyFile)
+	require.NoError(t, err)
+
+	certificate, err := reloader.Certificate()
+	require.NoError(t, err)
+	assert.Equal(t, first.cert.Raw, certificate.Certificate[0])
+
+	t.Run("reloads the changed files", func(t *testing.T) {
+		second := writeCertificate(t, dir, "server", nil)
+		touch(t, time.Now().Add(time.Minute), second.certFile, second.keyFile)
+
+		certificate, err := reloader.Certificate()
+		require.NoError(t, err)
+		assert.Equal(t, second.cert.Raw, certificate.Certificate[0])
+	})
+
+	t.Run("keeps the previous certificate while the files are invalid", func(t *testing.T) {
+		previous, err := reloader.Certificate()
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(first.certFile, []byte("invalid"), 0600))
+		touch(t, time.Now().Add(2*time.Minute), first.certFile)
+
+		certificate, err := reloader.Certificate()
+		require.NoError(t, err)
+		assert.Equal(t, previous, certificate)
+	})
+
+	t.Run("requires the files to load initially", func(t *testing.T) {
+		_, err := newCertificateReloader(first.certFile, first.keyFile)
+		assert.Error(t, err)
+		_, err = newCertificateReloader(filepath.Join(dir, "missing.pem"), first.keyFile)
+		assert.Error(t, err)
+	})
+}
+
+func TestServerTLS_MutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := writeCertificate(t, dir, "ca", nil)
+	server := writeCertificate(t, dir, "server", &ca)
+	client := writeCertificate(t, dir, "client", &ca)
+	untrusted := writeCertificate(t, dir, "untrusted", nil)
+
+	serverTLS, err := newServerTLSConfig(Config{TLSCertFile: server.certFile, TLSKeyFile: server.keyFile, TLSClientCAFile: ca.certFile})
+	require.NoError(t, err)
+	srv := &HttpService{}
+	srv.createRouter()
+	// StartTLS would serve the certificate of httptest instead of the reloaded one
+	ts := httptest.NewUnstartedServer(srv)
+	ts.Listener = tls.NewListener(ts.Listener, serverTLS)
+	ts.Start()
+	defer ts.Close()
+
+	get := func(config Config) error {
+		clientTLS, err := newChainlinkTLSConfig(config)
+		require.NoError(t, err)
+		httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+		response, err := httpClient.Get("https://" + ts.Listener.Addr().String() + "/health")
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		return nil
+	}
+
+	assert.NoError(t, get(Config{ChainlinkTLSCAFile: ca.certFile, ChainlinkTLSCertFile: client.certFile, ChainlinkTLSKeyFile: client.keyFile}))
+	assert.Error(t, get(Config{ChainlinkTLSCAFile: ca.certFile}), "the client certificate is required")
+	assert.Error(t, get(Config{ChainlinkTLSCAFile: ca.certFile, ChainlinkTLSCertFile: untrusted.certFile, ChainlinkTLSKeyFile: untrusted.keyFile}))
+	assert.Error(t, get(Config{ChainlinkTLSCertFile: client.certFile, ChainlinkTLSKeyFile: client.keyFile}), "the server certificate isn't trusted by the system roots")
+}
+
+func TestTLSConfig_Optional(t *testing.T) {
+	serverTLS, err := newServerTLSConfig(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, serverTLS)
+	clientTLS, err := newChainlinkTLSConfig(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, clientTLS)
+
+	_, err = newServerTLSConfig(Config{TLSClientCAFile: "ca.pem"})
+	assert.Error(t, err)
+	_, err = newServerTLSConfig(Config{TLSCertFile: "server.pem"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/external-initiator/keeper"
+)
+
+// jobTriggerPresenter is the API representation of a job run trigger in the outbox.
+type jobTriggerPresenter struct {
+	ID             uint32          `json:"id"`
+	JobID          string          `json:"jobId"`
+	RegistryID     uint32          `json:"registryId"`
+	From           string          `json:"from"`
+	UpkeepID       uint64          `json:"upkeepId"`
+	BlockNumber    int64           `json:"blockNumber"`
+	IdempotencyKey string          `json:"idempotencyKey"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       uint32          `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	ExpiresAtBlock int64           `json:"expiresAtBlock,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+}
+
+// ShowJobTriggers returns a page of the job run triggers queued for the requesting node's
+// jobs, the most recent first.
+// The status query param filters the triggers, e.g. status=dead lists the dead letters.
+func (srv *HttpService) ShowJobTriggers(c *gin.Context) {
+	page, size, err := parsePagination(c)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusBadRequest, nil)
+		return
+	}
+
+	triggers, count, err := srv.Store.PaginatedJobTriggers(requestNodeID(c), keeper.TriggerStatus(c.Query("status")), (page-1)*size, size)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+
+	presenters := make([]jobTriggerPresenter, len(triggers))
+	for idx, trigger := range triggers {
+		presenters[idx] = presentJobTrigger(trigger)
+	}
+	c.JSON(http.StatusOK, paginatedResponse{Data: presenters, Count: count, Page: page, Size: size})
+}
+
+// ReplayJobTrigger queues a dead job run trigger to be sent again, it no
+// longer expires. Triggers which aren't dead, or belong to the jobs of
+// other nodes, can't be replayed.
+func (srv *HttpService) ReplayJobTrigger(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusBadRequest, nil)
+		return
+	}
+
+	trigger, err := srv.Store.ReplayJobTrigger(requestNodeID(c), uint32(id))
+	if gorm.IsRecordNotFoundError(err) {
+		c.JSON(http.StatusNotFound, nil)
+		return
+	} else if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+	logger.Infow("Replaying job run trigger", "id", id, "upkeepID", trigger.UpkeepID)
+	c.JSON(http.StatusOK, presentJobTrigger(trigger))
+}
+
+func presentJobTrigger(trigger keeper.JobTrigger) jobTriggerPresenter {
+	presenter := jobTriggerPresenter{
+		ID:             trigger.ID,
+		RegistryID:     trigger.Membership.RegistryID,
+		From:           trigger.Membership.From.Hex(),
+		UpkeepID:       trigger.UpkeepID,
+		BlockNumber:    trigger.BlockNumber,
+		IdempotencyKey: trigger.IdempotencyKey,
+		Payload:        trigger.Payload,
+		Status:         string(trigger.Status),
+		Attempts:       trigger.Attempts,
+		NextAttemptAt:  trigger.NextAttemptAt,
+		ExpiresAtBlock: trigger.ExpiresAtBlock,
+		LastError:      trigger.LastError,
+		CreatedAt:      trigger.CreatedAt,
+		UpdatedAt:      trigger.UpdatedAt,
+	}
+	if trigger.Membership.JobID != nil {
+		presenter.JobID = trigger.Membership.JobID.String()
+	}
+	return presenter
+}
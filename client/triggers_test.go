@@ -0,0 +1,75 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/external-initiator/keeper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowJobTriggers(t *testing.T) {
+	db, srv, _, cleanup := setupRegistriesController(t)
+	defer cleanup()
+
+	reg, membership := createSyncedRegistry(t, db)
+	triggers := make(map[keeper.TriggerStatus]keeper.JobTrigger)
+	for _, status := range []keeper.TriggerStatus{keeper.TriggerSent, keeper.TriggerDead, keeper.TriggerPending} {
+		trigger := keeper.JobTrigger{MembershipID: membership.ID, UpkeepID: 3, BlockNumber: 40, IdempotencyKey: string(status), Payload: []byte(`{"upkeep":3}`), Status: status, NextAttemptAt: time.Now(), ExpiresAtBlock: 60}
+		if status == keeper.TriggerDead {
+			trigger.LastError = "expired at block 60"
+		}
+		require.NoError(t, db.Create(&trigger).Error)
+		triggers[status] = trigger
+	}
+
+	w := authenticatedGet(srv, "/triggers?status=dead")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data  []jobTriggerPresenter `json:"data"`
+		Count int                   `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, "dead", response.Data[0].Status)
+	assert.Equal(t, "expired at block 60", response.Data[0].LastError)
+	assert.Equal(t, membership.JobID.String(), response.Data[0].JobID)
+	assert.Equal(t, reg.ID, response.Data[0].RegistryID)
+	assert.JSONEq(t, `{"upkeep":3}`, string(response.Data[0].Payload))
+
+	w = authenticatedGet(srv, "/triggers")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.Count)
+	assert.Equal(t, "pending", response.Data[0].Status)
+}
+
+func TestReplayJobTrigger(t *testing.T) {
+	db, srv, _, cleanup := setupRegistriesController(t)
+	defer cleanup()
+
+	_, membership := createSyncedRegistry(t, db)
+	dead := keeper.JobTrigger{MembershipID: membership.ID, IdempotencyKey: "dead", Payload: []byte(`{}`), Status: keeper.TriggerDead, Attempts: 5, NextAttemptAt: time.Now(), ExpiresAtBlock: 60}
+	require.NoError(t, db.Create(&dead).Error)
+	sent := keeper.JobTrigger{MembershipID: membership.ID, IdempotencyKey: "sent", Payload: []byte(`{}`), Status: keeper.TriggerSent, NextAttemptAt: time.Now()}
+	require.NoError(t, db.Create(&sent).Error)
+
+	w := authenticatedRequest(srv, "POST", fmt.Sprintf("/triggers/%d/replay", dead.ID))
+	require.Equal(t, http.StatusOK, w.Code)
+	var presenter jobTriggerPresenter
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &presenter))
+	assert.Equal(t, "pending", presenter.Status)
+	assert.Zero(t, presenter.Attempts)
+	assert.Zero(t, presenter.ExpiresAtBlock)
+
+	w = authenticatedRequest(srv, "POST", fmt.Sprintf("/triggers/%d/replay", sent.ID))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = authenticatedRequest(srv, "POST", "/triggers/abc/replay")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
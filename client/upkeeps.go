@@ -0,0 +1,164 @@
+package client
+
+import (
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gin-gonic/gin"
+	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/external-initiator/keeper"
+)
+
+// upkeepCheckPresenter is the API representation of a checkUpkeep call.
+// The decoded result is only present if the upkeep can be performed.
+type upkeepCheckPresenter struct {
+	UpkeepID       uint64  `json:"upkeepId"`
+	RegistryID     uint32  `json:"registryId"`
+	BlockNumber    *uint64 `json:"blockNumber,omitempty"`
+	Performable    bool    `json:"performable"`
+	RevertReason   string  `json:"revertReason,omitempty"`
+	PerformData    string  `json:"performData,omitempty"`
+	MaxLinkPayment string  `json:"maxLinkPayment,omitempty"`
+	GasLimit       string  `json:"gasLimit,omitempty"`
+	GasWei         string  `json:"gasWei,omitempty"`
+	LinkEth        string  `json:"linkEth,omitempty"`
+	GasUsed        uint64  `json:"gasUsed,omitempty"`
+	Triggered      bool    `json:"triggered"`
+}
+
+// CheckUpkeep calls checkUpkeep for an upkeep without triggering a job run.
+// The block can be set with the block query param, it defaults to the latest block.
+func (srv *HttpService) CheckUpkeep(c *gin.Context) {
+	reg, ok := srv.findRegistry(c)
+	if !ok {
+		return
+	}
+	upkeep, ok := srv.findUpkeep(c, reg)
+	if !ok {
+		return
+	}
+	upkeep.Registry = reg
+	membership, ok := srv.findMembership(c, reg)
+	if !ok {
+		return
+	}
+
+	var blockNumber *big.Int
+	if param := c.Query("block"); param != "" {
+		number, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			logger.Error(err)
+			c.JSON(http.StatusBadRequest, nil)
+			return
+		}
+		blockNumber = new(big.Int).SetUint64(number)
+	} else if latest := srv.latestBlockNumber(); latest != nil {
+		blockNumber = new(big.Int).SetUint64(*latest)
+	}
+
+	check, err := srv.Executer.CheckUpkeep(upkeep, membership, blockNumber)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+	c.JSON(http.StatusOK, presentUpkeepCheck(upkeep, check, false))
+}
+
+// PerformUpkeep checks an upkeep at the latest block and queues a job run trigger
+// to perform it, even if it isn't this keeper's turn. It is disabled unless
+// the service was started with manual performs allowed.
+func (srv *HttpService) PerformUpkeep(c *gin.Context) {
+	if !srv.AllowManualPerform {
+		c.JSON(http.StatusForbidden, nil)
+		return
+	}
+
+	reg, ok := srv.findRegistry(c)
+	if !ok {
+		return
+	}
+	upkeep, ok := srv.findUpkeep(c, reg)
+	if !ok {
+		return
+	}
+	upkeep.Registry = reg
+	membership, ok := srv.findMembership(c, reg)
+	if !ok {
+		return
+	}
+
+	check, err := srv.Executer.PerformUpkeep(upkeep, membership)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusBadGateway, nil)
+		return
+	}
+	if !check.Performable {
+		c.JSON(http.StatusConflict, presentUpkeepCheck(upkeep, check, false))
+		return
+	}
+	c.JSON(http.StatusOK, presentUpkeepCheck(upkeep, check, true))
+}
+
+// findMembership picks the keeper of the requesting node to check or perform with, set
+// by the from query param or defaulting to the node's first membership on the registry.
+// It writes the error response and returns false if there's no such membership.
+func (srv *HttpService) findMembership(c *gin.Context, reg keeper.Registry) (keeper.Membership, bool) {
+	var from *common.Address
+	if param := c.Query("from"); param != "" {
+		if !common.IsHexAddress(param) {
+			c.JSON(http.StatusBadRequest, nil)
+			return keeper.Membership{}, false
+		}
+		address := common.HexToAddress(param)
+		from = &address
+	}
+
+	memberships, ok := srv.findNodeMemberships(c, reg)
+	if !ok {
+		return keeper.Membership{}, false
+	}
+	for _, membership := range memberships {
+		if from == nil || membership.From == *from {
+			membership.Registry = reg
+			return membership, true
+		}
+	}
+	c.JSON(http.StatusNotFound, nil)
+	return keeper.Membership{}, false
+}
+
+func presentUpkeepCheck(upkeep keeper.Registration, check keeper.UpkeepCheck, triggered bool) upkeepCheckPresenter {
+	presenter := upkeepCheckPresenter{
+		UpkeepID:     upkeep.UpkeepID,
+		RegistryID:   upkeep.RegistryID,
+		Performable:  check.Performable,
+		RevertReason: check.RevertReason,
+		GasUsed:      check.GasUsed,
+		Triggered:    triggered,
+	}
+	if check.BlockNumber != nil {
+		blockNumber := check.BlockNumber.Uint64()
+		presenter.BlockNumber = &blockNumber
+	}
+	if !check.Performable {
+		return presenter
+	}
+	presenter.PerformData = hexutil.Encode(check.PerformData)
+	presenter.MaxLinkPayment = bigToString(check.MaxLinkPayment)
+	presenter.GasLimit = bigToString(check.GasLimit)
+	presenter.GasWei = bigToString(check.GasWei)
+	presenter.LinkEth = bigToString(check.LinkEth)
+	return presenter
+}
+
+func bigToString(n *big.Int) string {
+	if n == nil {
+		return ""
+	}
+	return n.String()
+}
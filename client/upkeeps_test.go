@@ -0,0 +1,144 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/external-initiator/eitest"
+	"github.com/smartcontractkit/external-initiator/keeper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var checkUpkeepResponse = struct {
+	PerformData    []byte
+	MaxLinkPayment *big.Int
+	GasLimit       *big.Int
+	GasWei         *big.Int
+	LinkEth        *big.Int
+}{
+	PerformData:    []byte{0x12, 0x34},
+	MaxLinkPayment: big.NewInt(1),
+	GasLimit:       big.NewInt(2_000_000),
+	GasWei:         big.NewInt(3),
+	LinkEth:        big.NewInt(4),
+}
+
+func TestCheckUpkeep(t *testing.T) {
+	db, srv, ethMock, cleanup := setupRegistriesController(t)
+	defer cleanup()
+	srv.Executer = keeper.NewUpkeepExecuter(srv.Store, ethMock, time.Second)
+
+	reg, membership := createSyncedRegistry(t, db)
+	upkeep := keeper.Registration{RegistryID: reg.ID, UpkeepID: 0, ExecuteGas: 10_000}
+	require.NoError(t, db.Create(&upkeep).Error)
+
+	t.Run("returns the decoded result at the given block", func(t *testing.T) {
+		registryMock := eitest.NewContractMockReceiver(t, ethMock, keeper.UpkeepRegistryABI, reg.Address)
+		registryMock.MockResponse("checkUpkeep", checkUpkeepResponse).Once()
+		ethMock.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(54_321), nil).Once()
+
+		w := authenticatedRequest(srv, "POST", fmt.Sprintf("/registries/%d/upkeeps/0/check?block=42", reg.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var presenter upkeepCheckPresenter
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &presenter))
+		assert.True(t, presenter.Performable)
+		require.NotNil(t, presenter.BlockNumber)
+		assert.Equal(t, uint64(42), *presenter.BlockNumber)
+		assert.Equal(t, "0x1234", presenter.PerformData)
+		assert.Equal(t, "2000000", presenter.GasLimit)
+		assert.Equal(t, uint64(54_321), presenter.GasUsed)
+		assert.False(t, presenter.Triggered)
+	})
+
+	t.Run("reports reverts as not performable", func(t *testing.T) {
+		ethMock.
+			On("CallContract", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, errors.New("upkeep not needed")).
+			Once()
+
+		w := authenticatedRequest(srv, "POST", fmt.Sprintf("/registries/%d/upkeeps/0/check?block=42", reg.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var presenter upkeepCheckPresenter
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &presenter))
+		assert.False(t, presenter.Performable)
+		assert.Equal(t, "upkeep not needed", presenter.RevertReason)
+	})
+
+	t.Run("checks as the keeper in the from param", func(t *testing.T) {
+		registryMock := eitest.NewContractMockReceiver(t, ethMock, keeper.UpkeepRegistryABI, reg.Address)
+		registryMock.MockResponse("checkUpkeep", checkUpkeepResponse).Once()
+		ethMock.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(54_321), nil).Once()
+
+		w := authenticatedRequest(srv, "POST", fmt.Sprintf("/registries/%d/upkeeps/0/check?block=42&from=%s", reg.ID, membership.From.Hex()))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = authenticatedRequest(srv, "POST", fmt.Sprintf("/registries/%d/upkeeps/0/check?from=%s", reg.ID, eitest.NewAddress().Hex()))
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("rejects invalid blocks", func(t *testing.T) {
+		w := authenticatedRequest(srv, "POST", fmt.Sprintf("/registries/%d/upkeeps/0/check?block=latest", reg.ID))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	eitest.AssertCount(t, db, keeper.JobTrigger{}, 0)
+	ethMock.AssertExpectations(t)
+}
+
+func TestPerformUpkeep(t *testing.T) {
+	db, srv, ethMock, cleanup := setupRegistriesController(t)
+	defer cleanup()
+	srv.Executer = keeper.NewUpkeepExecuter(srv.Store, ethMock, time.Second)
+
+	reg, membership := createSyncedRegistry(t, db)
+	upkeep := keeper.Registration{RegistryID: reg.ID, UpkeepID: 0, ExecuteGas: 10_000}
+	require.NoError(t, db.Create(&upkeep).Error)
+	path := fmt.Sprintf("/registries/%d/upkeeps/0/perform", reg.ID)
+
+	t.Run("is forbidden unless allowed", func(t *testing.T) {
+		w := authenticatedRequest(srv, "POST", path)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	srv.AllowManualPerform = true
+
+	t.Run("queues the job run trigger", func(t *testing.T) {
+		registryMock := eitest.NewContractMockReceiver(t, ethMock, keeper.UpkeepRegistryABI, reg.Address)
+		registryMock.MockResponse("checkUpkeep", checkUpkeepResponse).Once()
+		ethMock.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(54_321), nil).Once()
+
+		w := authenticatedRequest(srv, "POST", path)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var presenter upkeepCheckPresenter
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &presenter))
+		assert.True(t, presenter.Triggered)
+
+		var trigger keeper.JobTrigger
+		require.NoError(t, db.First(&trigger).Error)
+		assert.Equal(t, membership.ID, trigger.MembershipID)
+		assert.Equal(t, keeper.TriggerPending, trigger.Status)
+	})
+
+	t.Run("doesn't trigger the job if checkUpkeep reverts", func(t *testing.T) {
+		ethMock.
+			On("CallContract", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, errors.New("upkeep not needed")).
+			Once()
+
+		w := authenticatedRequest(srv, "POST", path)
+		require.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	eitest.AssertCount(t, db, keeper.JobTrigger{}, 1)
+	ethMock.AssertExpectations(t)
+}
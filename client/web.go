@@ -2,19 +2,27 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/chainlink/core/services/eth"
 	"github.com/smartcontractkit/chainlink/core/store/models"
 	"github.com/smartcontractkit/external-initiator/blockchain"
+	"github.com/smartcontractkit/external-initiator/chainlink"
 	"github.com/smartcontractkit/external-initiator/keeper"
 )
 
@@ -23,40 +31,119 @@ const (
 	ExternalInitiatorSecretHeader    = "X-Chainlink-EA-Secret"
 )
 
-// RunWebserver starts a new web server using the access key
-// and secret as provided on protected routes.
-func RunWebserver(
+// nodeContextKey is the gin context key of the ID of the node which authenticated the request
+const nodeContextKey = "node"
+
+// NewWebServer returns a new web server using the access key and secret of the
+// default node, or the credentials of the nodes in the store, on protected routes.
+// Requests are expected to be signed with the secrets when the verifier is set,
+// the secrets of the credentials in the store are decrypted with the secretKey.
+// The server is served over HTTPS with the tlsConfig, unless it is nil.
+func NewWebServer(
 	accessKey, secret string,
+	verifier *chainlink.SignatureVerifier,
+	secretKey *keeper.SecretKey,
 	regStore keeper.Store,
+	ethClient eth.Client,
+	executer keeper.UpkeepExecuter,
+	synchronizer keeper.RegistrySynchronizer,
+	allowManualPerform bool,
+	readinessChecks []ReadinessCheck,
+	supervisor *Supervisor,
+	tlsConfig *tls.Config,
 	port int,
-) {
-	srv := NewHTTPService(accessKey, secret, regStore)
-	addr := fmt.Sprintf(":%v", port)
-	err := srv.Router.Run(addr)
+) *http.Server {
+	srv := NewHTTPService(accessKey, secret, verifier, secretKey, regStore, ethClient, executer, synchronizer, allowManualPerform, readinessChecks)
+	srv.Supervisor = supervisor
+	return &http.Server{
+		Addr:      fmt.Sprintf(":%v", port),
+		Handler:   srv.Router,
+		TLSConfig: tlsConfig,
+	}
+}
+
+// RunWebserver serves the web server until the context is canceled, then shuts it
+// down once the requests being served are done, waiting for up to the shutdownTimeout.
+// ready is called once the server is listening.
+func RunWebserver(ctx context.Context, server *http.Server, shutdownTimeout time.Duration, ready func()) error {
+	listener, err := net.Listen("tcp", server.Addr)
 	if err != nil {
-		logger.Error(err)
+		return err
+	}
+	ready()
+
+	chServed := make(chan error, 1)
+	go func() {
+		if server.TLSConfig != nil {
+			// the certificate is served by the TLS config
+			chServed <- server.ServeTLS(listener, "", "")
+		} else {
+			chServed <- server.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-chServed:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Errorf("unable to shut down the web server: %v", err)
 	}
+	return nil
 }
 
 // HttpService encapsulates router, EI service
-// and access credentials.
+// and the access credentials of the default node.
 type HttpService struct {
 	Router    *gin.Engine
 	AccessKey string
 	Secret    string
+	// Verifier verifies the HMAC signatures of requests instead of
+	// expecting the secret in their headers, unless it is nil
+	Verifier *chainlink.SignatureVerifier
+	// SecretKey decrypts the signing secrets of the credentials, those can't
+	// authenticate signed requests without it
+	SecretKey *keeper.SecretKey
 	Store     keeper.Store
+	EthClient eth.Client
+	Executer  keeper.UpkeepExecuter
+	// Synchronizer is optional, new registries are synced on its next interval without it
+	Synchronizer keeper.RegistrySynchronizer
+
+	// AllowManualPerform enables triggering upkeeps outside of the turn taking
+	AllowManualPerform bool
+	ReadinessChecks    []ReadinessCheck
+	// Supervisor is optional, the state of its components is reported by /health
+	Supervisor *Supervisor
 }
 
 // NewHTTPService creates a new HttpService instance
 // with the default router.
 func NewHTTPService(
 	accessKey, secret string,
+	verifier *chainlink.SignatureVerifier,
+	secretKey *keeper.SecretKey,
 	regStore keeper.Store,
+	ethClient eth.Client,
+	executer keeper.UpkeepExecuter,
+	synchronizer keeper.RegistrySynchronizer,
+	allowManualPerform bool,
+	readinessChecks []ReadinessCheck,
 ) *HttpService {
 	srv := HttpService{
-		AccessKey: accessKey,
-		Secret:    secret,
-		Store:     regStore,
+		AccessKey:          accessKey,
+		Secret:             secret,
+		Verifier:           verifier,
+		SecretKey:          secretKey,
+		Store:              regStore,
+		EthClient:          ethClient,
+		Executer:           executer,
+		Synchronizer:       synchronizer,
+		AllowManualPerform: allowManualPerform,
+		ReadinessChecks:    readinessChecks,
 	}
 	srv.createRouter()
 	return &srv
@@ -73,29 +160,112 @@ func (srv *HttpService) createRouter() {
 	r.Use(gin.Recovery())
 	r.Use(loggerFunc())
 	r.GET("/health", srv.ShowHealth)
+	r.GET("/ready", srv.ShowReadiness)
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	auth := r.Group("/")
-	auth.Use(authenticate(srv.AccessKey, srv.Secret))
+	auth.Use(authenticate(srv.AccessKey, srv.Secret, srv.Store, srv.Verifier, srv.SecretKey))
 	{
+		auth.GET("/jobs", srv.ShowSubscriptions)
 		auth.POST("/jobs", srv.CreateSubscription)
+		auth.PATCH("/jobs/:jobid", srv.UpdateSubscription)
 		auth.DELETE("/jobs/:jobid", srv.DeleteSubscription)
+		auth.GET("/runs", srv.ShowJobRuns)
+		auth.GET("/triggers", srv.ShowJobTriggers)
+		auth.POST("/triggers/:id/replay", srv.ReplayJobTrigger)
+		auth.GET("/registries", srv.ShowRegistries)
+		auth.GET("/registries/:id", srv.ShowRegistry)
+		auth.GET("/registries/:id/upkeeps", srv.ShowUpkeeps)
+		auth.GET("/registries/:id/upkeeps/:upkeepId", srv.ShowUpkeep)
+		auth.POST("/registries/:id/pause", srv.PauseRegistry)
+		auth.POST("/registries/:id/resume", srv.ResumeRegistry)
+		auth.GET("/registries/:id/filters", srv.ShowUpkeepFilters)
+		auth.POST("/registries/:id/filters", srv.CreateUpkeepFilter)
+		auth.DELETE("/registries/:id/filters/:filterId", srv.DeleteUpkeepFilter)
+		auth.POST("/registries/:id/upkeeps/:upkeepId/pause", srv.PauseUpkeep)
+		auth.POST("/registries/:id/upkeeps/:upkeepId/resume", srv.ResumeUpkeep)
+		auth.POST("/registries/:id/upkeeps/:upkeepId/check", srv.CheckUpkeep)
+		auth.POST("/registries/:id/upkeeps/:upkeepId/perform", srv.PerformUpkeep)
 	}
 
 	srv.Router = r
 }
 
-func authenticate(accessKey, secret string) gin.HandlerFunc {
+// authenticate accepts the credentials of the default node from the config, or active
+// credentials from the store. Secrets are compared in constant time. The node of the
+// credential is set in the context so that handlers can scope the jobs to it. With a
+// verifier, requests have to be signed with the secret instead of carrying it, which
+// only the credentials keeping their signing secret, decrypted with the secretKey, can do.
+func authenticate(accessKey, secret string, credentials keeper.Store, verifier *chainlink.SignatureVerifier, secretKey *keeper.SecretKey) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		reqAccessKey := c.GetHeader(ExternalInitiatorAccessKeyHeader)
-		reqSecret := c.GetHeader(ExternalInitiatorSecretHeader)
-		if reqAccessKey == accessKey && reqSecret == secret {
+		if reqAccessKey == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		now := time.Now()
+		if accessKey != "" && secret != "" &&
+			subtle.ConstantTimeCompare([]byte(reqAccessKey), []byte(accessKey)) == 1 &&
+			verifySecret(c, verifier, reqAccessKey, secret, now) {
 			c.Next()
-		} else {
+			return
+		}
+
+		credential, err := credentials.CredentialByAccessKey(reqAccessKey)
+		if gorm.IsRecordNotFoundError(err) || (err == nil && !authenticateCredential(c, verifier, secretKey, credential, now)) {
 			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		} else if err != nil {
+			logger.Error(err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if credential.NodeID != nil {
+			c.Set(nodeContextKey, *credential.NodeID)
 		}
+		c.Next()
 	}
 }
 
+func authenticateCredential(c *gin.Context, verifier *chainlink.SignatureVerifier, secretKey *keeper.SecretKey, credential keeper.Credential, now time.Time) bool {
+	if verifier == nil {
+		return credential.Authenticate(c.GetHeader(ExternalInitiatorSecretHeader), now)
+	}
+	if credential.Status(now) != keeper.CredentialActive {
+		return false
+	}
+	signingSecret, ok := credential.SigningSecret(secretKey)
+	return ok && verifySecret(c, verifier, credential.AccessKey, signingSecret, now)
+}
+
+// verifySecret returns whether the request carries the secret, or with
+// a verifier whether the request and its body are signed with it
+func verifySecret(c *gin.Context, verifier *chainlink.SignatureVerifier, accessKey, secret string, now time.Time) bool {
+	if verifier == nil {
+		return subtle.ConstantTimeCompare([]byte(c.GetHeader(ExternalInitiatorSecretHeader)), []byte(secret)) == 1
+	}
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		return false
+	}
+	c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+	if err = verifier.Verify(accessKey, secret, c.Request, body, now); err != nil {
+		logger.Warnw("Rejected signed request", "accessKey", accessKey, "error", err)
+		return false
+	}
+	return true
+}
+
+// requestNodeID returns the ID of the node which authenticated the request, nil for the default node
+func requestNodeID(c *gin.Context) *uint32 {
+	nodeID, ok := c.Get(nodeContextKey)
+	if !ok {
+		return nil
+	}
+	id := nodeID.(uint32)
+	return &id
+}
+
 // CreateSubscriptionReq holds the payload expected for job POSTs
 // from the Chainlink node.
 type CreateSubscriptionReq struct {
@@ -107,6 +277,75 @@ type resp struct {
 	ID string `json:"id"`
 }
 
+// jobPresenter is the API representation of a keeper job registered by the Chainlink node.
+type jobPresenter struct {
+	JobID       string              `json:"jobId"`
+	ReferenceID string              `json:"referenceId"`
+	RegistryID  uint32              `json:"registryId"`
+	Address     string              `json:"address"`
+	From        string              `json:"from"`
+	Orphaned    bool                `json:"orphaned"`
+	Options     jobOptionsPresenter `json:"options"`
+}
+
+// jobOptionsPresenter is the API representation of the options of a keeper job,
+// with the defaults filled in.
+type jobOptionsPresenter struct {
+	GasBuffer          uint32          `json:"gasBuffer"`
+	MaxGasPrice        string          `json:"maxGasPrice,omitempty"`
+	AllowedUpkeeps     []uint64        `json:"allowedUpkeeps,omitempty"`
+	DeniedUpkeeps      []uint64        `json:"deniedUpkeeps,omitempty"`
+	DryRun             bool            `json:"dryRun"`
+	CheckConfirmations uint32          `json:"checkConfirmations"`
+	PayloadTemplate    json.RawMessage `json:"payloadTemplate,omitempty"`
+	JobEndpoint        string          `json:"jobEndpoint,omitempty"`
+}
+
+// ShowSubscriptions lists the jobs registered by the requesting node,
+// including the ones found to no longer exist on it.
+func (srv *HttpService) ShowSubscriptions(c *gin.Context) {
+	page, size, err := parsePagination(c)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusBadRequest, nil)
+		return
+	}
+
+	memberships, count, err := srv.Store.PaginatedMemberships(requestNodeID(c), (page-1)*size, size)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+
+	presenters := make([]jobPresenter, len(memberships))
+	for idx, membership := range memberships {
+		presenters[idx] = jobPresenter{
+			ReferenceID: membership.ReferenceID,
+			RegistryID:  membership.RegistryID,
+			Address:     membership.Registry.Address.Hex(),
+			From:        membership.From.Hex(),
+			Orphaned:    membership.Orphaned,
+			Options: jobOptionsPresenter{
+				GasBuffer:          membership.PerformGasLimit(0),
+				AllowedUpkeeps:     membership.AllowedUpkeeps,
+				DeniedUpkeeps:      membership.DeniedUpkeeps,
+				DryRun:             membership.DryRun,
+				CheckConfirmations: membership.CheckConfirmations,
+				PayloadTemplate:    json.RawMessage(membership.PayloadTemplate),
+				JobEndpoint:        string(membership.JobEndpoint),
+			},
+		}
+		if membership.JobID != nil {
+			presenters[idx].JobID = membership.JobID.String()
+		}
+		if membership.MaxGasPrice != nil {
+			presenters[idx].Options.MaxGasPrice = membership.MaxGasPrice.String()
+		}
+	}
+	c.JSON(http.StatusOK, paginatedResponse{Data: presenters, Count: count, Page: page, Size: size})
+}
+
 // CreateSubscription expects a CreateSubscriptionReq payload,
 // validates the request and subscribes to the job.
 func (srv *HttpService) CreateSubscription(c *gin.Context) {
@@ -123,8 +362,86 @@ func (srv *HttpService) CreateSubscription(c *gin.Context) {
 	srv.createKeeperSubscription(req, c)
 }
 
-// DeleteSubscription deletes any job with the jobid
-// provided as parameter in the request.
+// UpdateSubscriptionReq holds the payload expected for job PATCHes,
+// params that are left empty are not changed.
+type UpdateSubscriptionReq struct {
+	Params blockchain.Params `json:"params"`
+}
+
+// UpdateSubscription expects an UpdateSubscriptionReq payload and updates the from
+// address or options of the job with the jobid provided as parameter in the request.
+// Upkeeps synced for the job's registry are kept. Jobs of other nodes aren't found.
+func (srv *HttpService) UpdateSubscription(c *gin.Context) {
+	jobID, err := models.NewIDFromString(c.Param("jobid"))
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusBadRequest, nil)
+		return
+	}
+	var req UpdateSubscriptionReq
+	if err = c.BindJSON(&req); err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusBadRequest, nil)
+		return
+	}
+
+	membership, err := srv.Store.MembershipByJobID(jobID)
+	if gorm.IsRecordNotFoundError(err) || (err == nil && !membership.BelongsTo(requestNodeID(c))) {
+		c.JSON(http.StatusNotFound, nil)
+		return
+	} else if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+
+	if err = validateKeeperUpdate(req, membership); err != nil {
+		jsonError(c, http.StatusBadRequest, err)
+		return
+	}
+	membership.JobOptions = jobOptionsFromParams(req.Params, membership.JobOptions)
+	if err = membership.Validate(); err != nil {
+		jsonError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	reg := membership.Registry
+	if req.Params.From != "" && common.HexToAddress(req.Params.From) != membership.From {
+		from := common.HexToAddress(req.Params.From)
+		exists, err := srv.keeperExists(reg, from)
+		if err != nil {
+			logger.Error(err)
+			c.JSON(http.StatusInternalServerError, nil)
+			return
+		} else if exists {
+			jsonError(c, http.StatusConflict, fmt.Errorf("%s is already a keeper on the registry", from.Hex()))
+			return
+		}
+
+		membership.From = from
+		reg, membership, err = srv.syncNewMembership(reg, membership)
+		if err != nil {
+			jsonError(c, registryErrorStatus(err), err)
+			return
+		}
+		if err = srv.Store.UpsertRegistry(reg); err != nil {
+			logger.Error(err)
+			c.JSON(http.StatusInternalServerError, nil)
+			return
+		}
+	}
+
+	if err = srv.Store.UpsertMembership(membership); err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp{ID: membership.ReferenceID})
+}
+
+// DeleteSubscription deletes any job with the jobid provided as
+// parameter in the request, unless it belongs to another node.
 func (srv *HttpService) DeleteSubscription(c *gin.Context) {
 	// XXX: this now only works for keeper jobs
 	jobID, err := models.NewIDFromString(c.Param("jobid"))
@@ -133,7 +450,16 @@ func (srv *HttpService) DeleteSubscription(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, nil)
 		return
 	}
-	if err := srv.Store.DeleteRegistryByJobID(jobID); err != nil {
+	membership, err := srv.Store.MembershipByJobID(jobID)
+	if err == nil && !membership.BelongsTo(requestNodeID(c)) {
+		c.JSON(http.StatusNotFound, nil)
+		return
+	} else if err != nil && !gorm.IsRecordNotFoundError(err) {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+	if err := srv.Store.DeleteMembershipByJobID(jobID); err != nil {
 		logger.Error(err)
 		c.JSON(http.StatusInternalServerError, nil)
 		return
@@ -142,12 +468,6 @@ func (srv *HttpService) DeleteSubscription(c *gin.Context) {
 	c.JSON(http.StatusOK, resp{ID: jobID.String()})
 }
 
-// ShowHealth returns the following when online:
-//  {"chainlink": true}
-func (srv *HttpService) ShowHealth(c *gin.Context) {
-	c.JSON(200, gin.H{"chainlink": true})
-}
-
 // Inspired by https://github.com/gin-gonic/gin/issues/961
 func loggerFunc() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -221,8 +541,7 @@ func readSanitizedJSON(buf *bytes.Buffer) (string, error) {
 
 func (srv *HttpService) createKeeperSubscription(req CreateSubscriptionReq, c *gin.Context) {
 	if err := validateKeeperRequest(&req); err != nil {
-		logger.Error(err)
-		c.JSON(http.StatusBadRequest, nil)
+		jsonError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -234,15 +553,158 @@ func (srv *HttpService) createKeeperSubscription(req CreateSubscriptionReq, c *g
 	}
 	address := common.HexToAddress(req.Params.Address)
 	from := common.HexToAddress(req.Params.From)
-	reg := keeper.NewRegistry(address, from, jobID)
-	err = srv.Store.UpsertRegistry(reg)
+	turnTaking := keeper.TurnTakingStrategy(req.Params.TurnTaking)
+	nodeID := requestNodeID(c)
+	options := jobOptionsFromParams(req.Params, keeper.JobOptions{})
+	if err = options.Validate(); err != nil {
+		jsonError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// the Chainlink node can post the same job more than once, the original
+	// reference ID is returned if nothing changed, options are updated with PATCH
+	existing, err := srv.Store.MembershipByJobID(jobID)
+	if err == nil {
+		if !existing.BelongsTo(nodeID) {
+			jsonError(c, http.StatusConflict, fmt.Errorf("job %s already exists for another node", jobID))
+			return
+		}
+		if existing.Registry.Address != address || existing.From != from {
+			jsonError(c, http.StatusConflict, fmt.Errorf("job %s already exists with different params", jobID))
+			return
+		}
+		if !existing.JobOptions.Equal(options) {
+			jsonError(c, http.StatusConflict, fmt.Errorf("job %s already exists with different options, update it instead", jobID))
+			return
+		}
+		if existing.Orphaned {
+			// the job is known to the node again
+			if err = srv.Store.SetMembershipOrphaned(jobID, false); err != nil {
+				logger.Error(err)
+				c.JSON(http.StatusInternalServerError, nil)
+				return
+			}
+		}
+		c.JSON(http.StatusOK, resp{ID: existing.ReferenceID})
+		return
+	} else if !gorm.IsRecordNotFoundError(err) {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	}
+
+	version, err := keeper.DetectRegistryVersion(address, srv.EthClient)
+	if err != nil {
+		jsonError(c, registryErrorStatus(err), err)
+		return
+	}
+
+	// registries are shared by all of our keepers on them, a new job
+	// only adds a membership if the registry is already serviced
+	reg, err := srv.Store.RegistryByAddress(address)
+	if gorm.IsRecordNotFoundError(err) {
+		reg = keeper.NewRegistry(address)
+		reg.Version = version
+		if turnTaking != "" {
+			reg.TurnTaking = turnTaking
+		}
+	} else if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	} else if turnTaking != "" && turnTaking != reg.TurnTaking {
+		jsonError(c, http.StatusBadRequest, fmt.Errorf("registry already uses %s turn taking", reg.TurnTaking))
+		return
+	}
+
+	exists, err := srv.keeperExists(reg, from)
+	if err != nil {
+		logger.Error(err)
+		c.JSON(http.StatusInternalServerError, nil)
+		return
+	} else if exists {
+		jsonError(c, http.StatusConflict, fmt.Errorf("%s is already a keeper on the registry", from.Hex()))
+		return
+	}
+
+	membership := keeper.NewMembership(reg, from, jobID)
+	membership.NodeID = nodeID
+	membership.JobOptions = options
+	reg, membership, err = srv.syncNewMembership(reg, membership)
+	if err != nil {
+		jsonError(c, registryErrorStatus(err), err)
+		return
+	}
+
+	membership, err = srv.Store.CreateMembership(reg, membership)
 	if err != nil {
 		logger.Error(err)
 		c.JSON(http.StatusInternalServerError, nil)
 		return
 	}
 
-	c.JSON(http.StatusCreated, resp{ID: reg.ReferenceID})
+	if srv.Synchronizer != nil {
+		srv.Synchronizer.TriggerSync()
+	}
+
+	c.JSON(http.StatusCreated, resp{ID: membership.ReferenceID})
+}
+
+// keeperExists returns whether the registry already has a membership for the from address
+func (srv *HttpService) keeperExists(reg keeper.Registry, from common.Address) (bool, error) {
+	if reg.ID == 0 {
+		return false, nil
+	}
+	memberships, err := srv.Store.MembershipsForRegistries([]uint32{reg.ID})
+	if err != nil {
+		return false, err
+	}
+	for _, membership := range memberships {
+		if membership.From == from {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// syncNewMembership checks that the from address is an active keeper on the registry,
+// returning the registry and membership synced from the contract so that they don't
+// get saved with a zeroed config
+func (srv *HttpService) syncNewMembership(reg keeper.Registry, membership keeper.Membership) (keeper.Registry, keeper.Membership, error) {
+	contract, err := keeper.NewRegistryContract(reg.Version, reg.Address, srv.EthClient)
+	if err != nil {
+		return reg, membership, err
+	}
+	reg, keeperAddresses, err := reg.SyncFromContract(contract)
+	if err != nil {
+		return reg, membership, errors.Wrap(err, "unable to sync registry")
+	}
+	membership.Registry = reg
+	if err = membership.ValidateKeeper(contract); err != nil {
+		return reg, membership, err
+	}
+	membership, err = membership.SyncKeeperIndex(keeperAddresses)
+	if err != nil {
+		return reg, membership, err
+	}
+	return reg, membership, nil
+}
+
+// registryErrorStatus returns the status of the response to a failure to service a
+// registry: the job is only rejected if the registry is invalid, the failures to
+// call the ethereum node are reported as a bad gateway so that the job can be retried
+func registryErrorStatus(err error) int {
+	if errors.As(err, &keeper.InvalidRegistryError{}) {
+		return http.StatusBadRequest
+	}
+	return http.StatusBadGateway
+}
+
+// jsonError logs the error and responds with it in the body:
+//  {"error": "<message>"}
+func jsonError(c *gin.Context, status int, err error) {
+	logger.Error(err)
+	c.JSON(status, gin.H{"error": err.Error()})
 }
 
 func validateKeeperRequest(req *CreateSubscriptionReq) error {
@@ -256,5 +718,54 @@ func validateKeeperRequest(req *CreateSubscriptionReq) error {
 	if !common.IsHexAddress(req.Params.From) {
 		return errors.New("invalid or missing from param")
 	}
+	if req.Params.TurnTaking != "" {
+		if _, err := keeper.NewTurnTaker(keeper.TurnTakingStrategy(req.Params.TurnTaking)); err != nil {
+			return errors.Wrap(err, "invalid turnTaking param")
+		}
+	}
+	return nil
+}
+
+func validateKeeperUpdate(req UpdateSubscriptionReq, membership keeper.Membership) error {
+	if req.Params.Address != "" && common.HexToAddress(req.Params.Address) != membership.Registry.Address {
+		return errors.New("the address param can't be updated, create a new job instead")
+	}
+	if req.Params.From != "" && !common.IsHexAddress(req.Params.From) {
+		return errors.New("invalid from param")
+	}
+	if req.Params.TurnTaking != "" && keeper.TurnTakingStrategy(req.Params.TurnTaking) != membership.Registry.TurnTaking {
+		return errors.New("the turnTaking param can't be updated, it is shared by the registry's jobs")
+	}
 	return nil
 }
+
+// jobOptionsFromParams returns the options with the ones set in the params applied
+func jobOptionsFromParams(params blockchain.Params, options keeper.JobOptions) keeper.JobOptions {
+	if params.GasBuffer != nil {
+		options.GasBuffer = params.GasBuffer
+	}
+	if params.MaxGasPrice != nil {
+		options.MaxGasPrice = params.MaxGasPrice
+	}
+	if params.AllowedUpkeeps != nil {
+		options.AllowedUpkeeps = params.AllowedUpkeeps
+	}
+	if params.DeniedUpkeeps != nil {
+		options.DeniedUpkeeps = params.DeniedUpkeeps
+	}
+	if params.DryRun != nil {
+		options.DryRun = *params.DryRun
+	}
+	if params.CheckConfirmations != nil {
+		options.CheckConfirmations = *params.CheckConfirmations
+	}
+	if string(params.PayloadTemplate) == "null" {
+		options.PayloadTemplate = nil
+	} else if params.PayloadTemplate != nil {
+		options.PayloadTemplate = keeper.PayloadTemplate(params.PayloadTemplate)
+	}
+	if params.JobEndpoint != "" {
+		options.JobEndpoint = chainlink.JobEndpoint(params.JobEndpoint)
+	}
+	return options
+}
@@ -2,47 +2,352 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/smartcontractkit/chainlink/core/store/models"
 	"github.com/smartcontractkit/external-initiator/blockchain"
+	"github.com/smartcontractkit/external-initiator/chainlink"
 	"github.com/smartcontractkit/external-initiator/eitest"
+	"github.com/smartcontractkit/external-initiator/internal/mocks"
 	"github.com/smartcontractkit/external-initiator/keeper"
 	"github.com/smartcontractkit/external-initiator/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
 const (
 	key    = "testKey"
 	secret = "testSecretAbcdæøå"
+	// testSecretsKey encrypts the signing secrets of the credentials
+	testSecretsKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
 )
 
+type fakeSynchronizer struct {
+	keeper.RegistrySynchronizer
+	triggered *int
+}
+
+func (s fakeSynchronizer) TriggerSync() {
+	*s.triggered++
+}
+
+var registryConfig = struct {
+	PaymentPremiumPPB uint32
+	BlockCountPerTurn *big.Int
+	CheckGasLimit     uint32
+	StalenessSeconds  *big.Int
+	FallbackGasPrice  *big.Int
+	FallbackLinkPrice *big.Int
+}{
+	PaymentPremiumPPB: 100,
+	BlockCountPerTurn: big.NewInt(20),
+	CheckGasLimit:     2_000_000,
+	StalenessSeconds:  big.NewInt(3600),
+	FallbackGasPrice:  big.NewInt(1000000),
+	FallbackLinkPrice: big.NewInt(1000000),
+}
+
+func keeperInfo(active bool) interface{} {
+	return struct {
+		Payee   common.Address
+		Active  bool
+		Balance *big.Int
+	}{eitest.NewAddress(), active, big.NewInt(0)}
+}
+
+func createJobRequest(t *testing.T, srv *HttpService, requestData CreateSubscriptionReq) *httptest.ResponseRecorder {
+	requestBytes, err := json.Marshal(requestData)
+	require.NoError(t, err)
+
+	request := httptest.NewRequest("POST", "http://localhost:8080/jobs", bytes.NewReader(requestBytes))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Add(ExternalInitiatorAccessKeyHeader, key)
+	request.Header.Add(ExternalInitiatorSecretHeader, secret)
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, request)
+	return w
+}
+
 func TestCreateController(t *testing.T) {
 	dbClient, cleanup := store.SetupTestDB(t)
 	regStore := keeper.NewStore(dbClient.DB())
 	defer cleanup()
 
+	address := eitest.NewAddress()
+	from := eitest.NewAddress()
+	ethMock := new(mocks.EthClient)
+	ethMock.On("CodeAt", mock.Anything, mock.Anything, mock.Anything).Return([]byte{0x60, 0x80}, nil)
+	registryMock := eitest.NewContractMockReceiver(t, ethMock, keeper.UpkeepRegistryABI, address)
+	registryMock.MockResponse("getConfig", registryConfig)
+	registryMock.MockResponse("getKeeperList", []common.Address{eitest.NewAddress(), from})
+	registryMock.MockResponse("getKeeperInfo", keeperInfo(true))
+
+	triggered := 0
+	srv := &HttpService{
+		AccessKey:    key,
+		Secret:       secret,
+		Store:        regStore,
+		EthClient:    ethMock,
+		Synchronizer: fakeSynchronizer{triggered: &triggered},
+	}
+	srv.createRouter()
+
+	jobID := models.NewID().String()
+	w := createJobRequest(t, srv, CreateSubscriptionReq{
+		JobID: jobID,
+		Params: blockchain.Params{
+			Address: address.Hex(),
+			From:    from.Hex(),
+		},
+	})
+	require.Equal(t, 201, w.Code)
+
+	var respJSON map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &respJSON)
+	require.NoError(t, err)
+
+	registries, err := regStore.Registries()
+	require.NoError(t, err)
+	require.Len(t, registries, 1)
+	assert.Equal(t, uint32(20), registries[0].BlockCountPerTurn)
+	assert.Equal(t, uint32(2_000_000), registries[0].CheckGas)
+	assert.Equal(t, uint32(2), registries[0].NumKeepers)
+	assert.Equal(t, 1, triggered)
+
+	memberships, err := regStore.MembershipsForRegistries([]uint32{registries[0].ID})
+	require.NoError(t, err)
+	require.Len(t, memberships, 1)
+	assert.Equal(t, respJSON["id"], memberships[0].ReferenceID)
+	assert.Equal(t, from, memberships[0].From)
+	assert.Equal(t, uint32(1), memberships[0].KeeperIndex)
+}
+
+func TestCreateController_JobOptions(t *testing.T) {
+	dbClient, cleanup := store.SetupTestDB(t)
+	regStore := keeper.NewStore(dbClient.DB())
+	defer cleanup()
+
+	address := eitest.NewAddress()
+	from := eitest.NewAddress()
+	ethMock := new(mocks.EthClient)
+	ethMock.On("CodeAt", mock.Anything, mock.Anything, mock.Anything).Return([]byte{0x60, 0x80}, nil)
+	registryMock := eitest.NewContractMockReceiver(t, ethMock, keeper.UpkeepRegistryABI, address)
+	registryMock.MockResponse("getConfig", registryConfig)
+	registryMock.MockResponse("getKeeperList", []common.Address{from})
+	registryMock.MockResponse("getKeeperInfo", keeperInfo(true))
+
 	srv := &HttpService{
 		AccessKey: key,
 		Secret:    secret,
 		Store:     regStore,
+		EthClient: ethMock,
+	}
+	srv.createRouter()
+
+	var req CreateSubscriptionReq
+	require.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{
+		"jobId": "%s",
+		"params": {
+			"address": "%s",
+			"from": "%s",
+			"gasBuffer": 100000,
+			"maxGasPrice": 50000000000,
+			"allowedUpkeeps": [0, 2],
+			"dryRun": true,
+			"checkConfirmations": 2,
+			"payloadTemplate": {"upkeepId": "{{.UpkeepID}}", "performData": "{{.PerformData}}"},
+			"jobEndpoint": "webhook"
+		}
+	}`, models.NewID().String(), address.Hex(), from.Hex())), &req))
+
+	t.Run("rejects invalid options", func(t *testing.T) {
+		invalid := req
+		invalid.Params.DeniedUpkeeps = []uint64{1}
+		w := createJobRequest(t, srv, invalid)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		invalid = req
+		invalid.Params.PayloadTemplate = json.RawMessage(`{"upkeepId": "{{.Upkeep}}"}`)
+		w = createJobRequest(t, srv, invalid)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		invalid = req
+		invalid.Params.JobEndpoint = "v3"
+		w = createJobRequest(t, srv, invalid)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		eitest.AssertCount(t, dbClient.DB(), keeper.Membership{}, 0)
+	})
+
+	w := createJobRequest(t, srv, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	jobID, err := models.NewIDFromString(req.JobID)
+	require.NoError(t, err)
+	membership, err := regStore.MembershipByJobID(jobID)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100_000), *membership.GasBuffer)
+	assert.Equal(t, "50000000000", membership.MaxGasPrice.String())
+	assert.Equal(t, keeper.UpkeepIDs{0, 2}, membership.AllowedUpkeeps)
+	assert.True(t, membership.DryRun)
+	assert.Equal(t, uint32(2), membership.CheckConfirmations)
+	assert.JSONEq(t, `{"upkeepId": "{{.UpkeepID}}", "performData": "{{.PerformData}}"}`, string(membership.PayloadTemplate))
+	assert.Equal(t, chainlink.WebhookJobEndpoint, membership.JobEndpoint)
+
+	w = authenticatedGet(srv, "/jobs")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []jobPresenter `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, uint32(100_000), response.Data[0].Options.GasBuffer)
+	assert.Equal(t, "50000000000", response.Data[0].Options.MaxGasPrice)
+	assert.JSONEq(t, string(membership.PayloadTemplate), string(response.Data[0].Options.PayloadTemplate))
+	assert.Equal(t, "webhook", response.Data[0].Options.JobEndpoint)
+}
+
+func TestCreateController_MultipleKeepers(t *testing.T) {
+	dbClient, cleanup := store.SetupTestDB(t)
+	regStore := keeper.NewStore(dbClient.DB())
+	defer cleanup()
+
+	address := eitest.NewAddress()
+	from := eitest.NewAddress()
+	otherFrom := eitest.NewAddress()
+	ethMock := new(mocks.EthClient)
+	ethMock.On("CodeAt", mock.Anything, mock.Anything, mock.Anything).Return([]byte{0x60, 0x80}, nil)
+	registryMock := eitest.NewContractMockReceiver(t, ethMock, keeper.UpkeepRegistryABI, address)
+	registryMock.MockResponse("getConfig", registryConfig)
+	registryMock.MockResponse("getKeeperList", []common.Address{otherFrom, eitest.NewAddress(), from})
+	registryMock.MockResponse("getKeeperInfo", keeperInfo(true))
+
+	srv := &HttpService{
+		AccessKey: key,
+		Secret:    secret,
+		Store:     regStore,
+		EthClient: ethMock,
+	}
+	srv.createRouter()
+
+	newRequest := func(from common.Address, turnTaking string) CreateSubscriptionReq {
+		return CreateSubscriptionReq{
+			JobID: models.NewID().String(),
+			Params: blockchain.Params{
+				Address:    address.Hex(),
+				From:       from.Hex(),
+				TurnTaking: turnTaking,
+			},
+		}
+	}
+
+	w := createJobRequest(t, srv, newRequest(from, ""))
+	require.Equal(t, http.StatusCreated, w.Code)
+	w = createJobRequest(t, srv, newRequest(otherFrom, ""))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	eitest.AssertCount(t, dbClient.DB(), keeper.Registry{}, 1)
+	registries, err := regStore.Registries()
+	require.NoError(t, err)
+	memberships, err := regStore.MembershipsForRegistries([]uint32{registries[0].ID})
+	require.NoError(t, err)
+	require.Len(t, memberships, 2)
+	assert.Equal(t, uint32(2), memberships[0].KeeperIndex)
+	assert.Equal(t, uint32(0), memberships[1].KeeperIndex)
+
+	t.Run("rejects a second job for the same keeper", func(t *testing.T) {
+		w := createJobRequest(t, srv, newRequest(from, ""))
+		require.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("rejects a different turn taking strategy", func(t *testing.T) {
+		w := createJobRequest(t, srv, newRequest(eitest.NewAddress(), string(keeper.BlockHashStrategy)))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	eitest.AssertCount(t, dbClient.DB(), keeper.Membership{}, 2)
+}
+
+func TestCreateController_InvalidKeeper(t *testing.T) {
+	dbClient, cleanup := store.SetupTestDB(t)
+	regStore := keeper.NewStore(dbClient.DB())
+	defer cleanup()
+
+	tests := []struct {
+		name       string
+		keeperList func(from common.Address) []common.Address
+		active     bool
+	}{
+		{"not in the keeper list", func(common.Address) []common.Address { return []common.Address{eitest.NewAddress()} }, true},
+		{"inactive keeper", func(from common.Address) []common.Address { return []common.Address{from} }, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			address := eitest.NewAddress()
+			from := eitest.NewAddress()
+			ethMock := new(mocks.EthClient)
+			ethMock.On("CodeAt", mock.Anything, mock.Anything, mock.Anything).Return([]byte{0x60, 0x80}, nil)
+			registryMock := eitest.NewContractMockReceiver(t, ethMock, keeper.UpkeepRegistryABI, address)
+			registryMock.MockResponse("getConfig", registryConfig)
+			registryMock.MockResponse("getKeeperList", test.keeperList(from))
+			registryMock.MockResponse("getKeeperInfo", keeperInfo(test.active))
+
+			srv := &HttpService{
+				AccessKey: key,
+				Secret:    secret,
+				Store:     regStore,
+				EthClient: ethMock,
+			}
+			srv.createRouter()
+
+			w := createJobRequest(t, srv, CreateSubscriptionReq{
+				JobID: models.NewID().String(),
+				Params: blockchain.Params{
+					Address: address.Hex(),
+					From:    from.Hex(),
+				},
+			})
+			require.Equal(t, http.StatusBadRequest, w.Code)
+
+			var respJSON map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &respJSON))
+			assert.Contains(t, respJSON["error"], from.Hex())
+			eitest.AssertCount(t, dbClient.DB(), keeper.Registry{}, 0)
+		})
+	}
+}
+
+func TestCreateController_NoContractCode(t *testing.T) {
+	dbClient, cleanup := store.SetupTestDB(t)
+	defer cleanup()
+
+	ethMock := new(mocks.EthClient)
+	ethMock.On("CodeAt", mock.Anything, mock.Anything, mock.Anything).Return([]byte{}, nil)
+	srv := &HttpService{
+		AccessKey: key,
+		Secret:    secret,
+		Store:     keeper.NewStore(dbClient.DB()),
+		EthClient: ethMock,
 	}
 	srv.createRouter()
 
-	jobID := models.NewID().String()
 	requestData := CreateSubscriptionReq{
-		JobID: jobID,
+		JobID: models.NewID().String(),
 		Params: blockchain.Params{
 			Address: eitest.NewAddress().Hex(),
 			From:    eitest.NewAddress().Hex(),
 		},
 	}
-
 	requestBytes, err := json.Marshal(requestData)
 	require.NoError(t, err)
 
@@ -53,11 +358,253 @@ func TestCreateController(t *testing.T) {
 
 	w := httptest.NewRecorder()
 	srv.ServeHTTP(w, request)
-	require.Equal(t, 201, w.Code)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	ethMock.AssertExpectations(t)
+}
 
-	var respJSON map[string]interface{}
-	err = json.Unmarshal(w.Body.Bytes(), &respJSON)
+func TestCreateController_EthClientFailure(t *testing.T) {
+	dbClient, cleanup := store.SetupTestDB(t)
+	defer cleanup()
+
+	ethMock := new(mocks.EthClient)
+	ethMock.On("CodeAt", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+	srv := &HttpService{
+		AccessKey: key,
+		Secret:    secret,
+		Store:     keeper.NewStore(dbClient.DB()),
+		EthClient: ethMock,
+	}
+	srv.createRouter()
+
+	w := createJobRequest(t, srv, CreateSubscriptionReq{
+		JobID: models.NewID().String(),
+		Params: blockchain.Params{
+			Address: eitest.NewAddress().Hex(),
+			From:    eitest.NewAddress().Hex(),
+		},
+	})
+	require.Equal(t, http.StatusBadGateway, w.Code)
+	eitest.AssertCount(t, dbClient.DB(), keeper.Membership{}, 0)
+	ethMock.AssertExpectations(t)
+}
+
+func TestCreateController_Idempotent(t *testing.T) {
+	dbClient, cleanup := store.SetupTestDB(t)
+	regStore := keeper.NewStore(dbClient.DB())
+	defer cleanup()
+
+	address := eitest.NewAddress()
+	from := eitest.NewAddress()
+	ethMock := new(mocks.EthClient)
+	ethMock.On("CodeAt", mock.Anything, mock.Anything, mock.Anything).Return([]byte{0x60, 0x80}, nil)
+	registryMock := eitest.NewContractMockReceiver(t, ethMock, keeper.UpkeepRegistryABI, address)
+	registryMock.MockResponse("getConfig", registryConfig)
+	registryMock.MockResponse("getKeeperList", []common.Address{from})
+	registryMock.MockResponse("getKeeperInfo", keeperInfo(true))
+
+	srv := &HttpService{
+		AccessKey: key,
+		Secret:    secret,
+		Store:     regStore,
+		EthClient: ethMock,
+	}
+	srv.createRouter()
+
+	req := CreateSubscriptionReq{
+		JobID: models.NewID().String(),
+		Params: blockchain.Params{
+			Address: address.Hex(),
+			From:    from.Hex(),
+		},
+	}
+	w := createJobRequest(t, srv, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created resp
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	t.Run("returns the existing reference ID", func(t *testing.T) {
+		w := createJobRequest(t, srv, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var respJSON resp
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &respJSON))
+		assert.Equal(t, created.ID, respJSON.ID)
+		eitest.AssertCount(t, dbClient.DB(), keeper.Membership{}, 1)
+	})
+
+	t.Run("clears the orphaned flag", func(t *testing.T) {
+		jobID, err := models.NewIDFromString(req.JobID)
+		require.NoError(t, err)
+		require.NoError(t, regStore.SetMembershipOrphaned(jobID, true))
+
+		w := createJobRequest(t, srv, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		membership, err := regStore.MembershipByJobID(jobID)
+		require.NoError(t, err)
+		assert.False(t, membership.Orphaned)
+	})
+
+	t.Run("rejects the same job with different params", func(t *testing.T) {
+		changed := req
+		changed.Params.From = eitest.NewAddress().Hex()
+		w := createJobRequest(t, srv, changed)
+		require.Equal(t, http.StatusConflict, w.Code)
+		eitest.AssertCount(t, dbClient.DB(), keeper.Membership{}, 1)
+
+		changed = req
+		changed.Params.PayloadTemplate = json.RawMessage(`{"upkeep": "{{.UpkeepID}}"}`)
+		w = createJobRequest(t, srv, changed)
+		require.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestShowJobsController(t *testing.T) {
+	dbClient, cleanup := store.SetupTestDB(t)
+	regStore := keeper.NewStore(dbClient.DB())
+	defer cleanup()
+
+	reg := keeper.NewRegistry(eitest.NewAddress())
+	var jobIDs []*models.ID
+	for i := 0; i < 3; i++ {
+		jobID := models.NewID()
+		jobIDs = append(jobIDs, jobID)
+		_, err := regStore.CreateMembership(reg, keeper.NewMembership(reg, eitest.NewAddress(), jobID))
+		require.NoError(t, err)
+		if reg.ID == 0 {
+			reg, err = regStore.RegistryByAddress(reg.Address)
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, regStore.SetMembershipOrphaned(jobIDs[2], true))
+
+	srv := &HttpService{
+		AccessKey: key,
+		Secret:    secret,
+		Store:     regStore,
+	}
+	srv.createRouter()
+
+	w := authenticatedGet(srv, "/jobs?page=2&size=2")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data  []jobPresenter `json:"data"`
+		Count int            `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.Count)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, jobIDs[2].String(), response.Data[0].JobID)
+	assert.Equal(t, reg.Address.Hex(), response.Data[0].Address)
+	assert.True(t, response.Data[0].Orphaned)
+}
+
+func updateJobRequest(t *testing.T, srv *HttpService, jobID string, requestData UpdateSubscriptionReq) *httptest.ResponseRecorder {
+	requestBytes, err := json.Marshal(requestData)
 	require.NoError(t, err)
+
+	request := httptest.NewRequest("PATCH", "http://localhost:8080/jobs/"+jobID, bytes.NewReader(requestBytes))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Add(ExternalInitiatorAccessKeyHeader, key)
+	request.Header.Add(ExternalInitiatorSecretHeader, secret)
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, request)
+	return w
+}
+
+func TestUpdateController(t *testing.T) {
+	dbClient, cleanup := store.SetupTestDB(t)
+	regStore := keeper.NewStore(dbClient.DB())
+	defer cleanup()
+
+	address := eitest.NewAddress()
+	from := eitest.NewAddress()
+	newFrom := eitest.NewAddress()
+	jobID := models.NewID()
+	reg := keeper.NewRegistry(address)
+	membership, err := regStore.CreateMembership(reg, keeper.NewMembership(reg, from, jobID))
+	require.NoError(t, err)
+	upkeep := keeper.Registration{RegistryID: membership.RegistryID, UpkeepID: 0, ExecuteGas: 10_000}
+	require.NoError(t, dbClient.DB().Create(&upkeep).Error)
+
+	ethMock := new(mocks.EthClient)
+	registryMock := eitest.NewContractMockReceiver(t, ethMock, keeper.UpkeepRegistryABI, address)
+	registryMock.MockResponse("getConfig", registryConfig)
+	registryMock.MockResponse("getKeeperList", []common.Address{from, newFrom})
+	registryMock.MockResponse("getKeeperInfo", keeperInfo(true))
+
+	srv := &HttpService{
+		AccessKey: key,
+		Secret:    secret,
+		Store:     regStore,
+		EthClient: ethMock,
+	}
+	srv.createRouter()
+
+	t.Run("updates the from address", func(t *testing.T) {
+		w := updateJobRequest(t, srv, jobID.String(), UpdateSubscriptionReq{
+			Params: blockchain.Params{From: newFrom.Hex()},
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+		var respJSON resp
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &respJSON))
+		assert.Equal(t, membership.ReferenceID, respJSON.ID)
+
+		updated, err := regStore.MembershipByJobID(jobID)
+		require.NoError(t, err)
+		assert.Equal(t, newFrom, updated.From)
+		assert.Equal(t, uint32(1), updated.KeeperIndex)
+		eitest.AssertCount(t, dbClient.DB(), keeper.Registration{}, 1)
+	})
+
+	t.Run("updates the job options", func(t *testing.T) {
+		dryRun := true
+		w := updateJobRequest(t, srv, jobID.String(), UpdateSubscriptionReq{
+			Params: blockchain.Params{DryRun: &dryRun, DeniedUpkeeps: []uint64{3}},
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		updated, err := regStore.MembershipByJobID(jobID)
+		require.NoError(t, err)
+		assert.True(t, updated.DryRun)
+		assert.Equal(t, keeper.UpkeepIDs{3}, updated.DeniedUpkeeps)
+		assert.Equal(t, newFrom, updated.From)
+
+		w = updateJobRequest(t, srv, jobID.String(), UpdateSubscriptionReq{
+			Params: blockchain.Params{AllowedUpkeeps: []uint64{1}},
+		})
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("sets and resets the payload template", func(t *testing.T) {
+		w := updateJobRequest(t, srv, jobID.String(), UpdateSubscriptionReq{
+			Params: blockchain.Params{PayloadTemplate: json.RawMessage(`"{\"id\": {{.UpkeepID}}}"`)},
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+		updated, err := regStore.MembershipByJobID(jobID)
+		require.NoError(t, err)
+		assert.JSONEq(t, `"{\"id\": {{.UpkeepID}}}"`, string(updated.PayloadTemplate))
+
+		w = updateJobRequest(t, srv, jobID.String(), UpdateSubscriptionReq{
+			Params: blockchain.Params{PayloadTemplate: json.RawMessage(`null`)},
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+		updated, err = regStore.MembershipByJobID(jobID)
+		require.NoError(t, err)
+		assert.Empty(t, updated.PayloadTemplate)
+	})
+
+	t.Run("rejects changing the registry address", func(t *testing.T) {
+		w := updateJobRequest(t, srv, jobID.String(), UpdateSubscriptionReq{
+			Params: blockchain.Params{Address: eitest.NewAddress().Hex()},
+		})
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("404s on unknown jobs", func(t *testing.T) {
+		w := updateJobRequest(t, srv, models.NewID().String(), UpdateSubscriptionReq{})
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
 }
 
 func TestDeleteController(t *testing.T) {
@@ -66,12 +613,8 @@ func TestDeleteController(t *testing.T) {
 	defer cleanup()
 
 	jobID := models.NewID()
-	reg := keeper.NewRegistry(
-		eitest.NewAddress(),
-		eitest.NewAddress(),
-		jobID,
-	)
-	err := regStore.UpsertRegistry(reg)
+	reg := keeper.NewRegistry(eitest.NewAddress())
+	_, err := regStore.CreateMembership(reg, keeper.NewMembership(reg, eitest.NewAddress(), jobID))
 	require.NoError(t, err)
 
 	srv := &HttpService{
@@ -114,6 +657,236 @@ func TestDeleteController(t *testing.T) {
 	})
 }
 
+func nodeRequest(t *testing.T, srv *HttpService, method, path string, body interface{}, accessKey, secret string) *httptest.ResponseRecorder {
+	var requestBytes []byte
+	if body != nil {
+		var err error
+		requestBytes, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+
+	request := httptest.NewRequest(method, path, bytes.NewReader(requestBytes))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Add(ExternalInitiatorAccessKeyHeader, accessKey)
+	request.Header.Add(ExternalInitiatorSecretHeader, secret)
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, request)
+	return w
+}
+
+func TestJobsController_ScopedToNode(t *testing.T) {
+	dbClient, cleanup := store.SetupTestDB(t)
+	regStore := keeper.NewStore(dbClient.DB())
+	defer cleanup()
+
+	credential, err := keeper.NewCredential(nil, "otherKey", "otherSecret", nil)
+	require.NoError(t, err)
+	node, err := regStore.CreateNode(keeper.Node{
+		Name:                          "other",
+		URL:                           "http://other:6688",
+		InitiatorToChainlinkAccessKey: "otherIcKey",
+		InitiatorToChainlinkSecret:    "otherIcSecret",
+	}, credential)
+	require.NoError(t, err)
+
+	address := eitest.NewAddress()
+	from := eitest.NewAddress()
+	ethMock := new(mocks.EthClient)
+	ethMock.On("CodeAt", mock.Anything, mock.Anything, mock.Anything).Return([]byte{0x60, 0x80}, nil)
+	registryMock := eitest.NewContractMockReceiver(t, ethMock, keeper.UpkeepRegistryABI, address)
+	registryMock.MockResponse("getConfig", registryConfig)
+	registryMock.MockResponse("getKeeperList", []common.Address{from})
+	registryMock.MockResponse("getKeeperInfo", keeperInfo(true))
+
+	srv := &HttpService{
+		AccessKey: key,
+		Secret:    secret,
+		Store:     regStore,
+		EthClient: ethMock,
+	}
+	srv.createRouter()
+
+	jobID := models.NewID()
+	createReq := CreateSubscriptionReq{
+		JobID:  jobID.String(),
+		Params: blockchain.Params{Address: address.Hex(), From: from.Hex()},
+	}
+
+	t.Run("rejects the wrong secret", func(t *testing.T) {
+		w := nodeRequest(t, srv, "GET", "/jobs", nil, "otherKey", secret)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("creates the job for the node", func(t *testing.T) {
+		w := nodeRequest(t, srv, "POST", "/jobs", createReq, "otherKey", "otherSecret")
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		membership, err := regStore.MembershipByJobID(jobID)
+		require.NoError(t, err)
+		require.NotNil(t, membership.NodeID)
+		assert.Equal(t, node.ID, *membership.NodeID)
+	})
+
+	t.Run("lists the jobs of the node", func(t *testing.T) {
+		var response struct {
+			Count int `json:"count"`
+		}
+		w := nodeRequest(t, srv, "GET", "/jobs", nil, "otherKey", "otherSecret")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Count)
+
+		w = nodeRequest(t, srv, "GET", "/jobs", nil, key, secret)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 0, response.Count)
+	})
+
+	t.Run("hides the job from other nodes", func(t *testing.T) {
+		w := nodeRequest(t, srv, "POST", "/jobs", createReq, key, secret)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		w = nodeRequest(t, srv, "PATCH", "/jobs/"+jobID.String(), UpdateSubscriptionReq{}, key, secret)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = nodeRequest(t, srv, "DELETE", "/jobs/"+jobID.String(), nil, key, secret)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		eitest.AssertCount(t, dbClient.DB(), keeper.Membership{}, 1)
+	})
+
+	t.Run("deletes the job of the node", func(t *testing.T) {
+		w := nodeRequest(t, srv, "DELETE", "/jobs/"+jobID.String(), nil, "otherKey", "otherSecret")
+		assert.Equal(t, http.StatusOK, w.Code)
+		eitest.AssertCount(t, dbClient.DB(), keeper.Membership{}, 0)
+	})
+}
+
+func TestAuthenticate_Credentials(t *testing.T) {
+	dbClient, cleanup := store.SetupTestDB(t)
+	regStore := keeper.NewStore(dbClient.DB())
+	defer cleanup()
+
+	expired := time.Now().Add(-time.Minute)
+	expiresLater := time.Now().Add(time.Hour)
+	for accessKey, expiresAt := range map[string]*time.Time{
+		"current":  nil,
+		"rotating": &expiresLater,
+		"expired":  &expired,
+		"revoked":  nil,
+	} {
+		credential, err := keeper.NewCredential(nil, accessKey, accessKey+"Secret", expiresAt)
+		require.NoError(t, err)
+		_, err = regStore.CreateCredential(credential)
+		require.NoError(t, err)
+	}
+	require.NoError(t, regStore.RevokeCredential("revoked"))
+
+	srv := &HttpService{
+		AccessKey: key,
+		Secret:    secret,
+		Store:     regStore,
+	}
+	srv.createRouter()
+
+	for _, test := range []struct {
+		accessKey string
+		secret    string
+		status    int
+	}{
+		{key, secret, http.StatusOK},
+		{key, "wrong", http.StatusUnauthorized},
+		{"current", "currentSecret", http.StatusOK},
+		{"rotating", "rotatingSecret", http.StatusOK},
+		{"current", "rotatingSecret", http.StatusUnauthorized},
+		{"expired", "expiredSecret", http.StatusUnauthorized},
+		{"revoked", "revokedSecret", http.StatusUnauthorized},
+		{"unknown", "unknownSecret", http.StatusUnauthorized},
+	} {
+		w := nodeRequest(t, srv, "GET", "/jobs", nil, test.accessKey, test.secret)
+		assert.Equal(t, test.status, w.Code, test.accessKey)
+	}
+
+	t.Run("rejects the default access key without a secret", func(t *testing.T) {
+		srv := &HttpService{AccessKey: key, Store: regStore}
+		srv.createRouter()
+		w := nodeRequest(t, srv, "GET", "/jobs", nil, key, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestAuthenticate_Signed(t *testing.T) {
+	dbClient, cleanup := store.SetupTestDB(t)
+	regStore := keeper.NewStore(dbClient.DB())
+	defer cleanup()
+
+	secretKey, err := keeper.ParseSecretKey(testSecretsKey)
+	require.NoError(t, err)
+	signing, err := keeper.NewCredential(nil, "signing", "signingSecret", nil)
+	require.NoError(t, err)
+	require.NoError(t, signing.SetSigningSecret(secretKey, "signingSecret"))
+	_, err = regStore.CreateCredential(signing)
+	require.NoError(t, err)
+	hashed, err := keeper.NewCredential(nil, "hashed", "hashedSecret", nil)
+	require.NoError(t, err)
+	_, err = regStore.CreateCredential(hashed)
+	require.NoError(t, err)
+
+	srv := &HttpService{
+		AccessKey: key,
+		Secret:    secret,
+		Verifier:  chainlink.NewSignatureVerifier(time.Minute),
+		SecretKey: secretKey,
+		Store:     regStore,
+	}
+	srv.createRouter()
+
+	signedRequest := func(accessKey, secret string) *http.Request {
+		request := httptest.NewRequest("GET", "/jobs", nil)
+		request.Header.Add(ExternalInitiatorAccessKeyHeader, accessKey)
+		require.NoError(t, chainlink.SignRequest(request, secret, nil, time.Now()))
+		return request
+	}
+
+	for _, test := range []struct {
+		accessKey string
+		secret    string
+		status    int
+	}{
+		{key, secret, http.StatusOK},
+		{key, "wrong", http.StatusUnauthorized},
+		{"signing", "signingSecret", http.StatusOK},
+		{"hashed", "hashedSecret", http.StatusUnauthorized},
+	} {
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, signedRequest(test.accessKey, test.secret))
+		assert.Equal(t, test.status, w.Code, test.accessKey)
+	}
+
+	t.Run("rejects replays", func(t *testing.T) {
+		request := signedRequest(key, secret)
+		replay := request.Clone(context.Background())
+
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, request)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = httptest.NewRecorder()
+		srv.ServeHTTP(w, replay)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("rejects signing credentials without the secrets key", func(t *testing.T) {
+		srv := &HttpService{Verifier: chainlink.NewSignatureVerifier(time.Minute), Store: regStore}
+		srv.createRouter()
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, signedRequest("signing", "signingSecret"))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("rejects the secret header", func(t *testing.T) {
+		w := nodeRequest(t, srv, "GET", "/jobs", nil, key, secret)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
 func TestHealthController(t *testing.T) {
 	tests := []struct {
 		Name       string
@@ -140,6 +913,18 @@ func TestHealthController(t *testing.T) {
 	}
 }
 
+func TestMetricsController(t *testing.T) {
+	srv := &HttpService{}
+	srv.createRouter()
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "keeper_heads_received")
+	assert.Contains(t, w.Body.String(), "keeper_trigger_job_retries")
+}
+
 func TestRequireAuth(t *testing.T) {
 	tests := []struct {
 		Name   string
@@ -153,18 +938,138 @@ func TestRequireAuth(t *testing.T) {
 			"/health",
 			false,
 		},
+		{
+			"Readiness is open",
+			"GET",
+			"/ready",
+			false,
+		},
+		{
+			"Metrics is open",
+			"GET",
+			"/metrics",
+			false,
+		},
+		{
+			"Listing jobs is protected",
+			"GET",
+			"/jobs",
+			true,
+		},
 		{
 			"Creating jobs is protected",
 			"POST",
 			"/jobs",
 			true,
 		},
+		{
+			"Updating jobs is protected",
+			"PATCH",
+			"/jobs/test",
+			true,
+		},
 		{
 			"Deleting jobs is protected",
 			"DELETE",
 			"/jobs/test",
 			true,
 		},
+		{
+			"Listing job runs is protected",
+			"GET",
+			"/runs",
+			true,
+		},
+		{
+			"Listing job triggers is protected",
+			"GET",
+			"/triggers",
+			true,
+		},
+		{
+			"Replaying job triggers is protected",
+			"POST",
+			"/triggers/1/replay",
+			true,
+		},
+		{
+			"Listing registries is protected",
+			"GET",
+			"/registries",
+			true,
+		},
+		{
+			"Showing registries is protected",
+			"GET",
+			"/registries/1",
+			true,
+		},
+		{
+			"Listing upkeeps is protected",
+			"GET",
+			"/registries/1/upkeeps",
+			true,
+		},
+		{
+			"Showing upkeeps is protected",
+			"GET",
+			"/registries/1/upkeeps/0",
+			true,
+		},
+		{
+			"Pausing registries is protected",
+			"POST",
+			"/registries/1/pause",
+			true,
+		},
+		{
+			"Resuming registries is protected",
+			"POST",
+			"/registries/1/resume",
+			true,
+		},
+		{
+			"Listing upkeep filters is protected",
+			"GET",
+			"/registries/1/filters",
+			true,
+		},
+		{
+			"Creating upkeep filters is protected",
+			"POST",
+			"/registries/1/filters",
+			true,
+		},
+		{
+			"Deleting upkeep filters is protected",
+			"DELETE",
+			"/registries/1/filters/1",
+			true,
+		},
+		{
+			"Pausing upkeeps is protected",
+			"POST",
+			"/registries/1/upkeeps/0/pause",
+			true,
+		},
+		{
+			"Resuming upkeeps is protected",
+			"POST",
+			"/registries/1/upkeeps/0/resume",
+			true,
+		},
+		{
+			"Checking upkeeps is protected",
+			"POST",
+			"/registries/1/upkeeps/0/check",
+			true,
+		},
+		{
+			"Performing upkeeps is protected",
+			"POST",
+			"/registries/1/upkeeps/0/perform",
+			true,
+		},
 	}
 
 	srv := &HttpService{
@@ -234,6 +1139,14 @@ func TestValidateKeeperRequest_Error(t *testing.T) {
 				Address: "0x1234",
 			},
 		},
+		{ // invalid TurnTaking
+			JobID: models.NewID().String(),
+			Params: blockchain.Params{
+				From:       eitest.NewAddress().Hex(),
+				Address:    eitest.NewAddress().Hex(),
+				TurnTaking: "round_robin",
+			},
+		},
 	} {
 		err := validateKeeperRequest(&request)
 		require.Error(t, err)
@@ -251,3 +1164,16 @@ func TestValidateKeeperRequest_Happy(t *testing.T) {
 	err := validateKeeperRequest(&request)
 	require.NoError(t, err)
 }
+
+func TestValidateKeeperRequest_TurnTaking(t *testing.T) {
+	request := CreateSubscriptionReq{
+		JobID: models.NewID().String(),
+		Params: blockchain.Params{
+			From:       eitest.NewAddress().Hex(),
+			Address:    eitest.NewAddress().Hex(),
+			TurnTaking: string(keeper.BlockHashStrategy),
+		},
+	}
+	err := validateKeeperRequest(&request)
+	require.NoError(t, err)
+}
@@ -87,6 +87,16 @@ func TestKeeperEthIntegration(t *testing.T) {
 		ChainlinkToInitiatorSecret:    "secret",
 		Port:                          8080,
 		KeeperRegistrySyncInterval:    1 * time.Second,
+		OrphanedJobReconcileInterval:  time.Minute,
+		RunStatusPollInterval:         time.Minute,
+		RunStatusTimeout:              time.Hour,
+		TriggerDispatchInterval:       100 * time.Millisecond,
+		TriggerBackoffMin:             time.Second,
+		TriggerBackoffMax:             time.Minute,
+		RestartBackoffMin:             time.Second,
+		RestartBackoffMax:             time.Minute,
+		OrphanedJobThreshold:          10,
+		ShutdownTimeout:               time.Second,
 	}
 
 	keeperService := client.NewService(db, clMock, ethClient, config)
@@ -131,8 +141,8 @@ func TestKeeperEthIntegration(t *testing.T) {
 	// test for job run
 	chJobWasRun := make(chan struct{})
 	clMock.
-		On("TriggerJob", jobID, mock.Anything).
-		Return(nil).
+		On("TriggerJob", jobID, mock.Anything, mock.Anything, mock.Anything).
+		Return("", nil).
 		Run(func(args mock.Arguments) {
 			chJobWasRun <- struct{}{}
 		})
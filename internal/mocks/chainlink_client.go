@@ -2,23 +2,68 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	chainlink "github.com/smartcontractkit/external-initiator/chainlink"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // ChainlinkClient is an autogenerated mock type for the Client type
 type ChainlinkClient struct {
 	mock.Mock
 }
 
-// TriggerJob provides a mock function with given fields: jobId, data
-func (_m *ChainlinkClient) TriggerJob(jobId string, data []byte) error {
-	ret := _m.Called(jobId, data)
+// HasAPIToken provides a mock function with given fields: jobId
+func (_m *ChainlinkClient) HasAPIToken(jobId string) bool {
+	ret := _m.Called(jobId)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string, []byte) error); ok {
-		r0 = rf(jobId, data)
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(jobId)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(bool)
 	}
 
 	return r0
 }
+
+// RunStatus provides a mock function with given fields: jobId, jobEndpoint, runID
+func (_m *ChainlinkClient) RunStatus(jobId string, jobEndpoint chainlink.JobEndpoint, runID string) (chainlink.Run, error) {
+	ret := _m.Called(jobId, jobEndpoint, runID)
+
+	var r0 chainlink.Run
+	if rf, ok := ret.Get(0).(func(string, chainlink.JobEndpoint, string) chainlink.Run); ok {
+		r0 = rf(jobId, jobEndpoint, runID)
+	} else {
+		r0 = ret.Get(0).(chainlink.Run)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, chainlink.JobEndpoint, string) error); ok {
+		r1 = rf(jobId, jobEndpoint, runID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TriggerJob provides a mock function with given fields: jobId, jobEndpoint, idempotencyKey, data
+func (_m *ChainlinkClient) TriggerJob(jobId string, jobEndpoint chainlink.JobEndpoint, idempotencyKey string, data []byte) (string, error) {
+	ret := _m.Called(jobId, jobEndpoint, idempotencyKey, data)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, chainlink.JobEndpoint, string, []byte) string); ok {
+		r0 = rf(jobId, jobEndpoint, idempotencyKey, data)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, chainlink.JobEndpoint, string, []byte) error); ok {
+		r1 = rf(jobId, jobEndpoint, idempotencyKey, data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
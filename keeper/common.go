@@ -2,12 +2,15 @@ package keeper
 
 import (
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/smartcontractkit/external-initiator/keeper/keeper_registry_contract"
+	"github.com/smartcontractkit/external-initiator/keeper/keeper_registry_contract_1_1"
 )
 
 var UpkeepRegistryABI = mustGetABI(keeper_registry_contract.KeeperRegistryContractABI)
+var UpkeepRegistry1_1ABI = mustGetABI(keeper_registry_contract_1_1.KeeperRegistryContractABI)
 
 func mustGetABI(json string) abi.ABI {
 	abi, err := abi.JSON(strings.NewReader(json))
@@ -16,3 +19,11 @@ func mustGetABI(json string) abi.ABI {
 	}
 	return abi
 }
+
+// unixNanoToTime converts timestamps stored in atomics, leaving unset ones as the zero time
+func unixNanoToTime(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
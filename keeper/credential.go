@@ -0,0 +1,120 @@
+package keeper
+
+import (
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/smartcontractkit/chainlink/core/auth"
+	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/chainlink/core/utils"
+)
+
+// CredentialStatus is whether a Credential can be used to authenticate
+type CredentialStatus string
+
+const (
+	// CredentialActive credentials authenticate their node
+	CredentialActive CredentialStatus = "active"
+	// CredentialExpired credentials are past their expiry
+	CredentialExpired CredentialStatus = "expired"
+	// CredentialRevoked credentials were revoked, e.g. once rotated
+	CredentialRevoked CredentialStatus = "revoked"
+)
+
+// Credential is an access key and secret pair a Chainlink node authenticates
+// with to the EI, the ci credentials. Only the salted hash of the secret is
+// saved. A node can have several active credentials, so that they can be
+// rotated without downtime. Credentials without a node belong to the default node.
+type Credential struct {
+	ID           uint32  `gorm:"primary_key"`
+	NodeID       *uint32 `gorm:"default:null"`
+	AccessKey    string
+	HashedSecret string
+	Salt         string
+	// EncryptedSigningSecret is the secret encrypted with the SecretKey, only kept by
+	// the credentials which verify the HMAC signatures of requests since they can't be
+	// verified against a hash
+	EncryptedSigningSecret []byte
+	ExpiresAt              *time.Time `gorm:"default:null"`
+	RevokedAt              *time.Time `gorm:"default:null"`
+	CreatedAt              time.Time
+}
+
+// ErrNoSecretKey is returned when keeping a signing secret without a SecretKey to encrypt it with
+var ErrNoSecretKey = errors.New("the secrets key is required to keep the signing secret")
+
+func (Credential) TableName() string {
+	return "access_credentials"
+}
+
+// NewCredential returns the credential for the node with the secret salted and hashed,
+// a nil expiresAt never expires
+func NewCredential(nodeID *uint32, accessKey, secret string, expiresAt *time.Time) (Credential, error) {
+	if accessKey == "" || secret == "" {
+		return Credential{}, errors.New("the access key and secret are required")
+	}
+	salt := utils.NewSecret(utils.DefaultSecretSize)
+	hashedSecret, err := auth.HashedSecret(&auth.Token{AccessKey: accessKey, Secret: secret}, salt)
+	if err != nil {
+		return Credential{}, err
+	}
+	return Credential{
+		NodeID:       nodeID,
+		AccessKey:    accessKey,
+		HashedSecret: hashedSecret,
+		Salt:         salt,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// SetSigningSecret keeps the secret encrypted with the key, to verify signed requests.
+// The encrypted secret is bound to the access key, so that it can't be moved to another credential.
+func (c *Credential) SetSigningSecret(key *SecretKey, secret string) error {
+	if key == nil {
+		return ErrNoSecretKey
+	}
+	encrypted, err := key.Encrypt(secret, []byte(c.AccessKey))
+	if err != nil {
+		return err
+	}
+	c.EncryptedSigningSecret = encrypted
+	return nil
+}
+
+// SigningSecret decrypts the secret kept to verify signed requests, it returns
+// false if the credential doesn't keep it or it can't be decrypted with the key
+func (c Credential) SigningSecret(key *SecretKey) (string, bool) {
+	if key == nil || len(c.EncryptedSigningSecret) == 0 {
+		return "", false
+	}
+	secret, err := key.Decrypt(c.EncryptedSigningSecret, []byte(c.AccessKey))
+	if err != nil {
+		logger.Errorw("Unable to decrypt the signing secret", "accessKey", c.AccessKey, "error", err)
+		return "", false
+	}
+	return secret, true
+}
+
+// Status returns whether the credential is active, expired or revoked at the time
+func (c Credential) Status(now time.Time) CredentialStatus {
+	switch {
+	case c.RevokedAt != nil:
+		return CredentialRevoked
+	case c.ExpiresAt != nil && !now.Before(*c.ExpiresAt):
+		return CredentialExpired
+	default:
+		return CredentialActive
+	}
+}
+
+// Authenticate returns whether the credential is active and the secret matches
+// its hash, the hashes are compared in constant time
+func (c Credential) Authenticate(secret string, now time.Time) bool {
+	hashedSecret, err := auth.HashedSecret(&auth.Token{AccessKey: c.AccessKey, Secret: secret}, c.Salt)
+	if err != nil {
+		return false
+	}
+	matches := subtle.ConstantTimeCompare([]byte(hashedSecret), []byte(c.HashedSecret)) == 1
+	return matches && c.Status(now) == CredentialActive
+}
@@ -0,0 +1,67 @@
+package keeper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCredential(t *testing.T) {
+	credential, err := NewCredential(nil, "key", "secret", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "key", credential.AccessKey)
+	assert.NotEmpty(t, credential.Salt)
+	assert.NotContains(t, credential.HashedSecret, "secret")
+
+	other, err := NewCredential(nil, "key", "secret", nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, credential.HashedSecret, other.HashedSecret, "hashes are salted")
+
+	_, err = NewCredential(nil, "key", "", nil)
+	assert.Error(t, err)
+	_, err = NewCredential(nil, "", "secret", nil)
+	assert.Error(t, err)
+}
+
+func TestCredential_Authenticate(t *testing.T) {
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+	credential, err := NewCredential(nil, "key", "secret", &expiresAt)
+	require.NoError(t, err)
+
+	assert.True(t, credential.Authenticate("secret", now))
+	assert.False(t, credential.Authenticate("wrong", now))
+	assert.False(t, credential.Authenticate("", now))
+	assert.Equal(t, CredentialActive, credential.Status(now))
+
+	assert.False(t, credential.Authenticate("secret", expiresAt))
+	assert.Equal(t, CredentialExpired, credential.Status(expiresAt))
+
+	credential.RevokedAt = &now
+	assert.False(t, credential.Authenticate("secret", now))
+	assert.Equal(t, CredentialRevoked, credential.Status(now))
+}
+
+func TestCredential_SigningSecret(t *testing.T) {
+	key, err := ParseSecretKey(testSecretsKey)
+	require.NoError(t, err)
+	credential, err := NewCredential(nil, "key", "secret", nil)
+	require.NoError(t, err)
+
+	_, ok := credential.SigningSecret(key)
+	assert.False(t, ok, "not kept")
+	assert.Equal(t, ErrNoSecretKey, credential.SetSigningSecret(nil, "secret"))
+
+	require.NoError(t, credential.SetSigningSecret(key, "secret"))
+	secret, ok := credential.SigningSecret(key)
+	require.True(t, ok)
+	assert.Equal(t, "secret", secret)
+	_, ok = credential.SigningSecret(nil)
+	assert.False(t, ok)
+
+	moved := Credential{AccessKey: "otherKey", EncryptedSigningSecret: credential.EncryptedSigningSecret}
+	_, ok = moved.SigningSecret(key)
+	assert.False(t, ok, "bound to the access key")
+}
@@ -0,0 +1,158 @@
+package keeper
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/smartcontractkit/chainlink/core/utils"
+	"github.com/smartcontractkit/external-initiator/chainlink"
+)
+
+const (
+	// MaxGasBuffer caps the gas added to an upkeep's execute gas for its perform transaction
+	MaxGasBuffer = uint32(5_000_000)
+	// MaxCheckConfirmations caps the confirmations to wait before checking, as
+	// older blocks are pruned by non-archive nodes
+	MaxCheckConfirmations = uint32(64)
+)
+
+// JobOptions are the per-job settings of a Membership, set through the job params.
+// A nil GasBuffer or MaxGasPrice falls back to the default buffer and no gas price cap,
+// an empty PayloadTemplate to the preformatted run payload and an empty JobEndpoint
+// to the endpoint configured for the Chainlink node.
+type JobOptions struct {
+	GasBuffer          *uint32
+	MaxGasPrice        *utils.Big
+	AllowedUpkeeps     UpkeepIDs
+	DeniedUpkeeps      UpkeepIDs
+	DryRun             bool
+	CheckConfirmations uint32
+	PayloadTemplate    PayloadTemplate
+	JobEndpoint        chainlink.JobEndpoint
+}
+
+// Validate returns an error if the options can't be honored
+func (o JobOptions) Validate() error {
+	if o.GasBuffer != nil && *o.GasBuffer > MaxGasBuffer {
+		return fmt.Errorf("gasBuffer can't be greater than %d", MaxGasBuffer)
+	}
+	if o.MaxGasPrice != nil && o.MaxGasPrice.ToInt().Sign() <= 0 {
+		return errors.New("maxGasPrice must be positive")
+	}
+	if len(o.AllowedUpkeeps) > 0 && len(o.DeniedUpkeeps) > 0 {
+		return errors.New("allowedUpkeeps and deniedUpkeeps can't both be set")
+	}
+	if o.CheckConfirmations > MaxCheckConfirmations {
+		return fmt.Errorf("checkConfirmations can't be greater than %d", MaxCheckConfirmations)
+	}
+	if o.JobEndpoint != "" {
+		if _, err := chainlink.ParseJobEndpoint(string(o.JobEndpoint)); err != nil {
+			return err
+		}
+	}
+	return o.PayloadTemplate.Validate()
+}
+
+// Equal returns whether the options are the same once stored, i.e. empty upkeep
+// lists equal nil ones and payload templates are compared as JSON
+func (o JobOptions) Equal(other JobOptions) bool {
+	if (o.GasBuffer == nil) != (other.GasBuffer == nil) || (o.GasBuffer != nil && *o.GasBuffer != *other.GasBuffer) {
+		return false
+	}
+	if (o.MaxGasPrice == nil) != (other.MaxGasPrice == nil) || (o.MaxGasPrice != nil && o.MaxGasPrice.ToInt().Cmp(other.MaxGasPrice.ToInt()) != 0) {
+		return false
+	}
+	return o.AllowedUpkeeps.equal(other.AllowedUpkeeps) &&
+		o.DeniedUpkeeps.equal(other.DeniedUpkeeps) &&
+		o.DryRun == other.DryRun &&
+		o.CheckConfirmations == other.CheckConfirmations &&
+		o.JobEndpoint == other.JobEndpoint &&
+		o.PayloadTemplate.equal(other.PayloadTemplate)
+}
+
+// PerformGasLimit returns the gas limit of the perform transaction for an upkeep
+func (o JobOptions) PerformGasLimit(executeGas uint32) uint32 {
+	if o.GasBuffer == nil {
+		return executeGas + gasBuffer
+	}
+	return executeGas + *o.GasBuffer
+}
+
+// AllowsUpkeep returns whether the upkeep passes the allowlist or denylist
+func (o JobOptions) AllowsUpkeep(upkeepID uint64) bool {
+	if len(o.AllowedUpkeeps) > 0 {
+		return o.AllowedUpkeeps.Contains(upkeepID)
+	}
+	return !o.DeniedUpkeeps.Contains(upkeepID)
+}
+
+// AllowsGasPrice returns whether an upkeep can be performed at the gas price
+// used by the registry, as returned by checkUpkeep
+func (o JobOptions) AllowsGasPrice(gasWei *big.Int) bool {
+	if o.MaxGasPrice == nil || gasWei == nil {
+		return true
+	}
+	return gasWei.Cmp(o.MaxGasPrice.ToInt()) <= 0
+}
+
+// CheckBlockNumber returns the block to call checkUpkeep at for a head, nil meaning the latest block
+func (o JobOptions) CheckBlockNumber(headNumber int64) *big.Int {
+	if o.CheckConfirmations == 0 {
+		return nil
+	}
+	blockNumber := headNumber - int64(o.CheckConfirmations)
+	if blockNumber < 0 {
+		blockNumber = 0
+	}
+	return big.NewInt(blockNumber)
+}
+
+// UpkeepIDs is a list of upkeep IDs stored as JSON
+type UpkeepIDs []uint64
+
+func (ids UpkeepIDs) equal(other UpkeepIDs) bool {
+	if len(ids) != len(other) {
+		return false
+	}
+	for i := range ids {
+		if ids[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (ids UpkeepIDs) Contains(upkeepID uint64) bool {
+	for _, id := range ids {
+		if id == upkeepID {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the IDs serialized for database storage, an empty list is stored as NULL
+func (ids UpkeepIDs) Value() (driver.Value, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(ids)
+}
+
+// Scan reads the IDs from the database
+func (ids *UpkeepIDs) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*ids = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, ids)
+	case string:
+		return json.Unmarshal([]byte(v), ids)
+	default:
+		return fmt.Errorf("unable to convert %v of %T to UpkeepIDs", value, value)
+	}
+}
@@ -0,0 +1,111 @@
+package keeper
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/smartcontractkit/chainlink/core/utils"
+	"github.com/smartcontractkit/external-initiator/chainlink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobOptions_Validate(t *testing.T) {
+	tooMuchGas := MaxGasBuffer + 1
+	for _, test := range []struct {
+		name    string
+		options JobOptions
+		wantErr bool
+	}{
+		{"defaults", JobOptions{}, false},
+		{"gas buffer too high", JobOptions{GasBuffer: &tooMuchGas}, true},
+		{"zero max gas price", JobOptions{MaxGasPrice: utils.NewBigI(0)}, true},
+		{"max gas price", JobOptions{MaxGasPrice: utils.NewBigI(100)}, false},
+		{"allowlist and denylist", JobOptions{AllowedUpkeeps: UpkeepIDs{1}, DeniedUpkeeps: UpkeepIDs{2}}, true},
+		{"too many confirmations", JobOptions{CheckConfirmations: MaxCheckConfirmations + 1}, true},
+		{"webhook job endpoint", JobOptions{JobEndpoint: chainlink.WebhookJobEndpoint}, false},
+		{"unknown job endpoint", JobOptions{JobEndpoint: "v3"}, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.options.Validate()
+			assert.Equal(t, test.wantErr, err != nil, "%v", err)
+		})
+	}
+}
+
+func TestJobOptions_Equal(t *testing.T) {
+	gasBuffer, otherGasBuffer := uint32(1000), uint32(1000)
+	options := JobOptions{
+		GasBuffer:       &gasBuffer,
+		MaxGasPrice:     utils.NewBigI(100),
+		AllowedUpkeeps:  UpkeepIDs{1, 2},
+		PayloadTemplate: PayloadTemplate(`{"upkeep": "{{.UpkeepID}}", "from": "{{.From}}"}`),
+	}
+	same := JobOptions{
+		GasBuffer:       &otherGasBuffer,
+		MaxGasPrice:     utils.NewBigI(100),
+		AllowedUpkeeps:  UpkeepIDs{1, 2},
+		DeniedUpkeeps:   UpkeepIDs{},
+		PayloadTemplate: PayloadTemplate(`{"from":"{{.From}}","upkeep":"{{.UpkeepID}}"}`),
+	}
+	assert.True(t, options.Equal(same))
+	assert.True(t, JobOptions{}.Equal(JobOptions{}))
+
+	for name, change := range map[string]func(o *JobOptions){
+		"gas buffer":       func(o *JobOptions) { o.GasBuffer = nil },
+		"max gas price":    func(o *JobOptions) { o.MaxGasPrice = utils.NewBigI(200) },
+		"allowed upkeeps":  func(o *JobOptions) { o.AllowedUpkeeps = UpkeepIDs{1} },
+		"dry run":          func(o *JobOptions) { o.DryRun = true },
+		"job endpoint":     func(o *JobOptions) { o.JobEndpoint = chainlink.WebhookJobEndpoint },
+		"payload template": func(o *JobOptions) { o.PayloadTemplate = nil },
+	} {
+		changed := same
+		change(&changed)
+		assert.False(t, options.Equal(changed), name)
+	}
+}
+
+func TestJobOptions_AllowsUpkeep(t *testing.T) {
+	assert.True(t, JobOptions{}.AllowsUpkeep(1))
+
+	allowed := JobOptions{AllowedUpkeeps: UpkeepIDs{1, 2}}
+	assert.True(t, allowed.AllowsUpkeep(2))
+	assert.False(t, allowed.AllowsUpkeep(3))
+
+	denied := JobOptions{DeniedUpkeeps: UpkeepIDs{1}}
+	assert.False(t, denied.AllowsUpkeep(1))
+	assert.True(t, denied.AllowsUpkeep(3))
+}
+
+func TestJobOptions_Gas(t *testing.T) {
+	assert.Equal(t, 10_000+gasBuffer, JobOptions{}.PerformGasLimit(10_000))
+	buffer := uint32(50_000)
+	assert.Equal(t, uint32(60_000), JobOptions{GasBuffer: &buffer}.PerformGasLimit(10_000))
+
+	assert.True(t, JobOptions{}.AllowsGasPrice(big.NewInt(1_000)))
+	capped := JobOptions{MaxGasPrice: utils.NewBigI(100)}
+	assert.True(t, capped.AllowsGasPrice(big.NewInt(100)))
+	assert.False(t, capped.AllowsGasPrice(big.NewInt(101)))
+}
+
+func TestJobOptions_CheckBlockNumber(t *testing.T) {
+	assert.Nil(t, JobOptions{}.CheckBlockNumber(100))
+	assert.Equal(t, big.NewInt(97), JobOptions{CheckConfirmations: 3}.CheckBlockNumber(100))
+	assert.Equal(t, big.NewInt(0), JobOptions{CheckConfirmations: 3}.CheckBlockNumber(2))
+}
+
+func TestUpkeepIDs_ValueAndScan(t *testing.T) {
+	value, err := UpkeepIDs{}.Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	value, err = UpkeepIDs{1, 5}.Value()
+	require.NoError(t, err)
+
+	var ids UpkeepIDs
+	require.NoError(t, ids.Scan(value))
+	assert.Equal(t, UpkeepIDs{1, 5}, ids)
+	require.NoError(t, ids.Scan(nil))
+	assert.Nil(t, ids)
+	assert.Error(t, ids.Scan(42))
+}
@@ -0,0 +1,152 @@
+package keeper
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/chainlink/core/store/models"
+	"github.com/smartcontractkit/external-initiator/chainlink"
+	"go.uber.org/atomic"
+)
+
+var promOrphanedJobs = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "keeper_orphaned_jobs",
+	Help: "The number of jobs found to no longer exist on the Chainlink node, by action taken",
+}, []string{"action"})
+
+const (
+	orphanActionMarked  = "marked"
+	orphanActionDeleted = "deleted"
+)
+
+// JobReconciler detects jobs which were deleted on the Chainlink node without the
+// EI being notified. It wraps the Chainlink client to count the consecutive job run
+// triggers answered with a 404 or 410, and periodically marks the memberships of
+// jobs reaching the threshold as orphaned, or deletes them if autoDelete is set.
+type JobReconciler interface {
+	chainlink.Client
+	Start() error
+	Stop()
+	// Err receives the error the reconciler failed with while running
+	Err() <-chan error
+}
+
+func NewJobReconciler(keeperStore Store, clNode chainlink.Client, interval time.Duration, threshold uint, autoDelete bool) JobReconciler {
+	return &jobReconciler{
+		Client:      clNode,
+		keeperStore: keeperStore,
+		interval:    interval,
+		threshold:   threshold,
+		autoDelete:  autoDelete,
+		notFound:    make(map[string]uint),
+		isRunning:   atomic.NewBool(false),
+		lifecycle:   newLifecycle(),
+	}
+}
+
+type jobReconciler struct {
+	chainlink.Client
+	keeperStore Store
+	interval    time.Duration
+	threshold   uint
+	autoDelete  bool
+	isRunning   *atomic.Bool
+
+	// consecutive not found responses by job ID
+	notFound   map[string]uint
+	notFoundMu sync.Mutex
+
+	*lifecycle
+}
+
+func (jr *jobReconciler) Start() error {
+	if jr.isRunning.Load() {
+		return errors.New("already started")
+	}
+	jr.restart()
+	jr.isRunning.Store(true)
+	jr.spawn(jr.run)
+	return nil
+}
+
+func (jr *jobReconciler) Stop() {
+	jr.stop()
+	jr.isRunning.Store(false)
+}
+
+// TriggerJob triggers the job run on the Chainlink node and records whether the job was found
+func (jr *jobReconciler) TriggerJob(jobID string, jobEndpoint chainlink.JobEndpoint, idempotencyKey string, data []byte) (string, error) {
+	runID, err := jr.Client.TriggerJob(jobID, jobEndpoint, idempotencyKey, data)
+
+	jr.notFoundMu.Lock()
+	defer jr.notFoundMu.Unlock()
+	if chainlink.IsJobNotFound(err) {
+		jr.notFound[jobID]++
+	} else if err == nil {
+		delete(jr.notFound, jobID)
+	}
+	return runID, err
+}
+
+func (jr *jobReconciler) run() {
+	ticker := time.NewTicker(jr.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-jr.chDone:
+			return
+		case <-ticker.C:
+			jr.reconcile()
+		}
+	}
+}
+
+// reconcile marks or deletes the memberships of the jobs which
+// were not found on the Chainlink node threshold times in a row
+func (jr *jobReconciler) reconcile() {
+	for _, jobID := range jr.orphanedJobIDs() {
+		id, err := models.NewIDFromString(jobID)
+		if err != nil {
+			logger.Error(err)
+			continue
+		}
+
+		if jr.autoDelete {
+			logger.Warnw("Deleting job which no longer exists on the Chainlink node", "jobID", jobID)
+			err = jr.keeperStore.DeleteMembershipByJobID(id)
+			if err == nil {
+				promOrphanedJobs.WithLabelValues(orphanActionDeleted).Inc()
+			}
+		} else {
+			logger.Warnw("Marking job which no longer exists on the Chainlink node as orphaned", "jobID", jobID)
+			err = jr.keeperStore.SetMembershipOrphaned(id, true)
+			if err == nil {
+				promOrphanedJobs.WithLabelValues(orphanActionMarked).Inc()
+			}
+		}
+		if err != nil && !gorm.IsRecordNotFoundError(err) {
+			logger.Errorf("unable to reconcile job %s: %v", jobID, err)
+		}
+	}
+}
+
+// orphanedJobIDs returns the jobs which reached the threshold and resets their count
+func (jr *jobReconciler) orphanedJobIDs() []string {
+	jr.notFoundMu.Lock()
+	defer jr.notFoundMu.Unlock()
+
+	var jobIDs []string
+	for jobID, count := range jr.notFound {
+		if count >= jr.threshold {
+			jobIDs = append(jobIDs, jobID)
+			delete(jr.notFound, jobID)
+		}
+	}
+	return jobIDs
+}
@@ -0,0 +1,75 @@
+package keeper
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/external-initiator/chainlink"
+	"github.com/smartcontractkit/external-initiator/eitest"
+	"github.com/smartcontractkit/external-initiator/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupJobReconciler(t *testing.T, autoDelete bool) (*jobReconciler, Membership, *mocks.ChainlinkClient, func()) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	_, membership := createRegistry(t, db, newRegistry())
+	clMock := new(mocks.ChainlinkClient)
+	reconciler := NewJobReconciler(regStore, clMock, time.Hour, 2, autoDelete).(*jobReconciler)
+	return reconciler, membership, clMock, cleanup
+}
+
+func Test_JobReconciler_MarksOrphanedJobs(t *testing.T) {
+	reconciler, membership, clMock, cleanup := setupJobReconciler(t, false)
+	defer cleanup()
+	jobID := membership.JobID.String()
+	notFound := chainlink.StatusCodeError{StatusCode: http.StatusNotFound}
+
+	t.Run("a successful trigger resets the count", func(t *testing.T) {
+		clMock.On("TriggerJob", jobID, mock.Anything, mock.Anything, mock.Anything).Return("", notFound).Once()
+		clMock.On("TriggerJob", jobID, mock.Anything, mock.Anything, mock.Anything).Return("", nil).Once()
+		clMock.On("TriggerJob", jobID, mock.Anything, mock.Anything, mock.Anything).Return("", notFound).Once()
+		for i := 0; i < 3; i++ {
+			_, _ = reconciler.TriggerJob(jobID, "", "", nil)
+		}
+
+		reconciler.reconcile()
+		found, err := reconciler.keeperStore.MembershipByJobID(membership.JobID)
+		require.NoError(t, err)
+		assert.False(t, found.Orphaned)
+	})
+
+	t.Run("marks the job after consecutive not found responses", func(t *testing.T) {
+		clMock.On("TriggerJob", jobID, mock.Anything, mock.Anything, mock.Anything).Return("", chainlink.StatusCodeError{StatusCode: http.StatusGone}).Once()
+		_, err := reconciler.TriggerJob(jobID, "", "", nil)
+		require.Error(t, err)
+
+		reconciler.reconcile()
+		found, err := reconciler.keeperStore.MembershipByJobID(membership.JobID)
+		require.NoError(t, err)
+		assert.True(t, found.Orphaned)
+	})
+
+	clMock.AssertExpectations(t)
+}
+
+func Test_JobReconciler_DeletesOrphanedJobs(t *testing.T) {
+	reconciler, membership, clMock, cleanup := setupJobReconciler(t, true)
+	defer cleanup()
+	jobID := membership.JobID.String()
+
+	clMock.
+		On("TriggerJob", jobID, mock.Anything, mock.Anything, mock.Anything).
+		Return("", chainlink.StatusCodeError{StatusCode: http.StatusNotFound}).
+		Twice()
+	for i := 0; i < 2; i++ {
+		_, _ = reconciler.TriggerJob(jobID, "", "", nil)
+	}
+
+	reconciler.reconcile()
+	eitest.AssertCount(t, reconciler.keeperStore.DB(), Membership{}, 0)
+	eitest.AssertCount(t, reconciler.keeperStore.DB(), Registry{}, 0)
+	clMock.AssertExpectations(t)
+}
@@ -0,0 +1,33 @@
+package keeper
+
+import (
+	"time"
+
+	"github.com/smartcontractkit/external-initiator/chainlink"
+)
+
+// RunStatusTimedOut is the status of job runs which didn't reach a terminal
+// state on the Chainlink node within the poller's timeout
+const RunStatusTimedOut chainlink.RunStatus = "timed_out"
+
+// JobRun is a job run triggered on the Chainlink node to perform an upkeep,
+// its status is pending until the RunPoller finds it in a terminal state
+type JobRun struct {
+	ID           uint32 `gorm:"primary_key"`
+	MembershipID uint32
+	Membership   Membership `gorm:"association_autoupdate:false;association_autocreate:false"`
+	UpkeepID     uint64
+	BlockNumber  int64
+	RunID        string
+	Status       chainlink.RunStatus
+	TxHash       string
+	Error        string
+	// LastPolledAt is nil until the status is first polled from the Chainlink node
+	LastPolledAt *time.Time `gorm:"default:null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (JobRun) TableName() string {
+	return "keeper_job_runs"
+}
@@ -0,0 +1,70 @@
+package keeper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/smartcontractkit/chainlink/core/store/models"
+)
+
+// ErrJobTriggerExists is returned when a job trigger with the same
+// idempotency key was already written to the outbox
+var ErrJobTriggerExists = errors.New("job trigger already exists for the idempotency key")
+
+// TriggerStatus is the delivery state of a job run trigger in the outbox
+type TriggerStatus string
+
+const (
+	// TriggerPending triggers are waiting to be sent, possibly after failed attempts
+	TriggerPending TriggerStatus = "pending"
+	// TriggerSent triggers were accepted by the Chainlink node
+	TriggerSent TriggerStatus = "sent"
+	// TriggerDead triggers expired or were rejected, they are only sent again when replayed
+	TriggerDead TriggerStatus = "dead"
+)
+
+// JobTrigger is a job run trigger written to the outbox by the UpkeepExecuter
+// and sent to the Chainlink node by the TriggerDispatcher. Pending triggers
+// become dead letters once the ExpiresAtBlock is reached, zero meaning never.
+// The IdempotencyKey is unique, so an upkeep is only queued once per block
+// unless it is performed manually.
+type JobTrigger struct {
+	ID             uint32 `gorm:"primary_key"`
+	MembershipID   uint32
+	Membership     Membership `gorm:"association_autoupdate:false;association_autocreate:false"`
+	UpkeepID       uint64
+	BlockNumber    int64
+	IdempotencyKey string
+	Payload        []byte
+	Status         TriggerStatus
+	Attempts       uint32
+	NextAttemptAt  time.Time
+	ExpiresAtBlock int64
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (JobTrigger) TableName() string {
+	return "keeper_job_triggers"
+}
+
+// Expired returns whether the trigger can no longer be sent at the block number
+func (trigger JobTrigger) Expired(blockNumber int64) bool {
+	return trigger.ExpiresAtBlock > 0 && blockNumber >= trigger.ExpiresAtBlock
+}
+
+// idempotencyKey returns the key identifying the perform of the upkeep on the
+// registry at the block, it is sent with every attempt to trigger the job run
+func idempotencyKey(registry common.Address, upkeepID uint64, blockNumber int64) string {
+	return fmt.Sprintf("%s-%d-%d", strings.ToLower(registry.Hex()), upkeepID, blockNumber)
+}
+
+// manualIdempotencyKey returns a key unique to a manual perform of the upkeep, which
+// is queued even if the perform for the block was already queued or sent
+func manualIdempotencyKey(registry common.Address, upkeepID uint64, blockNumber int64) string {
+	return fmt.Sprintf("%s-manual-%s", idempotencyKey(registry, upkeepID, blockNumber), models.NewID().String())
+}
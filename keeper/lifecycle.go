@@ -0,0 +1,80 @@
+package keeper
+
+import (
+	"sync"
+)
+
+// lifecycle tracks the goroutines of a background service, so that the
+// service can be stopped more than once, or started again once stopped,
+// and its Stop waits for them to return
+type lifecycle struct {
+	// chDone is closed when the service is stopped
+	chDone   chan struct{}
+	stopOnce *sync.Once
+	wg       *sync.WaitGroup
+	// chErr holds the error the service failed with while running
+	chErr chan error
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{
+		chDone:   make(chan struct{}),
+		stopOnce: &sync.Once{},
+		wg:       &sync.WaitGroup{},
+		chErr:    make(chan error, 1),
+	}
+}
+
+// spawn runs fn in a goroutine which stop waits for, fn must return once chDone is closed
+func (lc *lifecycle) spawn(fn func()) {
+	lc.wg.Add(1)
+	go func() {
+		defer lc.wg.Done()
+		fn()
+	}()
+}
+
+// stop closes chDone, unless it is already closed, and waits for the spawned goroutines
+func (lc *lifecycle) stop() {
+	lc.stopOnce.Do(func() { close(lc.chDone) })
+	lc.wg.Wait()
+}
+
+// restart reopens chDone once stopped, so that the service can spawn its goroutines
+// again, and discards the error it failed with. It must not be called concurrently
+// with stop.
+func (lc *lifecycle) restart() {
+	if lc.stopped() {
+		lc.chDone = make(chan struct{})
+		lc.stopOnce = &sync.Once{}
+		select {
+		case <-lc.chErr:
+		default:
+		}
+	}
+}
+
+// fail reports that the service can't keep running, only the first error is kept
+// until the service is restarted
+func (lc *lifecycle) fail(err error) {
+	select {
+	case lc.chErr <- err:
+	default:
+	}
+}
+
+// Err receives the error the service failed with while running, the service must
+// then be stopped, and can be started again
+func (lc *lifecycle) Err() <-chan error {
+	return lc.chErr
+}
+
+// stopped returns whether chDone is closed, for long running work to return early
+func (lc *lifecycle) stopped() bool {
+	select {
+	case <-lc.chDone:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,77 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycle_Stop(t *testing.T) {
+	lc := newLifecycle()
+	returned := make(chan struct{})
+	lc.spawn(func() {
+		<-lc.chDone
+		time.Sleep(10 * time.Millisecond)
+		close(returned)
+	})
+	assert.False(t, lc.stopped())
+
+	lc.stop()
+	assert.True(t, lc.stopped())
+	select {
+	case <-returned:
+	default:
+		t.Fatal("stop returned before the spawned goroutine")
+	}
+	assert.NotPanics(t, lc.stop, "stopping twice")
+}
+
+func TestLifecycle_Restart(t *testing.T) {
+	lc := newLifecycle()
+	lc.restart()
+	assert.False(t, lc.stopped(), "restarting a running lifecycle is a no-op")
+
+	lc.stop()
+	lc.restart()
+	assert.False(t, lc.stopped())
+
+	returned := make(chan struct{})
+	lc.spawn(func() {
+		<-lc.chDone
+		close(returned)
+	})
+	lc.stop()
+	assert.True(t, lc.stopped())
+	<-returned
+}
+
+func TestLifecycle_Fail(t *testing.T) {
+	lc := newLifecycle()
+	lc.fail(errors.New("first"))
+	lc.fail(errors.New("second"))
+	assert.EqualError(t, <-lc.Err(), "first")
+
+	lc.fail(errors.New("third"))
+	lc.stop()
+	lc.restart()
+	assert.Len(t, lc.Err(), 0, "the error is discarded once restarted")
+}
+
+func TestServices_StartAgainOnceStopped(t *testing.T) {
+	for name, service := range map[string]interface {
+		Start() error
+		Stop()
+	}{
+		"dispatcher": NewTriggerDispatcher(nil, nil, time.Hour, BackoffConfig{}, func() int64 { return 0 }),
+		"poller":     NewRunPoller(nil, nil, time.Hour, time.Hour),
+		"reconciler": NewJobReconciler(nil, nil, time.Hour, 1, false),
+	} {
+		assert.NoError(t, service.Start(), name)
+		assert.Error(t, service.Start(), name)
+		service.Stop()
+		assert.NoError(t, service.Start(), "%s started again", name)
+		service.Stop()
+	}
+}
@@ -0,0 +1,76 @@
+package keeper
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/smartcontractkit/chainlink/core/store/models"
+)
+
+// Membership is a keeper address servicing a Registry through a job on the Chainlink node.
+// Several memberships can share a registry, each with its own index in the keeper list.
+// Orphaned memberships are skipped, their job no longer exists on the Chainlink node.
+// The job belongs to the Chainlink node which created it, the default node if NodeID is nil.
+type Membership struct {
+	ID          uint32 `gorm:"primary_key"`
+	RegistryID  uint32
+	Registry    Registry       `gorm:"association_autoupdate:false;association_autocreate:false"`
+	NodeID      *uint32        `gorm:"default:null"`
+	From        common.Address `gorm:"default:null"`
+	JobID       *models.ID     `gorm:"default:null"`
+	KeeperIndex uint32
+	ReferenceID string `gorm:"default:null"`
+	Orphaned    bool
+	JobOptions
+}
+
+func NewMembership(registry Registry, from common.Address, jobID *models.ID) Membership {
+	return Membership{
+		RegistryID:  registry.ID,
+		Registry:    registry,
+		From:        from,
+		JobID:       jobID,
+		ReferenceID: models.NewID().String(),
+	}
+}
+
+func (Membership) TableName() string {
+	return "keeper_memberships"
+}
+
+// BelongsTo returns whether the job was created by the node, nil being the default node
+func (m Membership) BelongsTo(nodeID *uint32) bool {
+	if m.NodeID == nil || nodeID == nil {
+		return m.NodeID == nil && nodeID == nil
+	}
+	return *m.NodeID == *nodeID
+}
+
+// EligibleUpkeep is an upkeep which the keeper of a membership is expected to perform
+type EligibleUpkeep struct {
+	Registration Registration
+	Membership   Membership
+}
+
+// SyncKeeperIndex returns the membership with its index in the registry's keeper list
+func (m Membership) SyncKeeperIndex(keeperAddresses []common.Address) (Membership, error) {
+	for idx, address := range keeperAddresses {
+		if address == m.From {
+			m.KeeperIndex = uint32(idx)
+			return m, nil
+		}
+	}
+	return Membership{}, InvalidRegistryError{Reason: fmt.Sprintf("unable to find %s in keeper list on registry %s", m.From.Hex(), m.Registry.Address.Hex())}
+}
+
+// ValidateKeeper returns an error if the from address isn't an active keeper on the registry
+func (m Membership) ValidateKeeper(contract RegistryContract) error {
+	info, err := contract.GetKeeperInfo(m.From)
+	if err != nil {
+		return fmt.Errorf("unable to get keeper info for %s: %v", m.From.Hex(), err)
+	}
+	if !info.Active {
+		return InvalidRegistryError{Reason: fmt.Sprintf("%s is not an active keeper on registry %s", m.From.Hex(), m.Registry.Address.Hex())}
+	}
+	return nil
+}
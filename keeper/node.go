@@ -0,0 +1,51 @@
+package keeper
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultNodeName is the name of the Chainlink node configured through the
+// chainlinkurl, ic_* and ci_* flags, it isn't saved in the nodes table
+const DefaultNodeName = "default"
+
+// ErrNodeHasJobs is returned when deleting a node which jobs still belong to
+var ErrNodeHasJobs = errors.New("jobs still belong to the node, delete them first")
+
+// Node is a Chainlink node served by the EI. Its jobs are triggered with the
+// InitiatorToChainlink credentials, and the node authenticates with any of its
+// active Credentials to manage them. The status of its job runs is read with the
+// optional API token. Memberships without a node belong to the default node.
+type Node struct {
+	ID                            uint32 `gorm:"primary_key"`
+	Name                          string
+	URL                           string
+	InitiatorToChainlinkAccessKey string
+	InitiatorToChainlinkSecret    string
+	APIKey                        string
+	APISecret                     string
+	CreatedAt                     time.Time
+	UpdatedAt                     time.Time
+}
+
+func (Node) TableName() string {
+	return "chainlink_nodes"
+}
+
+// Validate returns an error if the node is missing its name, URL or ic credentials,
+// or only has half of its API token
+func (n Node) Validate() error {
+	switch {
+	case n.Name == "":
+		return errors.New("name is required")
+	case n.Name == DefaultNodeName:
+		return errors.New("the default node is configured through the chainlinkurl, ic_* and ci_* flags")
+	case n.URL == "":
+		return errors.New("url is required")
+	case n.InitiatorToChainlinkAccessKey == "" || n.InitiatorToChainlinkSecret == "":
+		return errors.New("the ic access key and secret are required")
+	case (n.APIKey == "") != (n.APISecret == ""):
+		return errors.New("the api key and secret must be set together")
+	}
+	return nil
+}
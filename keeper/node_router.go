@@ -0,0 +1,110 @@
+package keeper
+
+import (
+	"sync"
+
+	"github.com/jinzhu/gorm"
+	"github.com/smartcontractkit/chainlink/core/store/models"
+	"github.com/smartcontractkit/external-initiator/chainlink"
+)
+
+// NodeRouter is a chainlink.Client sending the requests for a job to the Chainlink
+// node which created it, with the credentials of that node. Jobs of the default
+// node, or unknown to the EI, are sent to the default node.
+type NodeRouter interface {
+	chainlink.Client
+	// CircuitBreakers returns the circuit breakers of the default node and of
+	// the nodes which requests were routed to, by node name
+	CircuitBreakers() map[string]chainlink.CircuitBreaker
+}
+
+// NodeClientFactory returns the client for the node, wrapped with its own circuit breaker
+type NodeClientFactory func(node Node) (chainlink.CircuitBreaker, error)
+
+// NewNodeRouter returns a NodeRouter creating the clients of the nodes on their first request
+func NewNodeRouter(keeperStore Store, defaultNode chainlink.CircuitBreaker, newClient NodeClientFactory) NodeRouter {
+	return &nodeRouter{
+		keeperStore: keeperStore,
+		defaultNode: defaultNode,
+		newClient:   newClient,
+		clients:     make(map[uint32]nodeClient),
+	}
+}
+
+type nodeRouter struct {
+	keeperStore Store
+	defaultNode chainlink.CircuitBreaker
+	newClient   NodeClientFactory
+
+	mu sync.Mutex
+	// clients are keyed by node ID, they are recreated once the node is updated
+	clients map[uint32]nodeClient
+}
+
+type nodeClient struct {
+	node   Node
+	client chainlink.CircuitBreaker
+}
+
+func (nr *nodeRouter) TriggerJob(jobId string, jobEndpoint chainlink.JobEndpoint, idempotencyKey string, data []byte) (string, error) {
+	client, err := nr.clientForJob(jobId)
+	if err != nil {
+		return "", err
+	}
+	return client.TriggerJob(jobId, jobEndpoint, idempotencyKey, data)
+}
+
+func (nr *nodeRouter) RunStatus(jobId string, jobEndpoint chainlink.JobEndpoint, runID string) (chainlink.Run, error) {
+	client, err := nr.clientForJob(jobId)
+	if err != nil {
+		return chainlink.Run{}, err
+	}
+	return client.RunStatus(jobId, jobEndpoint, runID)
+}
+
+// HasAPIToken returns false if the node of the job can't be found
+func (nr *nodeRouter) HasAPIToken(jobId string) bool {
+	client, err := nr.clientForJob(jobId)
+	if err != nil {
+		return false
+	}
+	return client.HasAPIToken(jobId)
+}
+
+func (nr *nodeRouter) CircuitBreakers() map[string]chainlink.CircuitBreaker {
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+
+	breakers := map[string]chainlink.CircuitBreaker{DefaultNodeName: nr.defaultNode}
+	for _, nc := range nr.clients {
+		breakers[nc.node.Name] = nc.client
+	}
+	return breakers
+}
+
+func (nr *nodeRouter) clientForJob(jobId string) (chainlink.Client, error) {
+	jobID, err := models.NewIDFromString(jobId)
+	if err != nil {
+		return nil, err
+	}
+	node, err := nr.keeperStore.NodeByJobID(jobID)
+	if gorm.IsRecordNotFoundError(err) {
+		return nr.defaultNode, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+
+	nc, ok := nr.clients[node.ID]
+	if ok && nc.node.UpdatedAt.Equal(node.UpdatedAt) {
+		return nc.client, nil
+	}
+	client, err := nr.newClient(node)
+	if err != nil {
+		return nil, err
+	}
+	nr.clients[node.ID] = nodeClient{node: node, client: client}
+	return client, nil
+}
@@ -0,0 +1,110 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/chainlink/core/store/models"
+	"github.com/smartcontractkit/external-initiator/chainlink"
+	"github.com/smartcontractkit/external-initiator/eitest"
+	"github.com/smartcontractkit/external-initiator/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NodeRouter_RoutesJobsToTheirNode(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+	reg, membership := createRegistry(t, db, newRegistry())
+	node := createNode(t, db, "other")
+	otherMembership := NewMembership(reg, eitest.NewAddress(), models.NewID())
+	otherMembership.NodeID = &node.ID
+	require.NoError(t, db.Create(&otherMembership).Error)
+
+	defaultMock := new(mocks.ChainlinkClient)
+	otherMock := new(mocks.ChainlinkClient)
+	created := 0
+	router := NewNodeRouter(regStore, chainlink.NewCircuitBreaker(DefaultNodeName, defaultMock, 0, time.Hour), func(n Node) (chainlink.CircuitBreaker, error) {
+		assert.Equal(t, node.ID, n.ID)
+		created++
+		return chainlink.NewCircuitBreaker(n.Name, otherMock, 0, time.Hour), nil
+	})
+
+	defaultMock.On("TriggerJob", membership.JobID.String(), mock.Anything, "a", mock.Anything).Return("1", nil).Once()
+	runID, err := router.TriggerJob(membership.JobID.String(), "", "a", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "1", runID)
+
+	otherMock.On("TriggerJob", otherMembership.JobID.String(), mock.Anything, "b", mock.Anything).Return("2", nil).Once()
+	otherMock.On("RunStatus", otherMembership.JobID.String(), mock.Anything, "2").Return(chainlink.Run{Status: chainlink.RunStatusCompleted}, nil).Once()
+	runID, err = router.TriggerJob(otherMembership.JobID.String(), "", "b", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2", runID)
+	run, err := router.RunStatus(otherMembership.JobID.String(), "", "2")
+	require.NoError(t, err)
+	assert.Equal(t, chainlink.RunStatusCompleted, run.Status)
+	assert.Equal(t, 1, created)
+
+	// jobs unknown to the EI go to the default node
+	unknownJobID := models.NewID().String()
+	defaultMock.On("TriggerJob", unknownJobID, mock.Anything, "c", mock.Anything).Return("", nil).Once()
+	_, err = router.TriggerJob(unknownJobID, "", "c", nil)
+	require.NoError(t, err)
+
+	defaultMock.AssertExpectations(t)
+	otherMock.AssertExpectations(t)
+
+	breakers := router.CircuitBreakers()
+	assert.Len(t, breakers, 2)
+	assert.Contains(t, breakers, DefaultNodeName)
+	assert.Contains(t, breakers, "other")
+}
+
+func Test_NodeRouter_RecreatesClientOnceNodeUpdated(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+	reg, _ := createRegistry(t, db, newRegistry())
+	node := createNode(t, db, "other")
+	membership := NewMembership(reg, eitest.NewAddress(), models.NewID())
+	membership.NodeID = &node.ID
+	require.NoError(t, db.Create(&membership).Error)
+
+	clMock := new(mocks.ChainlinkClient)
+	clMock.On("TriggerJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("", nil)
+	var secrets []string
+	router := NewNodeRouter(regStore, nil, func(n Node) (chainlink.CircuitBreaker, error) {
+		secrets = append(secrets, n.InitiatorToChainlinkSecret)
+		return chainlink.NewCircuitBreaker(n.Name, clMock, 0, time.Hour), nil
+	})
+
+	_, err := router.TriggerJob(membership.JobID.String(), "", "a", nil)
+	require.NoError(t, err)
+	_, err = router.TriggerJob(membership.JobID.String(), "", "b", nil)
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&node).Update("initiator_to_chainlink_secret", "rotated").Error)
+	_, err = router.TriggerJob(membership.JobID.String(), "", "c", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"other-ic-secret", "rotated"}, secrets)
+}
+
+func Test_NodeRouter_ReturnsClientErrors(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+	reg, _ := createRegistry(t, db, newRegistry())
+	node := createNode(t, db, "other")
+	membership := NewMembership(reg, eitest.NewAddress(), models.NewID())
+	membership.NodeID = &node.ID
+	require.NoError(t, db.Create(&membership).Error)
+
+	router := NewNodeRouter(regStore, nil, func(n Node) (chainlink.CircuitBreaker, error) {
+		return nil, errors.New("invalid url")
+	})
+
+	_, err := router.TriggerJob(membership.JobID.String(), "", "a", nil)
+	assert.EqualError(t, err, "invalid url")
+	_, err = router.TriggerJob("invalid", "", "a", nil)
+	assert.Error(t, err)
+}
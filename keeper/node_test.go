@@ -0,0 +1,45 @@
+package keeper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNode_Validate(t *testing.T) {
+	valid := Node{
+		Name:                          "other",
+		URL:                           "http://other:6688",
+		InitiatorToChainlinkAccessKey: "ic-key",
+		InitiatorToChainlinkSecret:    "ic-secret",
+	}
+	assert.NoError(t, valid.Validate())
+	withToken := valid
+	withToken.APIKey, withToken.APISecret = "api-key", "api-secret"
+	assert.NoError(t, withToken.Validate())
+
+	for name, update := range map[string]func(*Node){
+		"missing name":       func(n *Node) { n.Name = "" },
+		"default name":       func(n *Node) { n.Name = DefaultNodeName },
+		"missing url":        func(n *Node) { n.URL = "" },
+		"missing ic key":     func(n *Node) { n.InitiatorToChainlinkAccessKey = "" },
+		"missing ic secret":  func(n *Node) { n.InitiatorToChainlinkSecret = "" },
+		"missing api secret": func(n *Node) { n.APIKey = "api-key" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			node := valid
+			update(&node)
+			assert.Error(t, node.Validate())
+		})
+	}
+}
+
+func TestMembership_BelongsTo(t *testing.T) {
+	one, two := uint32(1), uint32(2)
+
+	assert.True(t, Membership{}.BelongsTo(nil))
+	assert.False(t, Membership{}.BelongsTo(&one))
+	assert.False(t, Membership{NodeID: &one}.BelongsTo(nil))
+	assert.False(t, Membership{NodeID: &one}.BelongsTo(&two))
+	assert.True(t, Membership{NodeID: &one}.BelongsTo(&one))
+}
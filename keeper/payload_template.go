@@ -0,0 +1,165 @@
+package keeper
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"text/template"
+)
+
+// MaxPayloadTemplateSize caps the size of a job's payload template
+const MaxPayloadTemplateSize = 16 * 1024
+
+// PayloadData is the data available to payload templates. PerformData,
+// FunctionSelector and PerformArgs are hex encoded with a 0x prefix,
+// PerformArgs being the ABI encoded arguments of the performUpkeep call.
+type PayloadData struct {
+	UpkeepID         uint64
+	PerformData      string
+	Registry         string
+	From             string
+	GasLimit         uint32
+	BlockNumber      int64
+	FunctionSelector string
+	PerformArgs      string
+}
+
+// PayloadTemplate is the JSON template of the job run payload sent to the Chainlink
+// node. It is either a JSON string holding a Go text/template which must render to
+// JSON, or a JSON object whose string values are rendered as Go text/templates.
+// An empty template renders the preformatted payload expected by ethtx tasks.
+type PayloadTemplate json.RawMessage
+
+// Validate returns an error if the template can't be parsed or doesn't
+// render to JSON
+func (t PayloadTemplate) Validate() error {
+	if len(t) > MaxPayloadTemplateSize {
+		return fmt.Errorf("payloadTemplate can't be longer than %d bytes", MaxPayloadTemplateSize)
+	}
+	_, err := t.Render(PayloadData{})
+	if err != nil {
+		return fmt.Errorf("invalid payloadTemplate: %v", err)
+	}
+	return nil
+}
+
+// equal returns whether the templates hold the same JSON, as the database
+// doesn't keep the formatting of the template
+func (t PayloadTemplate) equal(other PayloadTemplate) bool {
+	if len(t) == 0 || len(other) == 0 {
+		return len(t) == len(other)
+	}
+	var parsed, otherParsed interface{}
+	if json.Unmarshal(t, &parsed) != nil || json.Unmarshal(other, &otherParsed) != nil {
+		return bytes.Equal(t, other)
+	}
+	return reflect.DeepEqual(parsed, otherParsed)
+}
+
+// Render returns the run payload for the data
+func (t PayloadTemplate) Render(data PayloadData) ([]byte, error) {
+	if len(t) == 0 {
+		return json.Marshal(map[string]interface{}{
+			"format":           "preformatted",
+			"address":          data.Registry,
+			"functionSelector": data.FunctionSelector,
+			"result":           data.PerformArgs,
+			"fromAddresses":    []string{data.From},
+			"gasLimit":         data.GasLimit,
+		})
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal(t, &parsed); err != nil {
+		return nil, err
+	}
+	switch value := parsed.(type) {
+	case string:
+		rendered, err := renderTemplate(value, data)
+		if err != nil {
+			return nil, err
+		}
+		if !json.Valid([]byte(rendered)) {
+			return nil, errors.New("template doesn't render to JSON")
+		}
+		return []byte(rendered), nil
+	case map[string]interface{}:
+		rendered, err := renderValues(value, data)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(rendered)
+	default:
+		return nil, errors.New("template must be a JSON string or object")
+	}
+}
+
+// renderValues renders the strings nested in a JSON value
+func renderValues(value interface{}, data PayloadData) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return renderTemplate(v, data)
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			renderedElem, err := renderValues(elem, data)
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = renderedElem
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := make([]interface{}, len(v))
+		for idx, elem := range v {
+			renderedElem, err := renderValues(elem, data)
+			if err != nil {
+				return nil, err
+			}
+			rendered[idx] = renderedElem
+		}
+		return rendered, nil
+	default:
+		return v, nil
+	}
+}
+
+func renderTemplate(text string, data PayloadData) (string, error) {
+	tmpl, err := template.New("payload").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Value returns the template for database storage, an empty template is stored as NULL
+func (t PayloadTemplate) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return nil, nil
+	}
+	return []byte(t), nil
+}
+
+// Scan reads the template from the database
+func (t *PayloadTemplate) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		*t = append(PayloadTemplate{}, v...)
+		return nil
+	case string:
+		*t = PayloadTemplate(v)
+		return nil
+	default:
+		return fmt.Errorf("unable to convert %v of %T to PayloadTemplate", value, value)
+	}
+}
@@ -0,0 +1,90 @@
+package keeper
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var payloadData = PayloadData{
+	UpkeepID:         3,
+	PerformData:      "0x1234",
+	Registry:         "0x00000000000000000000000000000000000000AA",
+	From:             "0x00000000000000000000000000000000000000BB",
+	GasLimit:         300_000,
+	BlockNumber:      42,
+	FunctionSelector: "0x4585e33b",
+	PerformArgs:      "0x0000",
+}
+
+func TestPayloadTemplate_Render(t *testing.T) {
+	t.Run("defaults to the preformatted payload", func(t *testing.T) {
+		payload, err := PayloadTemplate(nil).Render(payloadData)
+		require.NoError(t, err)
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(payload, &decoded))
+		assert.Equal(t, "preformatted", decoded["format"])
+		assert.Equal(t, payloadData.Registry, decoded["address"])
+		assert.Equal(t, payloadData.FunctionSelector, decoded["functionSelector"])
+		assert.Equal(t, payloadData.PerformArgs, decoded["result"])
+		assert.Equal(t, []interface{}{payloadData.From}, decoded["fromAddresses"])
+		assert.Equal(t, float64(payloadData.GasLimit), decoded["gasLimit"])
+	})
+
+	t.Run("renders text templates", func(t *testing.T) {
+		tmpl := PayloadTemplate(`"{\"id\": {{.UpkeepID}}, \"data\": \"{{.PerformData}}\"}"`)
+		payload, err := tmpl.Render(payloadData)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id": 3, "data": "0x1234"}`, string(payload))
+	})
+
+	t.Run("renders the string values of JSON templates", func(t *testing.T) {
+		tmpl := PayloadTemplate(`{"upkeep": {"id": "{{.UpkeepID}}", "block": "{{.BlockNumber}}"}, "tags": ["keeper", "{{.Registry}}"], "version": 2}`)
+		payload, err := tmpl.Render(payloadData)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"upkeep": {"id": "3", "block": "42"}, "tags": ["keeper", "0x00000000000000000000000000000000000000AA"], "version": 2}`, string(payload))
+	})
+}
+
+func TestPayloadTemplate_Validate(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		template PayloadTemplate
+		wantErr  bool
+	}{
+		{"default", nil, false},
+		{"text template", PayloadTemplate(`"{\"gas\": {{.GasLimit}}}"`), false},
+		{"JSON template", PayloadTemplate(`{"gas": "{{.GasLimit}}"}`), false},
+		{"invalid JSON", PayloadTemplate(`{"gas": `), true},
+		{"not a string or object", PayloadTemplate(`[1, 2]`), true},
+		{"unparseable template", PayloadTemplate(`{"gas": "{{.GasLimit"}`), true},
+		{"unknown field", PayloadTemplate(`{"gas": "{{.Gas}}"}`), true},
+		{"doesn't render JSON", PayloadTemplate(`"gas: {{.GasLimit}}"`), true},
+		{"too long", PayloadTemplate(`"` + strings.Repeat(" ", MaxPayloadTemplateSize) + `{}"`), true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.template.Validate()
+			assert.Equal(t, test.wantErr, err != nil, "%v", err)
+		})
+	}
+}
+
+func TestPayloadTemplate_ValueAndScan(t *testing.T) {
+	value, err := PayloadTemplate(nil).Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	tmpl := PayloadTemplate(`{"id": "{{.UpkeepID}}"}`)
+	value, err = tmpl.Value()
+	require.NoError(t, err)
+
+	var scanned PayloadTemplate
+	require.NoError(t, scanned.Scan(value))
+	assert.Equal(t, tmpl, scanned)
+	require.NoError(t, scanned.Scan(nil))
+	assert.Nil(t, scanned)
+	assert.Error(t, scanned.Scan(42))
+}
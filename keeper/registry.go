@@ -1,61 +1,53 @@
 package keeper
 
 import (
-	"fmt"
-
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/smartcontractkit/chainlink/core/store/models"
-	"github.com/smartcontractkit/external-initiator/keeper/keeper_registry_contract"
 )
 
-type registry struct {
+// Registry is a KeeperRegistry contract serviced by one or more memberships, its
+// state is synced from the contract and shared between the memberships
+type Registry struct {
 	ID                uint32         `gorm:"primary_key"`
 	Address           common.Address `gorm:"default:null"`
 	BlockCountPerTurn uint32
 	CheckGas          uint32
-	From              common.Address `gorm:"default:null"`
-	JobID             *models.ID     `gorm:"default:null"`
-	KeeperIndex       uint32
 	NumKeepers        uint32
-	ReferenceID       string `gorm:"default:null"`
+	TurnTaking        TurnTakingStrategy `gorm:"default:null"`
+	Version           RegistryVersion    `gorm:"default:null"`
+	Paused            bool
 }
 
-func NewRegistry(address common.Address, from common.Address, jobID *models.ID) registry {
-	return registry{
-		Address:     address,
-		From:        from,
-		JobID:       jobID,
-		ReferenceID: models.NewID().String(),
+func NewRegistry(address common.Address) Registry {
+	return Registry{
+		Address:    address,
+		TurnTaking: DefaultTurnTakingStrategy,
+		Version:    DefaultRegistryVersion,
 	}
 }
 
-func (registry) TableName() string {
+func (Registry) TableName() string {
 	return "keeper_registries"
 }
 
-func (reg registry) SyncFromContract(contract *keeper_registry_contract.KeeperRegistryContract) (registry, error) {
-	config, err := contract.GetConfig(nil)
+// TurnTaker returns the TurnTaker for the strategy selected on the registry
+func (reg Registry) TurnTaker() (TurnTaker, error) {
+	return NewTurnTaker(reg.TurnTaking)
+}
+
+// SyncFromContract returns the registry updated with the config on the contract,
+// along with the keeper list used to sync the memberships' keeper indexes
+func (reg Registry) SyncFromContract(contract RegistryContract) (Registry, []common.Address, error) {
+	config, err := contract.GetConfig()
 	if err != nil {
-		return registry{}, err
+		return Registry{}, nil, err
 	}
-	reg.CheckGas = config.CheckGasLimit
-	reg.BlockCountPerTurn = uint32(config.BlockCountPerTurn.Uint64())
-	keeperAddresses, err := contract.GetKeeperList(nil)
+	reg.CheckGas = config.CheckGas
+	reg.BlockCountPerTurn = config.BlockCountPerTurn
+	keeperAddresses, err := contract.GetKeeperList()
 	if err != nil {
-		return registry{}, err
+		return Registry{}, nil, err
 	}
-	found := false
-	for idx, address := range keeperAddresses {
-		if address == reg.From {
-			reg.KeeperIndex = uint32(idx)
-			found = true
-		}
-	}
-	if !found {
-		return registry{}, fmt.Errorf("unable to find %s in keeper list on registry %s", reg.From.Hex(), reg.Address.Hex())
-	}
-
 	reg.NumKeepers = uint32(len(keeperAddresses))
 
-	return reg, nil
+	return reg, keeperAddresses, nil
 }
@@ -0,0 +1,328 @@
+package keeper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/smartcontractkit/chainlink/core/services/eth"
+	"github.com/smartcontractkit/external-initiator/keeper/keeper_registry_contract"
+	"github.com/smartcontractkit/external-initiator/keeper/keeper_registry_contract_1_1"
+)
+
+// The 1.1 binding is generated with abigen of go-ethereum v1.9.25, from the subset of the
+// KeeperRegistry 1.1 ABI with the functions called on the registry
+//go:generate abigen --abi keeper_registry_contract_1_1/KeeperRegistry1_1.abi --pkg keeper_registry_contract_1_1 --type KeeperRegistryContract --out keeper_registry_contract_1_1/keeper_registry_contract_1_1.go
+
+// RegistryVersion identifies the KeeperRegistry contract version deployed at a registry address
+type RegistryVersion string
+
+const (
+	RegistryVersion1_0 RegistryVersion = "1.0"
+	RegistryVersion1_1 RegistryVersion = "1.1"
+)
+
+// DefaultRegistryVersion is assumed for registries which were added before versions were tracked
+const DefaultRegistryVersion = RegistryVersion1_0
+
+const typeAndVersionPrefix = "KeeperRegistry "
+
+type registryConfig struct {
+	BlockCountPerTurn uint32
+	CheckGas          uint32
+}
+
+type upkeepConfig struct {
+	Target     common.Address
+	Admin      common.Address
+	ExecuteGas uint32
+	CheckData  []byte
+}
+
+type keeperInfo struct {
+	Payee   common.Address
+	Active  bool
+	Balance *big.Int
+}
+
+// CheckUpkeepResult holds the decoded checkUpkeep return values. Fields which
+// are not returned by a registry version are left nil.
+type CheckUpkeepResult struct {
+	PerformData    []byte
+	MaxLinkPayment *big.Int
+	GasLimit       *big.Int
+	GasWei         *big.Int
+	LinkEth        *big.Int
+}
+
+// RegistryContract adapts the differences between KeeperRegistry versions, so that
+// syncing and executing upkeeps doesn't depend on a single generated binding
+type RegistryContract interface {
+	Version() RegistryVersion
+	GetConfig() (registryConfig, error)
+	GetKeeperList() ([]common.Address, error)
+	GetKeeperInfo(keeper common.Address) (keeperInfo, error)
+	GetUpkeepCount() (uint64, error)
+	GetUpkeep(upkeepID uint64) (upkeepConfig, error)
+	GetCanceledUpkeepList() ([]uint64, error)
+	PackCheckUpkeep(upkeepID uint64, from common.Address) ([]byte, error)
+	UnpackCheckUpkeep(result []byte) (CheckUpkeepResult, error)
+	PackPerformUpkeep(upkeepID uint64, performData []byte) ([]byte, error)
+}
+
+// NewRegistryContract returns the RegistryContract adapter for the given version
+func NewRegistryContract(version RegistryVersion, address common.Address, ethClient eth.Client) (RegistryContract, error) {
+	switch version {
+	case RegistryVersion1_0, "":
+		contract, err := keeper_registry_contract.NewKeeperRegistryContract(address, ethClient)
+		if err != nil {
+			return nil, err
+		}
+		return registryContract1_0{contract: contract}, nil
+	case RegistryVersion1_1:
+		contract, err := keeper_registry_contract_1_1.NewKeeperRegistryContract(address, ethClient)
+		if err != nil {
+			return nil, err
+		}
+		return registryContract1_1{contract: contract}, nil
+	default:
+		return nil, InvalidRegistryError{Reason: fmt.Sprintf("unsupported registry version %s", version)}
+	}
+}
+
+// InvalidRegistryError is returned when the contract can't be serviced as a registry
+// by the keeper, unlike the failures to call the ethereum node
+type InvalidRegistryError struct {
+	Reason string
+}
+
+func (e InvalidRegistryError) Error() string {
+	return e.Reason
+}
+
+// DetectRegistryVersion inspects the contract deployed at address. Registries from v1.1
+// onwards implement typeAndVersion(), which v1.0 lacks, so the version is read from the
+// contract if the function selector is present in its code.
+func DetectRegistryVersion(address common.Address, ethClient eth.Client) (RegistryVersion, error) {
+	code, err := ethClient.CodeAt(context.Background(), address, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(code) == 0 {
+		return "", InvalidRegistryError{Reason: fmt.Sprintf("no contract code at %s", address.Hex())}
+	}
+	if !bytes.Contains(code, UpkeepRegistry1_1ABI.Methods["typeAndVersion"].ID) {
+		return RegistryVersion1_0, nil
+	}
+
+	contract, err := keeper_registry_contract_1_1.NewKeeperRegistryContract(address, ethClient)
+	if err != nil {
+		return "", err
+	}
+	typeAndVersion, err := contract.TypeAndVersion(nil)
+	if err != nil {
+		return "", err
+	}
+	return parseTypeAndVersion(typeAndVersion)
+}
+
+func parseTypeAndVersion(typeAndVersion string) (RegistryVersion, error) {
+	if !strings.HasPrefix(typeAndVersion, typeAndVersionPrefix) {
+		return "", InvalidRegistryError{Reason: fmt.Sprintf("contract is not a KeeperRegistry: %s", typeAndVersion)}
+	}
+	semver := strings.Split(strings.TrimPrefix(typeAndVersion, typeAndVersionPrefix), ".")
+	if len(semver) < 2 {
+		return "", InvalidRegistryError{Reason: fmt.Sprintf("unable to parse registry version: %s", typeAndVersion)}
+	}
+	version := RegistryVersion(semver[0] + "." + semver[1])
+	switch version {
+	case RegistryVersion1_0, RegistryVersion1_1:
+		return version, nil
+	default:
+		return "", InvalidRegistryError{Reason: fmt.Sprintf("unsupported registry version %s", version)}
+	}
+}
+
+type registryContract1_0 struct {
+	contract *keeper_registry_contract.KeeperRegistryContract
+}
+
+var _ RegistryContract = registryContract1_0{}
+
+func (registryContract1_0) Version() RegistryVersion {
+	return RegistryVersion1_0
+}
+
+func (rc registryContract1_0) GetConfig() (registryConfig, error) {
+	config, err := rc.contract.GetConfig(nil)
+	if err != nil {
+		return registryConfig{}, err
+	}
+	return registryConfig{
+		BlockCountPerTurn: uint32(config.BlockCountPerTurn.Uint64()),
+		CheckGas:          config.CheckGasLimit,
+	}, nil
+}
+
+func (rc registryContract1_0) GetKeeperList() ([]common.Address, error) {
+	return rc.contract.GetKeeperList(nil)
+}
+
+func (rc registryContract1_0) GetKeeperInfo(keeper common.Address) (keeperInfo, error) {
+	info, err := rc.contract.GetKeeperInfo(nil, keeper)
+	if err != nil {
+		return keeperInfo{}, err
+	}
+	return keeperInfo{Payee: info.Payee, Active: info.Active, Balance: info.Balance}, nil
+}
+
+func (rc registryContract1_0) GetUpkeepCount() (uint64, error) {
+	count, err := rc.contract.GetUpkeepCount(nil)
+	if err != nil {
+		return 0, err
+	}
+	return count.Uint64(), nil
+}
+
+func (rc registryContract1_0) GetUpkeep(upkeepID uint64) (upkeepConfig, error) {
+	upkeep, err := rc.contract.GetUpkeep(nil, big.NewInt(int64(upkeepID)))
+	if err != nil {
+		return upkeepConfig{}, err
+	}
+	return upkeepConfig{
+		Target:     upkeep.Target,
+		Admin:      upkeep.Admin,
+		ExecuteGas: upkeep.ExecuteGas,
+		CheckData:  upkeep.CheckData,
+	}, nil
+}
+
+func (rc registryContract1_0) GetCanceledUpkeepList() ([]uint64, error) {
+	canceled, err := rc.contract.GetCanceledUpkeepList(nil)
+	if err != nil {
+		return nil, err
+	}
+	return bigsToUint64s(canceled), nil
+}
+
+func (registryContract1_0) PackCheckUpkeep(upkeepID uint64, from common.Address) ([]byte, error) {
+	return UpkeepRegistryABI.Pack(checkUpkeep, big.NewInt(int64(upkeepID)), from)
+}
+
+func (registryContract1_0) UnpackCheckUpkeep(result []byte) (CheckUpkeepResult, error) {
+	return unpackCheckUpkeep(UpkeepRegistryABI, result, "gasWei")
+}
+
+func (registryContract1_0) PackPerformUpkeep(upkeepID uint64, performData []byte) ([]byte, error) {
+	return UpkeepRegistryABI.Pack(performUpkeep, big.NewInt(int64(upkeepID)), performData)
+}
+
+type registryContract1_1 struct {
+	contract *keeper_registry_contract_1_1.KeeperRegistryContract
+}
+
+var _ RegistryContract = registryContract1_1{}
+
+func (registryContract1_1) Version() RegistryVersion {
+	return RegistryVersion1_1
+}
+
+func (rc registryContract1_1) GetConfig() (registryConfig, error) {
+	config, err := rc.contract.GetConfig(nil)
+	if err != nil {
+		return registryConfig{}, err
+	}
+	return registryConfig{
+		BlockCountPerTurn: uint32(config.BlockCountPerTurn.Uint64()),
+		CheckGas:          config.CheckGasLimit,
+	}, nil
+}
+
+func (rc registryContract1_1) GetKeeperList() ([]common.Address, error) {
+	return rc.contract.GetKeeperList(nil)
+}
+
+func (rc registryContract1_1) GetKeeperInfo(keeper common.Address) (keeperInfo, error) {
+	info, err := rc.contract.GetKeeperInfo(nil, keeper)
+	if err != nil {
+		return keeperInfo{}, err
+	}
+	return keeperInfo{Payee: info.Payee, Active: info.Active, Balance: info.Balance}, nil
+}
+
+func (rc registryContract1_1) GetUpkeepCount() (uint64, error) {
+	count, err := rc.contract.GetUpkeepCount(nil)
+	if err != nil {
+		return 0, err
+	}
+	return count.Uint64(), nil
+}
+
+func (rc registryContract1_1) GetUpkeep(upkeepID uint64) (upkeepConfig, error) {
+	upkeep, err := rc.contract.GetUpkeep(nil, big.NewInt(int64(upkeepID)))
+	if err != nil {
+		return upkeepConfig{}, err
+	}
+	return upkeepConfig{
+		Target:     upkeep.Target,
+		Admin:      upkeep.Admin,
+		ExecuteGas: upkeep.ExecuteGas,
+		CheckData:  upkeep.CheckData,
+	}, nil
+}
+
+func (rc registryContract1_1) GetCanceledUpkeepList() ([]uint64, error) {
+	canceled, err := rc.contract.GetCanceledUpkeepList(nil)
+	if err != nil {
+		return nil, err
+	}
+	return bigsToUint64s(canceled), nil
+}
+
+func (registryContract1_1) PackCheckUpkeep(upkeepID uint64, from common.Address) ([]byte, error) {
+	return UpkeepRegistry1_1ABI.Pack(checkUpkeep, big.NewInt(int64(upkeepID)), from)
+}
+
+func (registryContract1_1) UnpackCheckUpkeep(result []byte) (CheckUpkeepResult, error) {
+	return unpackCheckUpkeep(UpkeepRegistry1_1ABI, result, "adjustedGasWei")
+}
+
+func (registryContract1_1) PackPerformUpkeep(upkeepID uint64, performData []byte) ([]byte, error) {
+	return UpkeepRegistry1_1ABI.Pack(performUpkeep, big.NewInt(int64(upkeepID)), performData)
+}
+
+// unpackCheckUpkeep decodes the checkUpkeep return values by name, since their
+// position and naming varies between registry versions
+func unpackCheckUpkeep(registryABI abi.ABI, result []byte, gasWeiField string) (CheckUpkeepResult, error) {
+	values := make(map[string]interface{})
+	if err := registryABI.UnpackIntoMap(values, checkUpkeep, result); err != nil {
+		return CheckUpkeepResult{}, err
+	}
+	performData, ok := values["performData"].([]byte)
+	if !ok {
+		return CheckUpkeepResult{}, fmt.Errorf("checkUpkeep payload not as expected")
+	}
+	bigValue := func(name string) *big.Int {
+		value, _ := values[name].(*big.Int)
+		return value
+	}
+	return CheckUpkeepResult{
+		PerformData:    performData,
+		MaxLinkPayment: bigValue("maxLinkPayment"),
+		GasLimit:       bigValue("gasLimit"),
+		GasWei:         bigValue(gasWeiField),
+		LinkEth:        bigValue("linkEth"),
+	}, nil
+}
+
+func bigsToUint64s(bigs []*big.Int) []uint64 {
+	result := make([]uint64, len(bigs))
+	for idx, value := range bigs {
+		result[idx] = value.Uint64()
+	}
+	return result
+}
@@ -0,0 +1,167 @@
+package keeper
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/smartcontractkit/external-initiator/eitest"
+	"github.com/smartcontractkit/external-initiator/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var checkUpkeepResponse1_1 = struct {
+	PerformData    []byte
+	MaxLinkPayment *big.Int
+	GasLimit       *big.Int
+	AdjustedGasWei *big.Int
+	LinkEth        *big.Int
+}{
+	PerformData:    common.Hex2Bytes("1234"),
+	MaxLinkPayment: big.NewInt(1),
+	GasLimit:       big.NewInt(2_000_000),
+	AdjustedGasWei: big.NewInt(3),
+	LinkEth:        big.NewInt(4),
+}
+
+func TestDetectRegistryVersion(t *testing.T) {
+	address := eitest.NewAddress()
+	typeAndVersionSelector := UpkeepRegistry1_1ABI.Methods["typeAndVersion"].ID
+	codeWithTypeAndVersion := append([]byte{0x60, 0x80, 0x63}, typeAndVersionSelector...)
+
+	t.Run("errors without contract code", func(t *testing.T) {
+		ethMock := new(mocks.EthClient)
+		ethMock.On("CodeAt", mock.Anything, address, mock.Anything).Return([]byte{}, nil)
+		_, err := DetectRegistryVersion(address, ethMock)
+		require.Error(t, err)
+	})
+
+	t.Run("defaults to v1.0 without typeAndVersion", func(t *testing.T) {
+		ethMock := new(mocks.EthClient)
+		ethMock.On("CodeAt", mock.Anything, address, mock.Anything).Return([]byte{0x60, 0x80}, nil)
+		version, err := DetectRegistryVersion(address, ethMock)
+		require.NoError(t, err)
+		assert.Equal(t, RegistryVersion1_0, version)
+	})
+
+	t.Run("reads typeAndVersion", func(t *testing.T) {
+		ethMock := new(mocks.EthClient)
+		ethMock.On("CodeAt", mock.Anything, address, mock.Anything).Return(codeWithTypeAndVersion, nil)
+		registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistry1_1ABI, address)
+		registryMock.MockResponse("typeAndVersion", "KeeperRegistry 1.1.0")
+		version, err := DetectRegistryVersion(address, ethMock)
+		require.NoError(t, err)
+		assert.Equal(t, RegistryVersion1_1, version)
+	})
+
+	t.Run("errors on unsupported versions", func(t *testing.T) {
+		ethMock := new(mocks.EthClient)
+		ethMock.On("CodeAt", mock.Anything, address, mock.Anything).Return(codeWithTypeAndVersion, nil)
+		registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistry1_1ABI, address)
+		registryMock.MockResponse("typeAndVersion", "KeeperRegistry 9.0.0")
+		_, err := DetectRegistryVersion(address, ethMock)
+		require.Error(t, err)
+	})
+}
+
+func TestParseTypeAndVersion(t *testing.T) {
+	version, err := parseTypeAndVersion("KeeperRegistry 1.1.2")
+	require.NoError(t, err)
+	assert.Equal(t, RegistryVersion1_1, version)
+
+	for _, typeAndVersion := range []string{"", "KeeperRegistry", "UpkeepRegistrationRequests 1.0.0", "KeeperRegistry 2.0.0"} {
+		_, err = parseTypeAndVersion(typeAndVersion)
+		require.Error(t, err, typeAndVersion)
+	}
+}
+
+func TestRegistryContract_CheckUpkeep(t *testing.T) {
+	t.Run("v1.0", func(t *testing.T) {
+		contract, err := NewRegistryContract(RegistryVersion1_0, registryAddress, new(mocks.EthClient))
+		require.NoError(t, err)
+		encoded, err := UpkeepRegistryABI.Methods[checkUpkeep].Outputs.Pack(
+			checkUpkeepResponse.PerformData,
+			checkUpkeepResponse.MaxLinkPayment,
+			checkUpkeepResponse.GasLimit,
+			checkUpkeepResponse.GasWei,
+			checkUpkeepResponse.LinkEth,
+		)
+		require.NoError(t, err)
+		result, err := contract.UnpackCheckUpkeep(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, checkUpkeepResponse.PerformData, result.PerformData)
+		assert.Equal(t, checkUpkeepResponse.GasLimit, result.GasLimit)
+	})
+
+	t.Run("v1.1", func(t *testing.T) {
+		contract, err := NewRegistryContract(RegistryVersion1_1, registryAddress, new(mocks.EthClient))
+		require.NoError(t, err)
+		encoded, err := UpkeepRegistry1_1ABI.Methods[checkUpkeep].Outputs.Pack(
+			checkUpkeepResponse1_1.PerformData,
+			checkUpkeepResponse1_1.MaxLinkPayment,
+			checkUpkeepResponse1_1.GasLimit,
+			checkUpkeepResponse1_1.AdjustedGasWei,
+			checkUpkeepResponse1_1.LinkEth,
+		)
+		require.NoError(t, err)
+		result, err := contract.UnpackCheckUpkeep(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, checkUpkeepResponse1_1.PerformData, result.PerformData)
+		assert.Equal(t, checkUpkeepResponse1_1.MaxLinkPayment, result.MaxLinkPayment)
+		assert.Equal(t, checkUpkeepResponse1_1.GasLimit, result.GasLimit)
+		assert.Equal(t, checkUpkeepResponse1_1.AdjustedGasWei, result.GasWei)
+		assert.Equal(t, checkUpkeepResponse1_1.LinkEth, result.LinkEth)
+
+		payload, err := contract.PackPerformUpkeep(3, result.PerformData)
+		require.NoError(t, err)
+		assert.Equal(t, UpkeepRegistry1_1ABI.Methods[performUpkeep].ID, payload[:4])
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := NewRegistryContract("0.9", registryAddress, new(mocks.EthClient))
+		require.Error(t, err)
+	})
+}
+
+func TestMembership_ValidateKeeper(t *testing.T) {
+	reg := NewRegistry(eitest.NewAddress())
+	membership := NewMembership(reg, eitest.NewAddress(), jobID)
+	keeperInfo := func(active bool) interface{} {
+		return struct {
+			Payee   common.Address
+			Active  bool
+			Balance *big.Int
+		}{eitest.NewAddress(), active, big.NewInt(0)}
+	}
+
+	t.Run("active keeper", func(t *testing.T) {
+		ethMock := new(mocks.EthClient)
+		registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistryABI, reg.Address)
+		registryMock.MockResponse("getKeeperInfo", keeperInfo(true))
+		contract, err := NewRegistryContract(RegistryVersion1_0, reg.Address, ethMock)
+		require.NoError(t, err)
+		require.NoError(t, membership.ValidateKeeper(contract))
+	})
+
+	t.Run("inactive keeper", func(t *testing.T) {
+		ethMock := new(mocks.EthClient)
+		registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistryABI, reg.Address)
+		registryMock.MockResponse("getKeeperInfo", keeperInfo(false))
+		contract, err := NewRegistryContract(RegistryVersion1_0, reg.Address, ethMock)
+		require.NoError(t, err)
+		require.Error(t, membership.ValidateKeeper(contract))
+	})
+}
+
+func TestMembership_SyncKeeperIndex(t *testing.T) {
+	membership := NewMembership(NewRegistry(eitest.NewAddress()), fromAddress, jobID)
+
+	membership, err := membership.SyncKeeperIndex([]common.Address{eitest.NewAddress(), fromAddress})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), membership.KeeperIndex)
+
+	_, err = membership.SyncKeeperIndex([]common.Address{eitest.NewAddress()})
+	require.Error(t, err)
+}
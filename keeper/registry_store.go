@@ -1,18 +1,61 @@
 package keeper
 
 import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/jinzhu/gorm"
 	"github.com/smartcontractkit/chainlink/core/store/models"
+	"github.com/smartcontractkit/external-initiator/chainlink"
 )
 
 type Store interface {
-	Registries() ([]registry, error)
-	UpsertRegistry(registry registry) error
-	UpsertUpkeep(registration) error
+	Registries() ([]Registry, error)
+	PaginatedRegistries(nodeID *uint32, offset, limit int) ([]Registry, int, error)
+	RegistryByID(id uint32) (Registry, error)
+	RegistryByAddress(address common.Address) (Registry, error)
+	MembershipsForRegistries(registryIDs []uint32) ([]Membership, error)
+	MembershipByJobID(jobID *models.ID) (Membership, error)
+	PaginatedMemberships(nodeID *uint32, offset, limit int) ([]Membership, int, error)
+	CreateMembership(registry Registry, membership Membership) (Membership, error)
+	UpsertMembership(membership Membership) error
+	SetMembershipOrphaned(jobID *models.ID, orphaned bool) error
+	DeleteMembershipByJobID(jobID *models.ID) error
+	PaginatedUpkeeps(registryID uint32, offset, limit int) ([]Registration, int, error)
+	UpkeepByID(registryID uint32, upkeepID uint64) (Registration, error)
+	UpsertRegistry(registry Registry) error
+	SetRegistryPaused(id uint32, paused bool) error
+	SetUpkeepPaused(registryID uint32, upkeepID uint64, paused bool) error
+	UpsertUpkeep(Registration) error
 	BatchDeleteUpkeeps(registryID uint32, upkeedIDs []uint64) error
-	DeleteRegistryByJobID(jobID *models.ID) error
-	EligibleUpkeeps(blockNumber uint64) ([]registration, error)
-	NextUpkeepIDForRegistry(registry registry) (uint64, error)
+	UpkeepIDsWithoutTarget(registryID uint32) ([]uint64, error)
+	UpkeepFilters(registryID uint32) ([]UpkeepFilter, error)
+	CreateUpkeepFilter(filter UpkeepFilter) (UpkeepFilter, error)
+	DeleteUpkeepFilter(registryID, id uint32) error
+	EligibleUpkeeps(head models.Head) ([]EligibleUpkeep, error)
+	CreateJobRun(run JobRun) (JobRun, error)
+	PendingJobRuns(limit int) ([]JobRun, error)
+	MarkJobRunsPolled(ids []uint32, polledAt time.Time) error
+	UpdateJobRunStatus(id uint32, status chainlink.RunStatus, txHash, runErr string) error
+	PaginatedJobRuns(nodeID *uint32, status chainlink.RunStatus, offset, limit int) ([]JobRun, int, error)
+	CreateJobTrigger(trigger JobTrigger) (JobTrigger, error)
+	DueJobTriggers(now time.Time, limit int) ([]JobTrigger, error)
+	UpdateJobTrigger(trigger JobTrigger) error
+	ReplayJobTrigger(nodeID *uint32, id uint32) (JobTrigger, error)
+	PaginatedJobTriggers(nodeID *uint32, status TriggerStatus, offset, limit int) ([]JobTrigger, int, error)
+	Nodes() ([]Node, error)
+	NodeByName(name string) (Node, error)
+	NodeByJobID(jobID *models.ID) (Node, error)
+	CreateNode(node Node, credential Credential) (Node, error)
+	DeleteNode(name string) error
+	Credentials() ([]Credential, error)
+	CredentialByAccessKey(accessKey string) (Credential, error)
+	CreateCredential(credential Credential) (Credential, error)
+	RevokeCredential(accessKey string) error
+	NextUpkeepIDForRegistry(registry Registry) (uint64, error)
+	UpkeepCountForRegistry(registryID uint32) (int, error)
 	DB() *gorm.DB
 	Close() error
 }
@@ -27,66 +70,605 @@ type keeperStore struct {
 	dbClient *gorm.DB
 }
 
-func (rm keeperStore) Registries() (registries []registry, _ error) {
+func (rm keeperStore) Registries() (registries []Registry, _ error) {
 	err := rm.dbClient.Find(&registries).Error
 	return registries, err
 }
 
-func (rm keeperStore) UpsertRegistry(registry registry) error {
-	return rm.dbClient.Save(&registry).Error
+// PaginatedRegistries returns a page of the registries the node has a membership on,
+// nil for the default node, ordered by ID, along with the total count
+func (rm keeperStore) PaginatedRegistries(nodeID *uint32, offset, limit int) (registries []Registry, count int, _ error) {
+	query := ofNodeRegistries(rm.dbClient.Model(Registry{}), nodeID)
+	err := query.Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	err = query.
+		Order("id").
+		Offset(offset).
+		Limit(limit).
+		Find(&registries).
+		Error
+	return registries, count, err
+}
+
+func (rm keeperStore) RegistryByID(id uint32) (reg Registry, _ error) {
+	err := rm.dbClient.Where("id = ?", id).First(&reg).Error
+	return reg, err
+}
+
+func (rm keeperStore) RegistryByAddress(address common.Address) (reg Registry, _ error) {
+	err := rm.dbClient.Where("address = ?", address).First(&reg).Error
+	return reg, err
+}
+
+// MembershipsForRegistries returns the memberships of the given registries ordered by ID
+func (rm keeperStore) MembershipsForRegistries(registryIDs []uint32) (memberships []Membership, _ error) {
+	if len(registryIDs) == 0 {
+		return nil, nil
+	}
+	err := rm.dbClient.
+		Where("registry_id IN (?)", registryIDs).
+		Order("id").
+		Find(&memberships).
+		Error
+	return memberships, err
+}
+
+// MembershipByJobID returns the membership created for a job along with its registry
+func (rm keeperStore) MembershipByJobID(jobID *models.ID) (membership Membership, _ error) {
+	err := rm.dbClient.Where("job_id = ?", jobID).First(&membership).Error
+	return membership, err
+}
+
+// PaginatedMemberships returns a page of the memberships of a node ordered by ID, along with
+// the total count. A nil nodeID returns the memberships of the default node.
+func (rm keeperStore) PaginatedMemberships(nodeID *uint32, offset, limit int) (memberships []Membership, count int, _ error) {
+	query := rm.dbClient.Where("node_id IS NULL")
+	if nodeID != nil {
+		query = rm.dbClient.Where("node_id = ?", *nodeID)
+	}
+	err := query.Model(Membership{}).Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	err = query.
+		Order("id").
+		Offset(offset).
+		Limit(limit).
+		Find(&memberships).
+		Error
+	return memberships, count, err
 }
 
-func (rm keeperStore) UpsertUpkeep(registration registration) error {
+// CreateMembership saves a new membership, along with its registry if it hasn't been saved yet
+func (rm keeperStore) CreateMembership(registry Registry, membership Membership) (Membership, error) {
+	err := rm.dbClient.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("paused").Save(&registry).Error; err != nil {
+			return err
+		}
+		membership.RegistryID = registry.ID
+		membership.Registry = registry
+		return tx.Create(&membership).Error
+	})
+	return membership, err
+}
+
+// UpsertMembership saves a membership, such as its synced keeper index or updated from address.
+// Whether the job is orphaned is only changed through SetMembershipOrphaned.
+func (rm keeperStore) UpsertMembership(membership Membership) error {
+	return rm.dbClient.Omit("orphaned").Save(&membership).Error
+}
+
+// SetMembershipOrphaned marks the membership of a job as orphaned, when the job no longer exists
+// on the Chainlink node, or clears it. It returns gorm.ErrRecordNotFound for unknown jobs.
+func (rm keeperStore) SetMembershipOrphaned(jobID *models.ID, orphaned bool) error {
+	result := rm.dbClient.
+		Model(Membership{}).
+		Where("job_id = ?", jobID).
+		UpdateColumn("orphaned", orphaned)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteMembershipByJobID deletes the membership of a job, the registry and
+// its upkeeps are deleted along with its last membership
+func (rm keeperStore) DeleteMembershipByJobID(jobID *models.ID) error {
+	return rm.dbClient.Transaction(func(tx *gorm.DB) error {
+		var membership Membership
+		err := tx.Where("job_id = ?", jobID).First(&membership).Error
+		if gorm.IsRecordNotFoundError(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err = tx.Delete(&membership).Error; err != nil {
+			return err
+		}
+		return tx.
+			Where("id = ? AND NOT EXISTS (SELECT 1 FROM keeper_memberships WHERE registry_id = ?)", membership.RegistryID, membership.RegistryID).
+			Delete(Registry{}).
+			Error
+	})
+}
+
+// PaginatedUpkeeps returns a page of a registry's upkeeps ordered by upkeep ID, along with the total count
+func (rm keeperStore) PaginatedUpkeeps(registryID uint32, offset, limit int) (upkeeps []Registration, count int, _ error) {
+	err := rm.dbClient.Model(Registration{}).Where("registry_id = ?", registryID).Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	err = rm.dbClient.
+		Where("registry_id = ?", registryID).
+		Order("upkeep_id").
+		Offset(offset).
+		Limit(limit).
+		Find(&upkeeps).
+		Error
+	return upkeeps, count, err
+}
+
+func (rm keeperStore) UpkeepByID(registryID uint32, upkeepID uint64) (upkeep Registration, _ error) {
+	err := rm.dbClient.
+		Where("registry_id = ? AND upkeep_id = ?", registryID, upkeepID).
+		First(&upkeep).
+		Error
+	return upkeep, err
+}
+
+// UpsertRegistry saves the registry's synced state, the paused state is
+// only changed through SetRegistryPaused so syncs never override it
+func (rm keeperStore) UpsertRegistry(registry Registry) error {
+	return rm.dbClient.Omit("paused").Save(&registry).Error
+}
+
+// SetRegistryPaused pauses or resumes all upkeeps on a registry,
+// returning gorm.ErrRecordNotFound if the registry doesn't exist
+func (rm keeperStore) SetRegistryPaused(id uint32, paused bool) error {
+	result := rm.dbClient.
+		Model(Registry{}).
+		Where("id = ?", id).
+		UpdateColumn("paused", paused)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetUpkeepPaused pauses or resumes a single upkeep,
+// returning gorm.ErrRecordNotFound if the upkeep doesn't exist
+func (rm keeperStore) SetUpkeepPaused(registryID uint32, upkeepID uint64, paused bool) error {
+	result := rm.dbClient.
+		Model(Registration{}).
+		Where("registry_id = ? AND upkeep_id = ?", registryID, upkeepID).
+		UpdateColumn("paused", paused)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (rm keeperStore) UpsertUpkeep(registration Registration) error {
 	return rm.dbClient.
 		Set(
 			"gorm:insert_option",
 			`ON CONFLICT (registry_id, upkeep_id)
 			DO UPDATE SET
 				execute_gas = excluded.execute_gas,
-				check_data = excluded.check_data
+				check_data = excluded.check_data,
+				target = excluded.target,
+				admin = excluded.admin,
+				target_synced = excluded.target_synced
 			`,
 		).
 		Create(&registration).
 		Error
 }
 
+// UpkeepIDsWithoutTarget returns the IDs of upkeeps synced before their target and admin were stored
+func (rm keeperStore) UpkeepIDsWithoutTarget(registryID uint32) (upkeepIDs []uint64, _ error) {
+	err := rm.dbClient.
+		Model(Registration{}).
+		Where("registry_id = ? AND NOT target_synced", registryID).
+		Order("upkeep_id").
+		Pluck("upkeep_id", &upkeepIDs).
+		Error
+	return upkeepIDs, err
+}
+
+func (rm keeperStore) UpkeepFilters(registryID uint32) (filters []UpkeepFilter, _ error) {
+	err := rm.dbClient.
+		Where("registry_id = ?", registryID).
+		Order("id").
+		Find(&filters).
+		Error
+	return filters, err
+}
+
+func (rm keeperStore) CreateUpkeepFilter(filter UpkeepFilter) (UpkeepFilter, error) {
+	err := rm.dbClient.Create(&filter).Error
+	return filter, err
+}
+
+// DeleteUpkeepFilter deletes a filter of the registry,
+// returning gorm.ErrRecordNotFound if it doesn't exist
+func (rm keeperStore) DeleteUpkeepFilter(registryID, id uint32) error {
+	result := rm.dbClient.
+		Where("registry_id = ? AND id = ?", registryID, id).
+		Delete(UpkeepFilter{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (rm keeperStore) BatchDeleteUpkeeps(registryID uint32, upkeedIDs []uint64) error {
 	return rm.dbClient.
 		Where("registry_id = ? AND upkeep_id IN (?)", registryID, upkeedIDs).
-		Delete(registration{}).
+		Delete(Registration{}).
+		Error
+}
+
+// EligibleUpkeeps returns the upkeeps each membership's keeper is expected to perform at
+// the given head, skipping paused registries and upkeeps as well as the upkeeps
+// filtered out by the registry's upkeep filters or the job options. Turns start every
+// block_count_per_turn blocks for all strategies, which is used to narrow down the
+// candidates before asking the registry's TurnTaker.
+func (rm keeperStore) EligibleUpkeeps(head models.Head) (result []EligibleUpkeep, _ error) {
+	var memberships []Membership
+	err := rm.dbClient.
+		Joins("INNER JOIN keeper_registries ON keeper_registries.id = keeper_memberships.registry_id").
+		Where("NOT keeper_registries.paused").
+		Where("NOT keeper_memberships.orphaned").
+		Where("keeper_registries.num_keepers > 0").
+		Where("? % NULLIF(keeper_registries.block_count_per_turn, 0) = 0", head.Number).
+		Order("keeper_memberships.id").
+		Find(&memberships).
+		Error
+	if err != nil || len(memberships) == 0 {
+		return nil, err
+	}
+
+	registryIDs := make([]uint32, len(memberships))
+	for idx, membership := range memberships {
+		registryIDs[idx] = membership.RegistryID
+	}
+	var candidates []Registration
+	err = rm.dbClient.
+		Where("registry_id IN (?) AND NOT paused", registryIDs).
+		Order("id").
+		Find(&candidates).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	upkeepsByRegistry := make(map[uint32][]Registration)
+	for _, upkeep := range candidates {
+		upkeepsByRegistry[upkeep.RegistryID] = append(upkeepsByRegistry[upkeep.RegistryID], upkeep)
+	}
+	var filters []UpkeepFilter
+	err = rm.dbClient.
+		Where("registry_id IN (?)", registryIDs).
+		Find(&filters).
 		Error
+	if err != nil {
+		return nil, err
+	}
+	filtersByRegistry := make(map[uint32]upkeepFilters)
+	for _, filter := range filters {
+		filtersByRegistry[filter.RegistryID] = append(filtersByRegistry[filter.RegistryID], filter)
+	}
+
+	for _, membership := range memberships {
+		turnTaker, err := membership.Registry.TurnTaker()
+		if err != nil {
+			return nil, err
+		}
+		for _, upkeep := range upkeepsByRegistry[membership.RegistryID] {
+			eligible, err := turnTaker.IsEligible(upkeep, membership, head)
+			if err != nil {
+				return nil, err
+			}
+			if !eligible {
+				continue
+			}
+			// only the upkeeps which would have been performed count as skipped
+			if reason := filtersByRegistry[membership.RegistryID].skipReason(upkeep); reason != "" {
+				promUpkeepsSkipped.WithLabelValues(reason).Inc()
+				continue
+			}
+			if !membership.AllowsUpkeep(upkeep.UpkeepID) {
+				promUpkeepsSkipped.WithLabelValues(skipReasonJobFilter).Inc()
+				continue
+			}
+			result = append(result, EligibleUpkeep{Registration: upkeep, Membership: membership})
+		}
+	}
+
+	return result, nil
+}
+
+func (rm keeperStore) CreateJobRun(run JobRun) (JobRun, error) {
+	err := rm.dbClient.Create(&run).Error
+	return run, err
 }
 
-func (rm keeperStore) DeleteRegistryByJobID(jobID *models.ID) error {
+// PendingJobRuns returns the job runs which haven't reached a terminal state, the
+// ones which were never polled first and then the least recently polled ones
+func (rm keeperStore) PendingJobRuns(limit int) (runs []JobRun, _ error) {
+	err := rm.dbClient.
+		Where("status = ?", chainlink.RunStatusPending).
+		Order("last_polled_at NULLS FIRST, id").
+		Limit(limit).
+		Find(&runs).
+		Error
+	return runs, err
+}
+
+// MarkJobRunsPolled records when the status of the job runs was polled
+func (rm keeperStore) MarkJobRunsPolled(ids []uint32, polledAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
 	return rm.dbClient.
-		Where("job_id = ?", jobID).
-		Delete(registry{}).
+		Model(JobRun{}).
+		Where("id IN (?)", ids).
+		UpdateColumn("last_polled_at", polledAt).
 		Error
 }
 
-func (rm keeperStore) EligibleUpkeeps(blockNumber uint64) (result []registration, _ error) {
-	turnTakingQuery := `
-		keeper_registries.keeper_index =
-			(
-				keeper_registrations.positioning_constant + (? / keeper_registries.block_count_per_turn)
-			) % keeper_registries.num_keepers
-	`
+// UpdateJobRunStatus records the outcome of a job run,
+// returning gorm.ErrRecordNotFound if it doesn't exist
+func (rm keeperStore) UpdateJobRunStatus(id uint32, status chainlink.RunStatus, txHash, runErr string) error {
+	result := rm.dbClient.
+		Model(JobRun{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"status":     status,
+			"tx_hash":    txHash,
+			"error":      runErr,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
 
+// PaginatedJobRuns returns the job runs of the node's jobs with the status, or all
+// of them if the status is empty, the most recent ones first
+func (rm keeperStore) PaginatedJobRuns(nodeID *uint32, status chainlink.RunStatus, offset, limit int) (runs []JobRun, count int, _ error) {
+	query := ofNodeMemberships(rm.dbClient.Model(JobRun{}), nodeID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	err = query.
+		Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&runs).
+		Error
+	return runs, count, err
+}
+
+// CreateJobTrigger writes the job trigger to the outbox, returning
+// ErrJobTriggerExists if its idempotency key was already queued
+func (rm keeperStore) CreateJobTrigger(trigger JobTrigger) (JobTrigger, error) {
+	err := rm.dbClient.
+		Set("gorm:insert_option", "ON CONFLICT (idempotency_key) DO NOTHING").
+		Create(&trigger).
+		Error
+	// no id is returned when the insert is skipped
+	if errors.Is(err, sql.ErrNoRows) {
+		return trigger, ErrJobTriggerExists
+	}
+	return trigger, err
+}
+
+// DueJobTriggers returns the oldest pending job triggers whose next attempt is due
+func (rm keeperStore) DueJobTriggers(now time.Time, limit int) (triggers []JobTrigger, _ error) {
 	err := rm.dbClient.
-		Joins("INNER JOIN keeper_registries ON keeper_registries.id = keeper_registrations.registry_id").
-		Where("? % keeper_registries.block_count_per_turn = 0", blockNumber).
-		Where(turnTakingQuery, blockNumber).
-		Find(&result).
+		Where("status = ? AND next_attempt_at <= ?", TriggerPending, now).
+		Order("id").
+		Limit(limit).
+		Find(&triggers).
 		Error
+	return triggers, err
+}
 
-	return result, err
+// UpdateJobTrigger records the outcome of an attempt to send the job trigger,
+// returning gorm.ErrRecordNotFound if it doesn't exist
+func (rm keeperStore) UpdateJobTrigger(trigger JobTrigger) error {
+	result := rm.dbClient.
+		Model(JobTrigger{}).
+		Where("id = ?", trigger.ID).
+		UpdateColumns(map[string]interface{}{
+			"status":          trigger.Status,
+			"attempts":        trigger.Attempts,
+			"next_attempt_at": trigger.NextAttemptAt,
+			"last_error":      trigger.LastError,
+			"updated_at":      time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ReplayJobTrigger queues a dead job trigger to be sent again right away, without expiry.
+// It returns gorm.ErrRecordNotFound if the node's jobs have no dead trigger with the id.
+func (rm keeperStore) ReplayJobTrigger(nodeID *uint32, id uint32) (trigger JobTrigger, _ error) {
+	result := ofNodeMemberships(rm.dbClient.Model(JobTrigger{}), nodeID).
+		Where("id = ? AND status = ?", id, TriggerDead).
+		UpdateColumns(map[string]interface{}{
+			"status":           TriggerPending,
+			"attempts":         0,
+			"next_attempt_at":  time.Now(),
+			"expires_at_block": 0,
+			"updated_at":       time.Now(),
+		})
+	if result.Error != nil {
+		return trigger, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return trigger, gorm.ErrRecordNotFound
+	}
+	err := rm.dbClient.First(&trigger, id).Error
+	return trigger, err
+}
+
+// PaginatedJobTriggers returns the job triggers of the node's jobs with the status,
+// or all of them if the status is empty, the most recent ones first
+func (rm keeperStore) PaginatedJobTriggers(nodeID *uint32, status TriggerStatus, offset, limit int) (triggers []JobTrigger, count int, _ error) {
+	query := ofNodeMemberships(rm.dbClient.Model(JobTrigger{}), nodeID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	err = query.
+		Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&triggers).
+		Error
+	return triggers, count, err
+}
+
+// ofNodeMemberships scopes the query of rows with a membership_id to the
+// memberships created by the node, nil being the default node
+func ofNodeMemberships(query *gorm.DB, nodeID *uint32) *gorm.DB {
+	if nodeID == nil {
+		return query.Where("membership_id IN (SELECT id FROM keeper_memberships WHERE node_id IS NULL)")
+	}
+	return query.Where("membership_id IN (SELECT id FROM keeper_memberships WHERE node_id = ?)", *nodeID)
+}
+
+// ofNodeRegistries restricts the query to the registries the node has a membership on
+func ofNodeRegistries(query *gorm.DB, nodeID *uint32) *gorm.DB {
+	if nodeID == nil {
+		return query.Where("id IN (SELECT registry_id FROM keeper_memberships WHERE node_id IS NULL)")
+	}
+	return query.Where("id IN (SELECT registry_id FROM keeper_memberships WHERE node_id = ?)", *nodeID)
+}
+
+// Nodes returns the Chainlink nodes ordered by name, the default node isn't included
+func (rm keeperStore) Nodes() (nodes []Node, _ error) {
+	err := rm.dbClient.Order("name").Find(&nodes).Error
+	return nodes, err
+}
+
+func (rm keeperStore) NodeByName(name string) (node Node, _ error) {
+	err := rm.dbClient.Where("name = ?", name).First(&node).Error
+	return node, err
+}
+
+// NodeByJobID returns the node which created the job, it returns
+// gorm.ErrRecordNotFound for the jobs of the default node
+func (rm keeperStore) NodeByJobID(jobID *models.ID) (node Node, _ error) {
+	err := rm.dbClient.
+		Select("chainlink_nodes.*").
+		Joins("JOIN keeper_memberships ON keeper_memberships.node_id = chainlink_nodes.id").
+		Where("keeper_memberships.job_id = ?", jobID).
+		First(&node).
+		Error
+	return node, err
+}
+
+// CreateNode saves a new node along with the first credential it authenticates with
+func (rm keeperStore) CreateNode(node Node, credential Credential) (Node, error) {
+	err := rm.dbClient.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&node).Error; err != nil {
+			return err
+		}
+		credential.NodeID = &node.ID
+		return tx.Create(&credential).Error
+	})
+	return node, err
+}
+
+// DeleteNode deletes the node with the name, returning gorm.ErrRecordNotFound
+// if it doesn't exist and ErrNodeHasJobs while jobs still belong to it
+func (rm keeperStore) DeleteNode(name string) error {
+	return rm.dbClient.Transaction(func(tx *gorm.DB) error {
+		var node Node
+		if err := tx.Where("name = ?", name).First(&node).Error; err != nil {
+			return err
+		}
+		var count int
+		if err := tx.Model(Membership{}).Where("node_id = ?", node.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrNodeHasJobs
+		}
+		return tx.Delete(&node).Error
+	})
+}
+
+// Credentials returns the credentials of all nodes, including the expired and revoked ones
+func (rm keeperStore) Credentials() (credentials []Credential, _ error) {
+	err := rm.dbClient.Order("id").Find(&credentials).Error
+	return credentials, err
+}
+
+func (rm keeperStore) CredentialByAccessKey(accessKey string) (credential Credential, _ error) {
+	err := rm.dbClient.Where("access_key = ?", accessKey).First(&credential).Error
+	return credential, err
+}
+
+func (rm keeperStore) CreateCredential(credential Credential) (Credential, error) {
+	err := rm.dbClient.Create(&credential).Error
+	return credential, err
+}
+
+// RevokeCredential revokes the credential with the access key, returning
+// gorm.ErrRecordNotFound unless it exists and isn't revoked yet
+func (rm keeperStore) RevokeCredential(accessKey string) error {
+	result := rm.dbClient.
+		Model(Credential{}).
+		Where("access_key = ? AND revoked_at IS NULL", accessKey).
+		UpdateColumn("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
 // NextUpkeepIDForRegistry returns the largest upkeepID + 1, indicating the expected next upkeepID
 // to sync from the contract
-func (rm keeperStore) NextUpkeepIDForRegistry(reg registry) (nextID uint64, err error) {
+func (rm keeperStore) NextUpkeepIDForRegistry(reg Registry) (nextID uint64, err error) {
 	err = rm.dbClient.
-		Model(&registration{}).
+		Model(&Registration{}).
 		Where("registry_id = ?", reg.ID).
 		Select("coalesce(max(upkeep_id), -1) + 1").
 		Row().
@@ -94,6 +676,15 @@ func (rm keeperStore) NextUpkeepIDForRegistry(reg registry) (nextID uint64, err
 	return nextID, err
 }
 
+func (rm keeperStore) UpkeepCountForRegistry(registryID uint32) (count int, _ error) {
+	err := rm.dbClient.
+		Model(&Registration{}).
+		Where("registry_id = ?", registryID).
+		Count(&count).
+		Error
+	return count, err
+}
+
 func (rm keeperStore) DB() *gorm.DB {
 	return rm.dbClient
 }
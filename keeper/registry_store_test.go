@@ -1,7 +1,9 @@
 package keeper
 
 import (
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/jinzhu/gorm"
@@ -27,21 +29,54 @@ func setupRegistryStore(t *testing.T) (*gorm.DB, Store, func()) {
 	return dbClient.DB(), regStore, cleanup
 }
 
-func newRegistry() registry {
-	return registry{
+func newRegistry() Registry {
+	return Registry{
 		Address:           registryAddress,
 		BlockCountPerTurn: blockCountPerTurn,
 		CheckGas:          checkGas,
-		From:              fromAddress,
-		JobID:             jobID,
-		KeeperIndex:       0,
 		NumKeepers:        1,
-		ReferenceID:       models.NewID().String(),
 	}
 }
 
-func newRegistration(reg registry, upkeepID uint64) registration {
-	return registration{
+func newMembership(reg Registry) Membership {
+	return Membership{
+		RegistryID:  reg.ID,
+		Registry:    reg,
+		From:        fromAddress,
+		JobID:       models.NewID(),
+		KeeperIndex: 0,
+		ReferenceID: models.NewID().String(),
+	}
+}
+
+// createRegistry saves the registry along with a membership for fromAddress
+func createRegistry(t *testing.T, db *gorm.DB, reg Registry) (Registry, Membership) {
+	require.NoError(t, db.Create(&reg).Error)
+	membership := newMembership(reg)
+	require.NoError(t, db.Create(&membership).Error)
+	return reg, membership
+}
+
+func createNode(t *testing.T, db *gorm.DB, name string) Node {
+	node := Node{
+		Name:                          name,
+		URL:                           "http://" + name + ":6688",
+		InitiatorToChainlinkAccessKey: name + "-ic-key",
+		InitiatorToChainlinkSecret:    name + "-ic-secret",
+	}
+	credential, err := NewCredential(nil, name+"-ci-key", name+"-ci-secret", nil)
+	require.NoError(t, err)
+	node, err = NewStore(db).CreateNode(node, credential)
+	require.NoError(t, err)
+	return node
+}
+
+func newHead(blockNumber int64) models.Head {
+	return models.NewHead(big.NewInt(blockNumber), eitest.NewHash(), eitest.NewHash(), 1000)
+}
+
+func newRegistration(reg Registry, upkeepID uint64) Registration {
+	return Registration{
 		UpkeepID:   upkeepID,
 		ExecuteGas: executeGas,
 		Registry:   reg,
@@ -57,12 +92,9 @@ func TestRegistryStore_Registries(t *testing.T) {
 	err := db.Create(&reg).Error
 	require.NoError(t, err)
 
-	reg2 := registry{
-		Address:     common.HexToAddress("0x0000000000000000000000000000000000000456"),
-		CheckGas:    checkGas,
-		JobID:       models.NewID(),
-		From:        fromAddress,
-		ReferenceID: models.NewID().String(),
+	reg2 := Registry{
+		Address:  common.HexToAddress("0x0000000000000000000000000000000000000456"),
+		CheckGas: checkGas,
 	}
 
 	err = db.Create(&reg2).Error
@@ -73,6 +105,70 @@ func TestRegistryStore_Registries(t *testing.T) {
 	require.Equal(t, 2, len(existingRegistries))
 }
 
+func TestRegistryStore_PaginatedRegistries(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	for i := 0; i < 3; i++ {
+		createRegistry(t, db, NewRegistry(eitest.NewAddress()))
+	}
+	node := createNode(t, db, "other")
+	other := NewRegistry(eitest.NewAddress())
+	require.NoError(t, db.Create(&other).Error)
+	otherMembership := newMembership(other)
+	otherMembership.NodeID = &node.ID
+	require.NoError(t, db.Create(&otherMembership).Error)
+
+	page, count, err := regStore.PaginatedRegistries(nil, 0, 2)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	require.Len(t, page, 2)
+
+	nodePage, count, err := regStore.PaginatedRegistries(&node.ID, 0, 2)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Len(t, nodePage, 1)
+	assert.Equal(t, other.ID, nodePage[0].ID)
+
+	page, count, err = regStore.PaginatedRegistries(nil, 2, 2)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	require.Len(t, page, 1)
+
+	reg, err := regStore.RegistryByID(page[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, page[0].Address, reg.Address)
+
+	_, err = regStore.RegistryByID(page[0].ID + 1)
+	require.True(t, gorm.IsRecordNotFoundError(err))
+}
+
+func TestRegistryStore_PaginatedUpkeeps(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	reg := newRegistry()
+	require.NoError(t, db.Create(&reg).Error)
+	for upkeepID := uint64(0); upkeepID < 5; upkeepID++ {
+		require.NoError(t, regStore.UpsertUpkeep(newRegistration(reg, upkeepID)))
+	}
+
+	page, count, err := regStore.PaginatedUpkeeps(reg.ID, 3, 3)
+	require.NoError(t, err)
+	require.Equal(t, 5, count)
+	require.Len(t, page, 2)
+	require.Equal(t, uint64(3), page[0].UpkeepID)
+	require.Equal(t, uint64(4), page[1].UpkeepID)
+
+	upkeep, err := regStore.UpkeepByID(reg.ID, 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), upkeep.UpkeepID)
+	require.Equal(t, reg.Address, upkeep.Registry.Address)
+
+	_, err = regStore.UpkeepByID(reg.ID, 5)
+	require.True(t, gorm.IsRecordNotFoundError(err))
+}
+
 func TestRegistryStore_Upsert(t *testing.T) {
 	db, regStore, cleanup := setupRegistryStore(t)
 	defer cleanup()
@@ -87,15 +183,15 @@ func TestRegistryStore_Upsert(t *testing.T) {
 	err = regStore.UpsertUpkeep(newRegistration)
 	require.NoError(t, err)
 
-	eitest.AssertCount(t, db, &registration{}, 1)
-	var existingRegistration registration
+	eitest.AssertCount(t, db, &Registration{}, 1)
+	var existingRegistration Registration
 	err = db.First(&existingRegistration).Error
 	require.NoError(t, err)
 	require.Equal(t, executeGas, existingRegistration.ExecuteGas)
 	require.Equal(t, checkData, existingRegistration.CheckData)
 
 	// update registration
-	updatedRegistration := registration{
+	updatedRegistration := Registration{
 		Registry:   reg,
 		UpkeepID:   0,
 		ExecuteGas: 20_000,
@@ -103,7 +199,7 @@ func TestRegistryStore_Upsert(t *testing.T) {
 	}
 	err = regStore.UpsertUpkeep(updatedRegistration)
 	require.NoError(t, err)
-	eitest.AssertCount(t, db, &registration{}, 1)
+	eitest.AssertCount(t, db, &Registration{}, 1)
 	err = db.First(&existingRegistration).Error
 	require.NoError(t, err)
 	require.Equal(t, uint32(20_000), existingRegistration.ExecuteGas)
@@ -118,7 +214,7 @@ func TestRegistryStore_BatchDelete(t *testing.T) {
 	err := db.Create(&reg).Error
 	require.NoError(t, err)
 
-	registrations := [3]registration{
+	registrations := [3]Registration{
 		newRegistration(reg, 0),
 		newRegistration(reg, 1),
 		newRegistration(reg, 2),
@@ -129,75 +225,127 @@ func TestRegistryStore_BatchDelete(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	eitest.AssertCount(t, db, &registration{}, 3)
+	eitest.AssertCount(t, db, &Registration{}, 3)
 
 	err = regStore.BatchDeleteUpkeeps(reg.ID, []uint64{0, 2})
 	require.NoError(t, err)
 
-	eitest.AssertCount(t, db, &registration{}, 1)
+	eitest.AssertCount(t, db, &Registration{}, 1)
 }
 
-func TestRegistryStore_DeleteRegistryByJobID(t *testing.T) {
+func TestRegistryStore_CreateMembership(t *testing.T) {
 	db, regStore, cleanup := setupRegistryStore(t)
 	defer cleanup()
 
-	reg := newRegistry()
-	err := db.Create(&reg).Error
+	reg := NewRegistry(registryAddress)
+	membership, err := regStore.CreateMembership(reg, NewMembership(reg, fromAddress, models.NewID()))
+	require.NoError(t, err)
+	require.NotZero(t, membership.ID)
+	require.NotZero(t, membership.RegistryID)
+
+	reg, err = regStore.RegistryByAddress(registryAddress)
 	require.NoError(t, err)
+	require.Equal(t, membership.RegistryID, reg.ID)
+
+	// a second keeper shares the registry
+	reg.NumKeepers = 2
+	other := NewMembership(reg, eitest.NewAddress(), models.NewID())
+	other.KeeperIndex = 1
+	_, err = regStore.CreateMembership(reg, other)
+	require.NoError(t, err)
+	eitest.AssertCount(t, db, Registry{}, 1)
+	eitest.AssertCount(t, db, Membership{}, 2)
+
+	memberships, err := regStore.MembershipsForRegistries([]uint32{reg.ID})
+	require.NoError(t, err)
+	require.Len(t, memberships, 2)
+	assert.Equal(t, fromAddress, memberships[0].From)
+	assert.Equal(t, uint32(1), memberships[1].KeeperIndex)
+	assert.Equal(t, uint32(2), memberships[1].Registry.NumKeepers)
+
+	// the same keeper can't join a registry twice
+	_, err = regStore.CreateMembership(reg, NewMembership(reg, fromAddress, models.NewID()))
+	require.Error(t, err)
+	eitest.AssertCount(t, db, Membership{}, 2)
+}
+
+func TestRegistryStore_MembershipByJobID(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	reg, membership := createRegistry(t, db, newRegistry())
+
+	found, err := regStore.MembershipByJobID(membership.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, membership.ID, found.ID)
+	assert.Equal(t, reg.Address, found.Registry.Address)
+
+	_, err = regStore.MembershipByJobID(models.NewID())
+	require.True(t, gorm.IsRecordNotFoundError(err))
+}
+
+func TestRegistryStore_DeleteMembershipByJobID(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	reg, membership := createRegistry(t, db, newRegistry())
+	other := NewMembership(reg, eitest.NewAddress(), models.NewID())
+	require.NoError(t, db.Create(&other).Error)
 
-	registrations := [3]registration{
+	registrations := [3]Registration{
 		newRegistration(reg, 0),
 		newRegistration(reg, 1),
 		newRegistration(reg, 2),
 	}
 
 	for _, reg := range registrations {
-		err = db.Create(&reg).Error
+		err := db.Create(&reg).Error
 		require.NoError(t, err)
 	}
 
-	eitest.AssertCount(t, db, &registration{}, 3)
+	eitest.AssertCount(t, db, &Registration{}, 3)
+
+	// the registry is kept while other keepers service it
+	err := regStore.DeleteMembershipByJobID(membership.JobID)
+	require.NoError(t, err)
+
+	eitest.AssertCount(t, db, Membership{}, 1)
+	eitest.AssertCount(t, db, Registry{}, 1)
+	eitest.AssertCount(t, db, &Registration{}, 3)
 
-	err = regStore.DeleteRegistryByJobID(reg.JobID)
+	err = regStore.DeleteMembershipByJobID(other.JobID)
 	require.NoError(t, err)
 
-	eitest.AssertCount(t, db, registry{}, 0)
-	eitest.AssertCount(t, db, &registration{}, 0)
+	eitest.AssertCount(t, db, Membership{}, 0)
+	eitest.AssertCount(t, db, Registry{}, 0)
+	eitest.AssertCount(t, db, &Registration{}, 0)
+
+	// unknown jobs are a no-op
+	err = regStore.DeleteMembershipByJobID(models.NewID())
+	require.NoError(t, err)
 }
 
 func TestRegistryStore_Eligibile_BlockCountPerTurn(t *testing.T) {
 	db, regStore, cleanup := setupRegistryStore(t)
 	defer cleanup()
 
-	blockheight := uint64(40)
+	head := newHead(40)
 
 	// create registries
-	reg1 := registry{
+	reg1, _ := createRegistry(t, db, Registry{
 		Address:           common.HexToAddress("0x0000000000000000000000000000000000000123"),
 		BlockCountPerTurn: 20,
 		CheckGas:          checkGas,
-		From:              fromAddress,
-		JobID:             models.NewID(),
-		KeeperIndex:       0,
 		NumKeepers:        1,
-		ReferenceID:       models.NewID().String(),
-	}
-	reg2 := registry{
+	})
+	reg2, _ := createRegistry(t, db, Registry{
 		Address:           common.HexToAddress("0x0000000000000000000000000000000000000321"),
 		BlockCountPerTurn: 30,
 		CheckGas:          checkGas,
-		From:              fromAddress,
-		JobID:             models.NewID(),
-		KeeperIndex:       0,
 		NumKeepers:        1,
-		ReferenceID:       models.NewID().String(),
-	}
-	err := db.Create(&reg1).Error
-	require.NoError(t, err)
-	err = db.Create(&reg2).Error
-	require.NoError(t, err)
+	})
 
-	registrations := [3]registration{
+	registrations := [3]Registration{
 		{ // our turn
 			UpkeepID:   0,
 			ExecuteGas: executeGas,
@@ -214,75 +362,458 @@ func TestRegistryStore_Eligibile_BlockCountPerTurn(t *testing.T) {
 	}
 
 	for _, reg := range registrations {
-		err = regStore.UpsertUpkeep(reg)
+		err := regStore.UpsertUpkeep(reg)
 		require.NoError(t, err)
 	}
 
-	eitest.AssertCount(t, db, &registration{}, 3)
+	eitest.AssertCount(t, db, &Registration{}, 3)
 
-	elligibleRegistrations, err := regStore.EligibleUpkeeps(blockheight)
+	elligibleRegistrations, err := regStore.EligibleUpkeeps(head)
 	assert.NoError(t, err)
 	assert.Len(t, elligibleRegistrations, 2)
-	assert.Equal(t, uint64(0), elligibleRegistrations[0].UpkeepID)
-	assert.Equal(t, uint64(1), elligibleRegistrations[1].UpkeepID)
+	assert.Equal(t, uint64(0), elligibleRegistrations[0].Registration.UpkeepID)
+	assert.Equal(t, uint64(1), elligibleRegistrations[1].Registration.UpkeepID)
 
 	// preloads registry data
-	assert.Equal(t, reg1.ID, elligibleRegistrations[0].RegistryID)
-	assert.Equal(t, reg1.ID, elligibleRegistrations[1].RegistryID)
-	assert.Equal(t, reg1.CheckGas, elligibleRegistrations[0].Registry.CheckGas)
-	assert.Equal(t, reg1.CheckGas, elligibleRegistrations[1].Registry.CheckGas)
-	assert.Equal(t, reg1.Address, elligibleRegistrations[0].Registry.Address)
-	assert.Equal(t, reg1.Address, elligibleRegistrations[1].Registry.Address)
+	assert.Equal(t, reg1.ID, elligibleRegistrations[0].Registration.RegistryID)
+	assert.Equal(t, reg1.ID, elligibleRegistrations[1].Registration.RegistryID)
+	assert.Equal(t, reg1.CheckGas, elligibleRegistrations[0].Membership.Registry.CheckGas)
+	assert.Equal(t, reg1.CheckGas, elligibleRegistrations[1].Membership.Registry.CheckGas)
+	assert.Equal(t, reg1.Address, elligibleRegistrations[0].Membership.Registry.Address)
+	assert.Equal(t, reg1.Address, elligibleRegistrations[1].Membership.Registry.Address)
+	assert.Equal(t, fromAddress, elligibleRegistrations[0].Membership.From)
 }
 
 func TestRegistryStore_Eligibile_KeepersRotate(t *testing.T) {
 	db, regStore, cleanup := setupRegistryStore(t)
 	defer cleanup()
 
-	reg := registry{
+	reg, _ := createRegistry(t, db, Registry{
 		Address:           common.HexToAddress("0x0000000000000000000000000000000000000123"),
 		BlockCountPerTurn: 20,
 		CheckGas:          checkGas,
-		From:              fromAddress,
-		JobID:             models.NewID(),
-		KeeperIndex:       0,
 		NumKeepers:        5,
-		ReferenceID:       models.NewID().String(),
-	}
-
-	err := db.Create(&reg).Error
-	require.NoError(t, err)
+	})
 
 	upkeep := newRegistration(reg, 0)
-	err = regStore.UpsertUpkeep(upkeep)
+	err := regStore.UpsertUpkeep(upkeep)
 	require.NoError(t, err)
 
-	eitest.AssertCount(t, db, registry{}, 1)
-	eitest.AssertCount(t, db, &registration{}, 1)
+	eitest.AssertCount(t, db, Registry{}, 1)
+	eitest.AssertCount(t, db, &Registration{}, 1)
 
 	// out of 5 valid block heights, with 5 keepers, we are eligible
 	// to submit on exactly 1 of them
-	list1, err := regStore.EligibleUpkeeps(20) // someone eligible
+	list1, err := regStore.EligibleUpkeeps(newHead(20)) // someone eligible
 	require.NoError(t, err)
-	list2, err := regStore.EligibleUpkeeps(30) // noone eligible
+	list2, err := regStore.EligibleUpkeeps(newHead(30)) // noone eligible
 	require.NoError(t, err)
-	list3, err := regStore.EligibleUpkeeps(40) // someone eligible
+	list3, err := regStore.EligibleUpkeeps(newHead(40)) // someone eligible
 	require.NoError(t, err)
-	list4, err := regStore.EligibleUpkeeps(41) // noone eligible
+	list4, err := regStore.EligibleUpkeeps(newHead(41)) // noone eligible
 	require.NoError(t, err)
-	list5, err := regStore.EligibleUpkeeps(60) // someone eligible
+	list5, err := regStore.EligibleUpkeeps(newHead(60)) // someone eligible
 	require.NoError(t, err)
-	list6, err := regStore.EligibleUpkeeps(80) // someone eligible
+	list6, err := regStore.EligibleUpkeeps(newHead(80)) // someone eligible
 	require.NoError(t, err)
-	list7, err := regStore.EligibleUpkeeps(99) // noone eligible
+	list7, err := regStore.EligibleUpkeeps(newHead(99)) // noone eligible
 	require.NoError(t, err)
-	list8, err := regStore.EligibleUpkeeps(100) // someone eligible
+	list8, err := regStore.EligibleUpkeeps(newHead(100)) // someone eligible
 	require.NoError(t, err)
 
 	totalEligible := len(list1) + len(list2) + len(list3) + len(list4) + len(list5) + len(list6) + len(list7) + len(list8)
 	require.Equal(t, 1, totalEligible)
 }
 
+func TestRegistryStore_Eligibile_PerMembership(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	reg := newRegistry()
+	reg.NumKeepers = 2
+	reg, membership := createRegistry(t, db, reg)
+	other := NewMembership(reg, eitest.NewAddress(), models.NewID())
+	other.KeeperIndex = 1
+	require.NoError(t, db.Create(&other).Error)
+
+	for upkeepID := uint64(0); upkeepID < 4; upkeepID++ {
+		upkeep := newRegistration(reg, upkeepID)
+		upkeep.PositioningConstant = uint32(upkeepID % 2)
+		require.NoError(t, regStore.UpsertUpkeep(upkeep))
+	}
+
+	// every upkeep is performed by exactly one of our keepers each turn
+	eligible, err := regStore.EligibleUpkeeps(newHead(40))
+	require.NoError(t, err)
+	require.Len(t, eligible, 4)
+	for _, upkeep := range eligible[:2] {
+		assert.Equal(t, membership.ID, upkeep.Membership.ID)
+	}
+	for _, upkeep := range eligible[2:] {
+		assert.Equal(t, other.ID, upkeep.Membership.ID)
+	}
+	assert.Equal(t, uint64(0), eligible[0].Registration.UpkeepID)
+	assert.Equal(t, uint64(2), eligible[1].Registration.UpkeepID)
+	assert.Equal(t, uint64(1), eligible[2].Registration.UpkeepID)
+	assert.Equal(t, uint64(3), eligible[3].Registration.UpkeepID)
+}
+
+func TestRegistryStore_Eligibile_UsesRegistryTurnTaker(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	reg := newRegistry()
+	reg.NumKeepers = 3
+	reg.TurnTaking = BlockHashStrategy
+	reg, membership := createRegistry(t, db, reg)
+
+	for upkeepID := uint64(0); upkeepID < 10; upkeepID++ {
+		err := regStore.UpsertUpkeep(newRegistration(reg, upkeepID))
+		require.NoError(t, err)
+	}
+
+	head := newHead(40)
+	eligible, err := regStore.EligibleUpkeeps(head)
+	require.NoError(t, err)
+
+	var expected []uint64
+	for upkeepID := uint64(0); upkeepID < 10; upkeepID++ {
+		ok, err := blockHashTurnTaker{}.IsEligible(Registration{UpkeepID: upkeepID}, membership, head)
+		require.NoError(t, err)
+		if ok {
+			expected = append(expected, upkeepID)
+		}
+	}
+	actual := make([]uint64, len(eligible))
+	for idx, upkeep := range eligible {
+		actual[idx] = upkeep.Registration.UpkeepID
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestRegistryStore_Eligibile_SkipsUnsyncedRegistries(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	reg, _ := createRegistry(t, db, NewRegistry(registryAddress))
+
+	err := regStore.UpsertUpkeep(newRegistration(reg, 0))
+	require.NoError(t, err)
+
+	eligible, err := regStore.EligibleUpkeeps(newHead(40))
+	require.NoError(t, err)
+	assert.Len(t, eligible, 0)
+}
+
+func TestRegistryStore_Eligibile_SkipsPaused(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	reg, _ := createRegistry(t, db, newRegistry())
+
+	for upkeepID := uint64(0); upkeepID < 3; upkeepID++ {
+		err := regStore.UpsertUpkeep(newRegistration(reg, upkeepID))
+		require.NoError(t, err)
+	}
+
+	err := regStore.SetUpkeepPaused(reg.ID, 1, true)
+	require.NoError(t, err)
+
+	eligible, err := regStore.EligibleUpkeeps(newHead(40))
+	require.NoError(t, err)
+	require.Len(t, eligible, 2)
+	assert.Equal(t, uint64(0), eligible[0].Registration.UpkeepID)
+	assert.Equal(t, uint64(2), eligible[1].Registration.UpkeepID)
+
+	err = regStore.SetRegistryPaused(reg.ID, true)
+	require.NoError(t, err)
+
+	eligible, err = regStore.EligibleUpkeeps(newHead(40))
+	require.NoError(t, err)
+	assert.Len(t, eligible, 0)
+
+	// syncs don't resume paused registries or upkeeps
+	err = regStore.UpsertRegistry(reg)
+	require.NoError(t, err)
+	err = regStore.UpsertUpkeep(newRegistration(reg, 1))
+	require.NoError(t, err)
+
+	existing, err := regStore.RegistryByID(reg.ID)
+	require.NoError(t, err)
+	assert.True(t, existing.Paused)
+	upkeep, err := regStore.UpkeepByID(reg.ID, 1)
+	require.NoError(t, err)
+	assert.True(t, upkeep.Paused)
+
+	err = regStore.SetRegistryPaused(reg.ID, false)
+	require.NoError(t, err)
+
+	eligible, err = regStore.EligibleUpkeeps(newHead(40))
+	require.NoError(t, err)
+	assert.Len(t, eligible, 2)
+
+	err = regStore.SetUpkeepPaused(reg.ID, 5, true)
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+}
+
+func TestRegistryStore_Eligibile_SkipsOrphaned(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	reg, membership := createRegistry(t, db, newRegistry())
+	err := regStore.UpsertUpkeep(newRegistration(reg, 0))
+	require.NoError(t, err)
+
+	err = regStore.SetMembershipOrphaned(membership.JobID, true)
+	require.NoError(t, err)
+
+	eligible, err := regStore.EligibleUpkeeps(newHead(40))
+	require.NoError(t, err)
+	assert.Len(t, eligible, 0)
+
+	// syncs don't clear orphaned memberships
+	err = regStore.UpsertMembership(membership)
+	require.NoError(t, err)
+	found, err := regStore.MembershipByJobID(membership.JobID)
+	require.NoError(t, err)
+	assert.True(t, found.Orphaned)
+
+	err = regStore.SetMembershipOrphaned(membership.JobID, false)
+	require.NoError(t, err)
+
+	eligible, err = regStore.EligibleUpkeeps(newHead(40))
+	require.NoError(t, err)
+	assert.Len(t, eligible, 1)
+
+	err = regStore.SetMembershipOrphaned(models.NewID(), true)
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+}
+
+func TestRegistryStore_Eligibile_AppliesJobFilters(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	reg := newRegistry()
+	require.NoError(t, db.Create(&reg).Error)
+	membership := newMembership(reg)
+	membership.DeniedUpkeeps = UpkeepIDs{1}
+	require.NoError(t, db.Create(&membership).Error)
+	for upkeepID := uint64(0); upkeepID < 3; upkeepID++ {
+		require.NoError(t, regStore.UpsertUpkeep(newRegistration(reg, upkeepID)))
+	}
+
+	eligible, err := regStore.EligibleUpkeeps(newHead(40))
+	require.NoError(t, err)
+	require.Len(t, eligible, 2)
+	assert.Equal(t, uint64(0), eligible[0].Registration.UpkeepID)
+	assert.Equal(t, uint64(2), eligible[1].Registration.UpkeepID)
+	assert.Equal(t, UpkeepIDs{1}, eligible[0].Membership.DeniedUpkeeps)
+
+	membership.DeniedUpkeeps = nil
+	membership.AllowedUpkeeps = UpkeepIDs{1}
+	require.NoError(t, regStore.UpsertMembership(membership))
+
+	eligible, err = regStore.EligibleUpkeeps(newHead(40))
+	require.NoError(t, err)
+	require.Len(t, eligible, 1)
+	assert.Equal(t, uint64(1), eligible[0].Registration.UpkeepID)
+}
+
+func TestRegistryStore_Eligibile_AppliesUpkeepFilters(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	reg, _ := createRegistry(t, db, newRegistry())
+	target := eitest.NewAddress()
+	for upkeepID := uint64(0); upkeepID < 3; upkeepID++ {
+		upkeep := newRegistration(reg, upkeepID)
+		if upkeepID > 0 {
+			upkeep.Target = target
+		}
+		require.NoError(t, regStore.UpsertUpkeep(upkeep))
+	}
+	otherReg := newRegistry()
+	otherReg.Address = eitest.NewAddress()
+	otherReg, _ = createRegistry(t, db, otherReg)
+	require.NoError(t, regStore.UpsertUpkeep(newRegistration(otherReg, 0)))
+
+	allowTarget, err := NewUpkeepFilter(reg.ID, AllowFilter, TargetField, target.Hex())
+	require.NoError(t, err)
+	allowTarget, err = regStore.CreateUpkeepFilter(allowTarget)
+	require.NoError(t, err)
+	denyUpkeep, err := NewUpkeepFilter(reg.ID, DenyFilter, UpkeepIDField, "2")
+	require.NoError(t, err)
+	_, err = regStore.CreateUpkeepFilter(denyUpkeep)
+	require.NoError(t, err)
+
+	eligible, err := regStore.EligibleUpkeeps(newHead(40))
+	require.NoError(t, err)
+	require.Len(t, eligible, 2)
+	assert.Equal(t, reg.ID, eligible[0].Registration.RegistryID)
+	assert.Equal(t, uint64(1), eligible[0].Registration.UpkeepID)
+	assert.Equal(t, otherReg.ID, eligible[1].Registration.RegistryID)
+
+	filters, err := regStore.UpkeepFilters(reg.ID)
+	require.NoError(t, err)
+	require.Len(t, filters, 2)
+	require.NoError(t, regStore.DeleteUpkeepFilter(reg.ID, allowTarget.ID))
+	require.True(t, gorm.IsRecordNotFoundError(regStore.DeleteUpkeepFilter(otherReg.ID, filters[1].ID)))
+
+	eligible, err = regStore.EligibleUpkeeps(newHead(40))
+	require.NoError(t, err)
+	require.Len(t, eligible, 3)
+}
+
+func TestRegistryStore_UpkeepIDsWithoutTarget(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	reg, _ := createRegistry(t, db, newRegistry())
+	require.NoError(t, regStore.UpsertUpkeep(newRegistration(reg, 0)))
+	synced := newRegistration(reg, 1)
+	synced.Target = eitest.NewAddress()
+	synced.Admin = eitest.NewAddress()
+	synced.TargetSynced = true
+	require.NoError(t, regStore.UpsertUpkeep(synced))
+	// the target of an upkeep can be the zero address
+	zeroTarget := newRegistration(reg, 2)
+	zeroTarget.TargetSynced = true
+	require.NoError(t, regStore.UpsertUpkeep(zeroTarget))
+
+	upkeepIDs, err := regStore.UpkeepIDsWithoutTarget(reg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{0}, upkeepIDs)
+
+	upkeep, err := regStore.UpkeepByID(reg.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, synced.Target, upkeep.Target)
+	assert.Equal(t, synced.Admin, upkeep.Admin)
+}
+
+func TestRegistryStore_PaginatedMemberships(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	reg, first := createRegistry(t, db, newRegistry())
+	for i := 0; i < 2; i++ {
+		membership := NewMembership(reg, eitest.NewAddress(), models.NewID())
+		require.NoError(t, db.Create(&membership).Error)
+	}
+
+	memberships, count, err := regStore.PaginatedMemberships(nil, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	require.Len(t, memberships, 2)
+	assert.Equal(t, first.ID, memberships[0].ID)
+	assert.Equal(t, reg.Address, memberships[0].Registry.Address)
+
+	memberships, _, err = regStore.PaginatedMemberships(nil, 2, 2)
+	require.NoError(t, err)
+	assert.Len(t, memberships, 1)
+}
+
+func TestRegistryStore_PaginatedMemberships_ScopedToNode(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	reg, defaultMembership := createRegistry(t, db, newRegistry())
+	node := createNode(t, db, "other")
+	membership := NewMembership(reg, eitest.NewAddress(), models.NewID())
+	membership.NodeID = &node.ID
+	require.NoError(t, db.Create(&membership).Error)
+
+	memberships, count, err := regStore.PaginatedMemberships(&node.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	require.Len(t, memberships, 1)
+	assert.Equal(t, membership.ID, memberships[0].ID)
+
+	memberships, count, err = regStore.PaginatedMemberships(nil, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	require.Len(t, memberships, 1)
+	assert.Equal(t, defaultMembership.ID, memberships[0].ID)
+}
+
+func TestRegistryStore_NodeByJobID(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	reg, defaultMembership := createRegistry(t, db, newRegistry())
+	node := createNode(t, db, "other")
+	membership := NewMembership(reg, eitest.NewAddress(), models.NewID())
+	membership.NodeID = &node.ID
+	require.NoError(t, db.Create(&membership).Error)
+
+	found, err := regStore.NodeByJobID(membership.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, node.ID, found.ID)
+	assert.Equal(t, "other-ic-key", found.InitiatorToChainlinkAccessKey)
+
+	_, err = regStore.NodeByJobID(defaultMembership.JobID)
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+}
+
+func TestRegistryStore_CredentialByAccessKey(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	node := createNode(t, db, "other")
+
+	found, err := regStore.CredentialByAccessKey("other-ci-key")
+	require.NoError(t, err)
+	require.NotNil(t, found.NodeID)
+	assert.Equal(t, node.ID, *found.NodeID)
+	assert.NotContains(t, found.HashedSecret, "other-ci-secret")
+	assert.True(t, found.Authenticate("other-ci-secret", time.Now()))
+
+	_, err = regStore.CredentialByAccessKey("other-ic-key")
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+}
+
+func TestRegistryStore_RevokeCredential(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	createNode(t, db, "other")
+	credential, err := NewCredential(nil, "default-ci-key", "default-ci-secret", nil)
+	require.NoError(t, err)
+	_, err = regStore.CreateCredential(credential)
+	require.NoError(t, err)
+
+	require.NoError(t, regStore.RevokeCredential("default-ci-key"))
+	assert.True(t, gorm.IsRecordNotFoundError(regStore.RevokeCredential("default-ci-key")))
+	assert.True(t, gorm.IsRecordNotFoundError(regStore.RevokeCredential("unknown")))
+
+	credentials, err := regStore.Credentials()
+	require.NoError(t, err)
+	require.Len(t, credentials, 2)
+	assert.Equal(t, CredentialActive, credentials[0].Status(time.Now()))
+	assert.Nil(t, credentials[1].NodeID)
+	assert.Equal(t, CredentialRevoked, credentials[1].Status(time.Now()))
+}
+
+func TestRegistryStore_DeleteNode(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+
+	reg, _ := createRegistry(t, db, newRegistry())
+	node := createNode(t, db, "other")
+	createNode(t, db, "unused")
+	membership := NewMembership(reg, eitest.NewAddress(), models.NewID())
+	membership.NodeID = &node.ID
+	require.NoError(t, db.Create(&membership).Error)
+
+	assert.Equal(t, ErrNodeHasJobs, regStore.DeleteNode("other"))
+	assert.NoError(t, regStore.DeleteNode("unused"))
+	assert.True(t, gorm.IsRecordNotFoundError(regStore.DeleteNode("unused")))
+
+	nodes, err := regStore.Nodes()
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	assert.Equal(t, "other", nodes[0].Name)
+}
+
 func TestRegistryStore_NextUpkeepID(t *testing.T) {
 	db, regStore, cleanup := setupRegistryStore(t)
 	defer cleanup()
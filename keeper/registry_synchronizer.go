@@ -3,13 +3,14 @@ package keeper
 import (
 	"errors"
 	"fmt"
-	"math/big"
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/smartcontractkit/chainlink/core/logger"
 	"github.com/smartcontractkit/chainlink/core/services/eth"
-	"github.com/smartcontractkit/external-initiator/keeper/keeper_registry_contract"
 	"go.uber.org/atomic"
 )
 
@@ -17,43 +18,100 @@ import (
 const syncRegistryQueueSize = 3
 const syncUpkeepQueueSize = 10
 
+var (
+	promSyncDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name: "keeper_registry_full_sync_duration_seconds",
+		Help: "The time taken to sync all keeper registries",
+	})
+	promSyncErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "keeper_registry_sync_errors",
+		Help: "The number of failed registry syncs, by registry address",
+	}, []string{"registry"})
+	promUpkeepsPerRegistry = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "keeper_registry_upkeeps",
+		Help: "The number of active upkeeps synced from each registry",
+	}, []string{"registry"})
+)
+
 type RegistrySynchronizer interface {
 	Start() error
 	Stop()
+	// Err receives the error the synchronizer failed with while running
+	Err() <-chan error
+	Status() SynchronizerStatus
+	TriggerSync()
+}
+
+// SynchronizerStatus reports the progress of the RegistrySynchronizer,
+// zero times mean that the event hasn't happened yet
+type SynchronizerStatus struct {
+	StartedAt         time.Time
+	LastSyncAt        time.Time
+	LastSyncFailureAt time.Time
 }
 
 func NewRegistrySynchronizer(keeperStore Store, ethClient eth.Client, syncInterval time.Duration) RegistrySynchronizer {
 	return registrySynchronizer{
-		ethClient:   ethClient,
-		keeperStore: keeperStore,
-		interval:    syncInterval,
-		isRunning:   atomic.NewBool(false),
-		chDone:      make(chan struct{}),
+		ethClient:         ethClient,
+		keeperStore:       keeperStore,
+		interval:          syncInterval,
+		isRunning:         atomic.NewBool(false),
+		startedAt:         atomic.NewInt64(0),
+		lastSyncAt:        atomic.NewInt64(0),
+		lastSyncFailureAt: atomic.NewInt64(0),
+		lifecycle:         newLifecycle(),
+		chSyncNow:         make(chan struct{}, 1),
 	}
 }
 
 type registrySynchronizer struct {
-	endpoint    string
 	ethClient   eth.Client
 	interval    time.Duration
 	isRunning   *atomic.Bool
-	isSyncing   *atomic.Bool
 	keeperStore Store
 
-	chDone chan struct{}
+	// unix nano timestamps reported in Status()
+	startedAt         *atomic.Int64
+	lastSyncAt        *atomic.Int64
+	lastSyncFailureAt *atomic.Int64
+
+	*lifecycle
+	chSyncNow chan struct{}
 }
 
+// Start syncs the registries on every interval, it can be called again once stopped
 func (rs registrySynchronizer) Start() error {
 	if rs.isRunning.Load() {
 		return errors.New("already started")
 	}
+	rs.restart()
 	rs.isRunning.Store(true)
-	go rs.run()
+	rs.startedAt.Store(time.Now().UnixNano())
+	rs.spawn(rs.run)
 	return nil
 }
 
+func (rs registrySynchronizer) Status() SynchronizerStatus {
+	return SynchronizerStatus{
+		StartedAt:         unixNanoToTime(rs.startedAt.Load()),
+		LastSyncAt:        unixNanoToTime(rs.lastSyncAt.Load()),
+		LastSyncFailureAt: unixNanoToTime(rs.lastSyncFailureAt.Load()),
+	}
+}
+
+// TriggerSync requests a full sync without waiting for the next interval,
+// it doesn't block and is a no-op if a sync was already requested
+func (rs registrySynchronizer) TriggerSync() {
+	select {
+	case rs.chSyncNow <- struct{}{}:
+	default:
+	}
+}
+
+// Stop waits for the registries being synced, the remaining ones are synced once started again
 func (rs registrySynchronizer) Stop() {
-	close(rs.chDone)
+	rs.stop()
+	rs.isRunning.Store(false)
 }
 
 func (rs registrySynchronizer) run() {
@@ -66,6 +124,8 @@ func (rs registrySynchronizer) run() {
 			return
 		case <-ticker.C:
 			rs.performFullSync()
+		case <-rs.chSyncNow:
+			rs.performFullSync()
 		}
 	}
 }
@@ -74,11 +134,14 @@ func (rs registrySynchronizer) run() {
 // It blocks until the full sync is complete
 func (rs registrySynchronizer) performFullSync() {
 	logger.Debug("performing full sync of all keeper registries")
+	start := time.Now()
+	defer func() { promSyncDuration.Observe(time.Since(start).Seconds()) }()
 
 	registries, err := rs.keeperStore.Registries()
 	if err != nil {
 		logger.Error(err)
 	}
+	failed := atomic.NewBool(err != nil)
 
 	wg := sync.WaitGroup{}
 	wg.Add(len(registries))
@@ -87,58 +150,109 @@ func (rs registrySynchronizer) performFullSync() {
 	chSyncRegistryQueue := make(chan struct{}, syncRegistryQueueSize)
 	done := func() { <-chSyncRegistryQueue; wg.Done() }
 	for _, registry := range registries {
+		if rs.stopped() {
+			// the registries left unsynced fail the sync
+			failed.Store(true)
+			wg.Done()
+			continue
+		}
 		chSyncRegistryQueue <- struct{}{}
-		go rs.syncRegistry(registry, done)
+		go func(registry Registry) {
+			if err := rs.syncRegistry(registry, done); err != nil {
+				failed.Store(true)
+			}
+		}(registry)
 	}
 
 	wg.Wait()
+
+	if failed.Load() {
+		rs.lastSyncFailureAt.Store(time.Now().UnixNano())
+	} else {
+		rs.lastSyncAt.Store(time.Now().UnixNano())
+	}
 }
 
-func (rs registrySynchronizer) syncRegistry(registry registry, doneCallback func()) {
+func (rs registrySynchronizer) syncRegistry(registry Registry, doneCallback func()) error {
 	defer doneCallback()
 
 	logger.Debugf("syncing registry %s", registry.Address.Hex())
 
 	err := func() error {
-		contract, err := keeper_registry_contract.NewKeeperRegistryContract(registry.Address, rs.ethClient)
+		contract, err := NewRegistryContract(registry.Version, registry.Address, rs.ethClient)
 		if err != nil {
 			return err
 		}
-		registry, err = registry.SyncFromContract(contract)
+		var keeperAddresses []common.Address
+		registry, keeperAddresses, err = registry.SyncFromContract(contract)
 		if err != nil {
 			return err
 		}
+		if err = rs.syncMemberships(registry, keeperAddresses); err != nil {
+			return err
+		}
 		if err = rs.keeperStore.UpsertRegistry(registry); err != nil {
 			return err
 		}
 		if err = rs.addNewUpkeeps(contract, registry); err != nil {
 			return err
 		}
+		if err = rs.syncUpkeepsWithoutTarget(contract, registry); err != nil {
+			return err
+		}
 		if err = rs.deleteCanceledUpkeeps(contract, registry); err != nil {
 			return err
 		}
+		count, err := rs.keeperStore.UpkeepCountForRegistry(registry.ID)
+		if err != nil {
+			return err
+		}
+		promUpkeepsPerRegistry.WithLabelValues(registry.Address.Hex()).Set(float64(count))
 		return nil
 	}()
 
 	if err != nil {
+		promSyncErrors.WithLabelValues(registry.Address.Hex()).Inc()
 		logger.Errorf("unable to sync registry %s, err: %v", registry.Address.Hex(), err)
 	}
+	return err
+}
+
+// syncMemberships updates the keeper index of every membership on the registry.
+// Memberships whose keeper was removed from the keeper list are skipped, so that
+// the registry keeps syncing for the other keepers.
+func (rs registrySynchronizer) syncMemberships(registry Registry, keeperAddresses []common.Address) error {
+	memberships, err := rs.keeperStore.MembershipsForRegistries([]uint32{registry.ID})
+	if err != nil {
+		return err
+	}
+	for _, membership := range memberships {
+		membership.Registry = registry
+		synced, err := membership.SyncKeeperIndex(keeperAddresses)
+		if err != nil {
+			logger.Errorf("unable to sync membership %d: %v", membership.ID, err)
+			continue
+		}
+		if err = rs.keeperStore.UpsertMembership(synced); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (rs registrySynchronizer) addNewUpkeeps(
-	contract *keeper_registry_contract.KeeperRegistryContract,
-	reg registry,
+	contract RegistryContract,
+	reg Registry,
 ) error {
 	nextUpkeepID, err := rs.keeperStore.NextUpkeepIDForRegistry(reg)
 	if err != nil {
 		return err
 	}
 
-	countOnContractBig, err := contract.GetUpkeepCount(nil)
+	countOnContract, err := contract.GetUpkeepCount()
 	if err != nil {
 		return err
 	}
-	countOnContract := countOnContractBig.Uint64()
 
 	wg := sync.WaitGroup{}
 	wg.Add(int(countOnContract - nextUpkeepID))
@@ -158,30 +272,45 @@ func (rs registrySynchronizer) addNewUpkeeps(
 	return nil
 }
 
-func (rs registrySynchronizer) deleteCanceledUpkeeps(
-	contract *keeper_registry_contract.KeeperRegistryContract,
-	reg registry,
+// syncUpkeepsWithoutTarget resyncs the upkeeps which were synced before their
+// target and admin addresses were stored, so that upkeep filters apply to them.
+// Each upkeep is resynced once, even if its target is the zero address.
+func (rs registrySynchronizer) syncUpkeepsWithoutTarget(
+	contract RegistryContract,
+	reg Registry,
 ) error {
-	canceledBigs, err := contract.GetCanceledUpkeepList(nil)
+	upkeepIDs, err := rs.keeperStore.UpkeepIDsWithoutTarget(reg.ID)
 	if err != nil {
 		return err
 	}
-	canceled := make([]uint64, len(canceledBigs))
-	for idx, upkeepID := range canceledBigs {
-		canceled[idx] = upkeepID.Uint64()
+	for _, upkeepID := range upkeepIDs {
+		if err := rs.syncUpkeep(contract, reg, upkeepID, func() {}); err != nil {
+			logger.Error(err)
+		}
+	}
+	return nil
+}
+
+func (rs registrySynchronizer) deleteCanceledUpkeeps(
+	contract RegistryContract,
+	reg Registry,
+) error {
+	canceled, err := contract.GetCanceledUpkeepList()
+	if err != nil {
+		return err
 	}
 	return rs.keeperStore.BatchDeleteUpkeeps(reg.ID, canceled)
 }
 
 func (rs registrySynchronizer) syncUpkeep(
-	contract *keeper_registry_contract.KeeperRegistryContract,
-	registry registry,
+	contract RegistryContract,
+	registry Registry,
 	upkeepID uint64,
 	doneCallback func(),
 ) error {
 	defer doneCallback()
 
-	upkeepConfig, err := contract.GetUpkeep(nil, big.NewInt(int64(upkeepID)))
+	upkeepConfig, err := contract.GetUpkeep(upkeepID)
 	if err != nil {
 		return err
 	}
@@ -189,12 +318,15 @@ func (rs registrySynchronizer) syncUpkeep(
 	if err != nil {
 		return fmt.Errorf("unable to calculate positioning constant: %v", err)
 	}
-	newUpkeep := registration{
+	newUpkeep := Registration{
 		CheckData:           upkeepConfig.CheckData,
 		ExecuteGas:          upkeepConfig.ExecuteGas,
 		RegistryID:          registry.ID,
 		PositioningConstant: positioningConstant,
 		UpkeepID:            upkeepID,
+		Target:              upkeepConfig.Target,
+		Admin:               upkeepConfig.Admin,
+		TargetSynced:        true,
 	}
 
 	return rs.keeperStore.UpsertUpkeep(newUpkeep)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/jinzhu/gorm"
+	"github.com/smartcontractkit/chainlink/core/store/models"
 	"github.com/smartcontractkit/external-initiator/eitest"
 	"github.com/smartcontractkit/external-initiator/internal/mocks"
 	"github.com/smartcontractkit/external-initiator/store"
@@ -55,12 +56,15 @@ func setupRegistrySync(t *testing.T) (*gorm.DB, registrySynchronizer, *mocks.Eth
 	ethMock := new(mocks.EthClient)
 	regStore := NewStore(db.DB())
 	synchronizer := registrySynchronizer{
-		ethClient:   ethMock,
-		keeperStore: regStore,
-		interval:    syncInterval,
-		isRunning:   atomic.NewBool(false),
-		isSyncing:   atomic.NewBool(false),
-		chDone:      make(chan struct{}),
+		ethClient:         ethMock,
+		keeperStore:       regStore,
+		interval:          syncInterval,
+		isRunning:         atomic.NewBool(false),
+		startedAt:         atomic.NewInt64(0),
+		lastSyncAt:        atomic.NewInt64(0),
+		lastSyncFailureAt: atomic.NewInt64(0),
+		lifecycle:         newLifecycle(),
+		chSyncNow:         make(chan struct{}, 1),
 	}
 	return db.DB(), synchronizer, ethMock, cleanup
 }
@@ -78,31 +82,36 @@ func Test_RegistrySynchronizer_Start(t *testing.T) {
 
 	err = synchronizer.Start()
 	require.Error(t, err)
+
+	// can be started again once stopped
+	synchronizer.Stop()
+	synchronizer.Stop()
+	require.NoError(t, synchronizer.Start())
 }
 
 func Test_RegistrySynchronizer_AddsAndRemovesUpkeeps(t *testing.T) {
 	db, synchronizer, ethMock, cleanup := setupRegistrySync(t)
 	defer cleanup()
-	reg := newRegistry()
-	err := db.Create(&reg).Error
-	require.NoError(t, err)
+	reg, membership := createRegistry(t, db, newRegistry())
 
 	registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistryABI, reg.Address)
 	canceledUpkeeps := []*big.Int{big.NewInt(1)}
 	registryMock.MockResponse("getConfig", regConfig).Once()
-	registryMock.MockResponse("getKeeperList", []common.Address{reg.From}).Once()
+	registryMock.MockResponse("getKeeperList", []common.Address{membership.From}).Once()
 	registryMock.MockResponse("getCanceledUpkeepList", canceledUpkeeps).Once()
 	registryMock.MockResponse("getUpkeepCount", big.NewInt(3)).Once()
 	registryMock.MockResponse("getUpkeep", upkeep).Times(3) // sync all 3, then delete
 
 	synchronizer.performFullSync()
 
-	eitest.AssertCount(t, db, registry{}, 1)
-	eitest.AssertCount(t, db, registration{}, 2)
+	eitest.AssertCount(t, db, Registry{}, 1)
+	eitest.AssertCount(t, db, Registration{}, 2)
 	ethMock.AssertExpectations(t)
+	require.False(t, synchronizer.Status().LastSyncAt.IsZero())
+	require.True(t, synchronizer.Status().LastSyncFailureAt.IsZero())
 
-	var upkeepRegistration registration
-	err = db.Model(registration{}).First(&upkeepRegistration).Error
+	var upkeepRegistration Registration
+	err := db.Model(Registration{}).First(&upkeepRegistration).Error
 	require.NoError(t, err)
 
 	require.Equal(t, upkeep.CheckData, upkeepRegistration.CheckData)
@@ -110,14 +119,177 @@ func Test_RegistrySynchronizer_AddsAndRemovesUpkeeps(t *testing.T) {
 
 	canceledUpkeeps = []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(3)}
 	registryMock.MockResponse("getConfig", regConfig).Once()
-	registryMock.MockResponse("getKeeperList", []common.Address{reg.From}).Once()
+	registryMock.MockResponse("getKeeperList", []common.Address{membership.From}).Once()
 	registryMock.MockResponse("getCanceledUpkeepList", canceledUpkeeps).Once()
 	registryMock.MockResponse("getUpkeepCount", big.NewInt(5)).Once()
 	registryMock.MockResponse("getUpkeep", upkeep).Times(2) // two new upkeeps to sync
 
 	synchronizer.performFullSync()
 
-	eitest.AssertCount(t, db, registry{}, 1)
-	eitest.AssertCount(t, db, registration{}, 2)
+	eitest.AssertCount(t, db, Registry{}, 1)
+	eitest.AssertCount(t, db, Registration{}, 2)
+	ethMock.AssertExpectations(t)
+}
+
+func Test_RegistrySynchronizer_SyncsV1_1Registries(t *testing.T) {
+	db, synchronizer, ethMock, cleanup := setupRegistrySync(t)
+	defer cleanup()
+	reg := newRegistry()
+	reg.Version = RegistryVersion1_1
+	reg, membership := createRegistry(t, db, reg)
+
+	regConfig1_1 := struct {
+		PaymentPremiumPPB    uint32
+		BlockCountPerTurn    *big.Int
+		CheckGasLimit        uint32
+		StalenessSeconds     *big.Int
+		GasCeilingMultiplier uint16
+		FallbackGasPrice     *big.Int
+		FallbackLinkPrice    *big.Int
+	}{
+		PaymentPremiumPPB:    100,
+		BlockCountPerTurn:    big.NewInt(40),
+		CheckGasLimit:        3_000_000,
+		StalenessSeconds:     big.NewInt(3600),
+		GasCeilingMultiplier: 2,
+		FallbackGasPrice:     big.NewInt(1000000),
+		FallbackLinkPrice:    big.NewInt(1000000),
+	}
+	upkeep1_1 := struct {
+		Target              common.Address
+		ExecuteGas          uint32
+		CheckData           []byte
+		Balance             *big.Int
+		LastKeeper          common.Address
+		Admin               common.Address
+		MaxValidBlocknumber uint64
+		AmountSpent         *big.Int
+	}{
+		Target:              upkeep.Target,
+		ExecuteGas:          upkeep.ExecuteGas,
+		CheckData:           upkeep.CheckData,
+		Balance:             upkeep.Balance,
+		LastKeeper:          upkeep.LastKeeper,
+		Admin:               upkeep.Admin,
+		MaxValidBlocknumber: upkeep.MaxValidBlocknumber,
+		AmountSpent:         big.NewInt(0),
+	}
+
+	registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistry1_1ABI, reg.Address)
+	registryMock.MockResponse("getConfig", regConfig1_1).Once()
+	registryMock.MockResponse("getKeeperList", []common.Address{membership.From}).Once()
+	registryMock.MockResponse("getCanceledUpkeepList", []*big.Int{}).Once()
+	registryMock.MockResponse("getUpkeepCount", big.NewInt(2)).Once()
+	registryMock.MockResponse("getUpkeep", upkeep1_1).Twice()
+
+	synchronizer.performFullSync()
+
+	eitest.AssertCount(t, db, Registration{}, 2)
 	ethMock.AssertExpectations(t)
+
+	var syncedRegistry Registry
+	err := db.First(&syncedRegistry).Error
+	require.NoError(t, err)
+	require.Equal(t, uint32(40), syncedRegistry.BlockCountPerTurn)
+	require.Equal(t, uint32(3_000_000), syncedRegistry.CheckGas)
+	require.Equal(t, RegistryVersion1_1, syncedRegistry.Version)
+}
+
+func Test_RegistrySynchronizer_TriggerSync(t *testing.T) {
+	db, synchronizer, ethMock, cleanup := setupRegistrySync(t)
+	defer cleanup()
+	synchronizer.interval = time.Hour
+	reg, membership := createRegistry(t, db, newRegistry())
+
+	registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistryABI, reg.Address)
+	registryMock.MockResponse("getConfig", regConfig).Once()
+	registryMock.MockResponse("getKeeperList", []common.Address{membership.From}).Once()
+	registryMock.MockResponse("getCanceledUpkeepList", []*big.Int{}).Once()
+	registryMock.MockResponse("getUpkeepCount", big.NewInt(1)).Once()
+	registryMock.MockResponse("getUpkeep", upkeep).Once()
+
+	err := synchronizer.Start()
+	require.NoError(t, err)
+	defer synchronizer.Stop()
+
+	synchronizer.TriggerSync()
+	eitest.WaitForCount(t, db, Registration{}, 1)
+	ethMock.AssertExpectations(t)
+}
+
+func Test_RegistrySynchronizer_BackfillsUpkeepTargets(t *testing.T) {
+	db, synchronizer, ethMock, cleanup := setupRegistrySync(t)
+	defer cleanup()
+	reg, membership := createRegistry(t, db, newRegistry())
+	require.NoError(t, synchronizer.keeperStore.UpsertUpkeep(newRegistration(reg, 0)))
+
+	registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistryABI, reg.Address)
+	registryMock.MockResponse("getConfig", regConfig).Once()
+	registryMock.MockResponse("getKeeperList", []common.Address{membership.From}).Once()
+	registryMock.MockResponse("getCanceledUpkeepList", []*big.Int{}).Once()
+	registryMock.MockResponse("getUpkeepCount", big.NewInt(1)).Once()
+	registryMock.MockResponse("getUpkeep", upkeep).Once()
+
+	synchronizer.performFullSync()
+	ethMock.AssertExpectations(t)
+
+	registration, err := synchronizer.keeperStore.UpkeepByID(reg.ID, 0)
+	require.NoError(t, err)
+	require.Equal(t, upkeep.Target, registration.Target)
+	require.Equal(t, upkeep.Admin, registration.Admin)
+
+	// an upkeep whose target is the zero address is backfilled once
+	require.NoError(t, synchronizer.keeperStore.UpsertUpkeep(newRegistration(reg, 1)))
+	withoutTarget := upkeep
+	withoutTarget.Target = common.Address{}
+	registryMock.MockResponse("getConfig", regConfig).Twice()
+	registryMock.MockResponse("getKeeperList", []common.Address{membership.From}).Twice()
+	registryMock.MockResponse("getCanceledUpkeepList", []*big.Int{}).Twice()
+	registryMock.MockResponse("getUpkeepCount", big.NewInt(2)).Twice()
+	registryMock.MockResponse("getUpkeep", withoutTarget).Once()
+
+	synchronizer.performFullSync()
+	synchronizer.performFullSync()
+	ethMock.AssertExpectations(t)
+}
+
+func Test_RegistrySynchronizer_SyncsMemberships(t *testing.T) {
+	db, synchronizer, ethMock, cleanup := setupRegistrySync(t)
+	defer cleanup()
+	reg, membership := createRegistry(t, db, newRegistry())
+	other := NewMembership(reg, eitest.NewAddress(), models.NewID())
+	require.NoError(t, db.Create(&other).Error)
+
+	registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistryABI, reg.Address)
+	registryMock.MockResponse("getConfig", regConfig).Once()
+	registryMock.MockResponse("getKeeperList", []common.Address{eitest.NewAddress(), other.From, membership.From}).Once()
+	registryMock.MockResponse("getCanceledUpkeepList", []*big.Int{}).Once()
+	registryMock.MockResponse("getUpkeepCount", big.NewInt(0)).Once()
+
+	synchronizer.performFullSync()
+	ethMock.AssertExpectations(t)
+
+	memberships, err := synchronizer.keeperStore.MembershipsForRegistries([]uint32{reg.ID})
+	require.NoError(t, err)
+	require.Len(t, memberships, 2)
+	require.Equal(t, uint32(2), memberships[0].KeeperIndex)
+	require.Equal(t, uint32(1), memberships[1].KeeperIndex)
+	require.Equal(t, uint32(3), memberships[0].Registry.NumKeepers)
+
+	// skips the membership of a keeper which was removed, the others keep syncing
+	registryMock.MockResponse("getConfig", regConfig).Once()
+	registryMock.MockResponse("getKeeperList", []common.Address{membership.From}).Once()
+	registryMock.MockResponse("getCanceledUpkeepList", []*big.Int{}).Once()
+	registryMock.MockResponse("getUpkeepCount", big.NewInt(0)).Once()
+
+	synchronizer.performFullSync()
+	ethMock.AssertExpectations(t)
+	require.True(t, synchronizer.Status().LastSyncFailureAt.IsZero())
+
+	memberships, err = synchronizer.keeperStore.MembershipsForRegistries([]uint32{reg.ID})
+	require.NoError(t, err)
+	require.Len(t, memberships, 2)
+	require.Equal(t, membership.ID, memberships[0].ID)
+	require.Equal(t, uint32(0), memberships[0].KeeperIndex)
+	require.Equal(t, uint32(1), memberships[0].Registry.NumKeepers)
 }
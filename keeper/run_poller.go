@@ -0,0 +1,133 @@
+package keeper
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/external-initiator/chainlink"
+	"go.uber.org/atomic"
+)
+
+const (
+	// pollBatchSize caps the number of pending job runs polled per interval
+	pollBatchSize = 100
+)
+
+var promJobRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "keeper_job_runs",
+	Help: "The number of triggered job runs which reached a terminal state, by status",
+}, []string{"status"})
+
+// RunPoller periodically queries the Chainlink node for the status of pending job
+// runs, recording their tx hash and terminal state. Runs still pending after the
+// timeout are marked as timed out and no longer polled.
+type RunPoller interface {
+	Start() error
+	Stop()
+	// Err receives the error the poller failed with while running
+	Err() <-chan error
+}
+
+func NewRunPoller(keeperStore Store, clNode chainlink.Client, interval, timeout time.Duration) RunPoller {
+	return runPoller{
+		keeperStore: keeperStore,
+		clNode:      clNode,
+		interval:    interval,
+		timeout:     timeout,
+		isRunning:   atomic.NewBool(false),
+		lifecycle:   newLifecycle(),
+	}
+}
+
+type runPoller struct {
+	keeperStore Store
+	clNode      chainlink.Client
+	interval    time.Duration
+	timeout     time.Duration
+	isRunning   *atomic.Bool
+
+	*lifecycle
+}
+
+func (rp runPoller) Start() error {
+	if rp.isRunning.Load() {
+		return errors.New("already started")
+	}
+	rp.restart()
+	rp.isRunning.Store(true)
+	rp.spawn(rp.run)
+	return nil
+}
+
+func (rp runPoller) Stop() {
+	rp.stop()
+	rp.isRunning.Store(false)
+}
+
+func (rp runPoller) run() {
+	ticker := time.NewTicker(rp.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rp.chDone:
+			return
+		case <-ticker.C:
+			rp.poll()
+		}
+	}
+}
+
+// poll updates the status of the least recently polled pending job runs, so that
+// every pending run is polled in turn when there are more than the batch size
+func (rp runPoller) poll() {
+	runs, err := rp.keeperStore.PendingJobRuns(pollBatchSize)
+	if err != nil {
+		logger.Errorf("unable to load pending job runs: %v", err)
+		return
+	}
+
+	polled := make([]uint32, 0, len(runs))
+	defer func() {
+		if err := rp.keeperStore.MarkJobRunsPolled(polled, time.Now()); err != nil {
+			logger.Errorf("unable to record the polled job runs: %v", err)
+		}
+	}()
+	for _, run := range runs {
+		if rp.stopped() {
+			return
+		}
+		polled = append(polled, run.ID)
+		jobID := run.Membership.JobID.String()
+		status, err := rp.clNode.RunStatus(jobID, run.Membership.JobEndpoint, run.RunID)
+		unavailable := errors.Is(err, chainlink.ErrCircuitOpen) || errors.Is(err, chainlink.ErrNoAPIToken)
+		if err != nil && !unavailable {
+			logger.Warnf("unable to fetch the status of run %s of job %s: %v", run.RunID, jobID, err)
+		}
+		if err != nil || !status.Status.Terminal() {
+			// runs of unreachable nodes, or nodes without an API token, time out too
+			if time.Since(run.CreatedAt) > rp.timeout {
+				rp.updateStatus(run, chainlink.Run{Status: RunStatusTimedOut})
+			}
+			continue
+		}
+		rp.updateStatus(run, status)
+	}
+}
+
+func (rp runPoller) updateStatus(run JobRun, status chainlink.Run) {
+	err := rp.keeperStore.UpdateJobRunStatus(run.ID, status.Status, status.TxHash, status.Error)
+	if err != nil {
+		logger.Errorf("unable to update the status of run %s: %v", run.RunID, err)
+		return
+	}
+	promJobRuns.WithLabelValues(string(status.Status)).Inc()
+	if status.Status == chainlink.RunStatusCompleted {
+		logger.Infow("Job run completed", "runID", run.RunID, "upkeepID", run.UpkeepID, "txHash", status.TxHash)
+	} else {
+		logger.Warnw("Job run failed", "runID", run.RunID, "upkeepID", run.UpkeepID, "status", status.Status, "error", status.Error)
+	}
+}
@@ -0,0 +1,115 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/external-initiator/chainlink"
+	"github.com/smartcontractkit/external-initiator/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RunPoller_RecordsTerminalRuns(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+	_, membership := createRegistry(t, db, newRegistry())
+	clMock := new(mocks.ChainlinkClient)
+	poller := NewRunPoller(regStore, clMock, time.Hour, time.Hour).(runPoller)
+	jobID := membership.JobID.String()
+
+	createRun := func(runID string) JobRun {
+		run, err := regStore.CreateJobRun(JobRun{MembershipID: membership.ID, UpkeepID: 1, BlockNumber: 20, RunID: runID, Status: chainlink.RunStatusPending})
+		require.NoError(t, err)
+		return run
+	}
+	createRun("completed")
+	createRun("errored")
+	createRun("pending")
+	stale := createRun("stale")
+	require.NoError(t, db.Model(&stale).UpdateColumn("created_at", time.Now().Add(-2*time.Hour)).Error)
+	unpolled := createRun("unpolled")
+	require.NoError(t, db.Model(&unpolled).UpdateColumn("created_at", time.Now().Add(-2*time.Hour)).Error)
+
+	clMock.On("RunStatus", jobID, membership.JobEndpoint, "completed").
+		Return(chainlink.Run{ID: "completed", Status: chainlink.RunStatusCompleted, TxHash: "0xabc"}, nil).Once()
+	clMock.On("RunStatus", jobID, membership.JobEndpoint, "errored").
+		Return(chainlink.Run{ID: "errored", Status: chainlink.RunStatusErrored, Error: "out of gas"}, nil).Once()
+	clMock.On("RunStatus", jobID, membership.JobEndpoint, "pending").
+		Return(chainlink.Run{ID: "pending", Status: chainlink.RunStatusPending}, nil).Once()
+	clMock.On("RunStatus", jobID, membership.JobEndpoint, "stale").
+		Return(chainlink.Run{}, errors.New("unreachable")).Once()
+	clMock.On("RunStatus", jobID, membership.JobEndpoint, "unpolled").
+		Return(chainlink.Run{}, chainlink.ErrNoAPIToken).Once()
+
+	poller.poll()
+	clMock.AssertExpectations(t)
+
+	runs, count, err := regStore.PaginatedJobRuns(nil, "", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 5, count)
+	statuses := make(map[string]JobRun)
+	for _, run := range runs {
+		statuses[run.RunID] = run
+	}
+	assert.Equal(t, chainlink.RunStatusCompleted, statuses["completed"].Status)
+	assert.Equal(t, "0xabc", statuses["completed"].TxHash)
+	assert.Equal(t, chainlink.RunStatusErrored, statuses["errored"].Status)
+	assert.Equal(t, "out of gas", statuses["errored"].Error)
+	assert.Equal(t, chainlink.RunStatusPending, statuses["pending"].Status)
+	assert.Equal(t, RunStatusTimedOut, statuses["stale"].Status)
+	assert.Equal(t, RunStatusTimedOut, statuses["unpolled"].Status)
+
+	pending, err := regStore.PendingJobRuns(10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "pending", pending[0].RunID)
+
+	errored, count, err := regStore.PaginatedJobRuns(nil, chainlink.RunStatusErrored, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	assert.Equal(t, membership.From, errored[0].Membership.From)
+}
+
+func Test_RunPoller_PollsLeastRecentlyPolledRunsFirst(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+	_, membership := createRegistry(t, db, newRegistry())
+	clMock := new(mocks.ChainlinkClient)
+	poller := NewRunPoller(regStore, clMock, time.Hour, time.Hour).(runPoller)
+
+	var runs []JobRun
+	for _, runID := range []string{"first", "second", "third"} {
+		run, err := regStore.CreateJobRun(JobRun{MembershipID: membership.ID, UpkeepID: 1, BlockNumber: 20, RunID: runID, Status: chainlink.RunStatusPending})
+		require.NoError(t, err)
+		runs = append(runs, run)
+	}
+	now := time.Now()
+	require.NoError(t, regStore.MarkJobRunsPolled([]uint32{runs[0].ID}, now))
+	require.NoError(t, regStore.MarkJobRunsPolled([]uint32{runs[1].ID}, now.Add(-time.Minute)))
+
+	pending, err := regStore.PendingJobRuns(2)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "third", pending[0].RunID)
+	assert.Equal(t, "second", pending[1].RunID)
+
+	clMock.On("RunStatus", membership.JobID.String(), membership.JobEndpoint, "third").
+		Return(chainlink.Run{ID: "third", Status: chainlink.RunStatusPending}, nil).Once()
+	clMock.On("RunStatus", membership.JobID.String(), membership.JobEndpoint, "second").
+		Return(chainlink.Run{}, chainlink.ErrNoAPIToken).Once()
+	clMock.On("RunStatus", membership.JobID.String(), membership.JobEndpoint, "first").
+		Return(chainlink.Run{ID: "first", Status: chainlink.RunStatusPending}, nil).Once()
+	poller.poll()
+	clMock.AssertExpectations(t)
+
+	// every run was polled once, in order
+	pending, err = regStore.PendingJobRuns(3)
+	require.NoError(t, err)
+	require.Len(t, pending, 3)
+	for _, run := range pending {
+		require.NotNil(t, run.LastPolledAt)
+		assert.True(t, run.LastPolledAt.After(now.Add(-time.Second)))
+	}
+}
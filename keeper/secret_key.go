@@ -0,0 +1,59 @@
+package keeper
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// SecretKey encrypts the secrets the EI has to keep, rather than hash, at rest
+// with AES-256-GCM. Every secret is sealed with a random nonce, prepended to it.
+type SecretKey struct {
+	aead cipher.AEAD
+}
+
+// ParseSecretKey returns the SecretKey of the hex encoded 32 byte key, or nil if it is empty
+func ParseSecretKey(hexKey string) (*SecretKey, error) {
+	if hexKey == "" {
+		return nil, nil
+	}
+	key, err := hex.DecodeString(strings.TrimPrefix(hexKey, "0x"))
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("the secrets key must be 32 hex encoded bytes")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretKey{aead: aead}, nil
+}
+
+// Encrypt seals the secret, binding it to the additionalData which
+// has to be given again to decrypt it
+func (k *SecretKey) Encrypt(secret string, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return k.aead.Seal(nonce, nonce, []byte(secret), additionalData), nil
+}
+
+// Decrypt opens a secret sealed by Encrypt with the same key and additionalData
+func (k *SecretKey) Decrypt(encrypted, additionalData []byte) (string, error) {
+	nonceSize := k.aead.NonceSize()
+	if len(encrypted) < nonceSize {
+		return "", errors.New("the encrypted secret is too short")
+	}
+	secret, err := k.aead.Open(nil, encrypted[:nonceSize], encrypted[nonceSize:], additionalData)
+	if err != nil {
+		return "", errors.New("unable to decrypt the secret, the secrets key may have changed")
+	}
+	return string(secret), nil
+}
@@ -0,0 +1,50 @@
+package keeper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecretsKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func TestParseSecretKey(t *testing.T) {
+	key, err := ParseSecretKey("")
+	require.NoError(t, err)
+	assert.Nil(t, key)
+
+	key, err = ParseSecretKey("0x" + testSecretsKey)
+	require.NoError(t, err)
+	assert.NotNil(t, key)
+
+	for _, invalid := range []string{"secret", testSecretsKey[:62], testSecretsKey + "20"} {
+		_, err = ParseSecretKey(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSecretKey_EncryptDecrypt(t *testing.T) {
+	key, err := ParseSecretKey(testSecretsKey)
+	require.NoError(t, err)
+
+	encrypted, err := key.Encrypt("secret", []byte("key"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "secret")
+	other, err := key.Encrypt("secret", []byte("key"))
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, other, "nonces are random")
+
+	secret, err := key.Decrypt(encrypted, []byte("key"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", secret)
+
+	_, err = key.Decrypt(encrypted, []byte("otherKey"))
+	assert.Error(t, err)
+	_, err = key.Decrypt(encrypted[:4], []byte("key"))
+	assert.Error(t, err)
+	otherKey, err := ParseSecretKey("1f" + testSecretsKey[2:])
+	require.NoError(t, err)
+	_, err = otherKey.Decrypt(encrypted, []byte("key"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,269 @@
+package keeper
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/external-initiator/chainlink"
+	"go.uber.org/atomic"
+)
+
+const (
+	// dispatchBatchSize caps the number of job triggers sent per interval
+	dispatchBatchSize = 100
+)
+
+const (
+	triggerOutcomeSent     = "sent"
+	triggerOutcomeRetried  = "retried"
+	triggerOutcomeExpired  = "expired"
+	triggerOutcomeRejected = "rejected"
+)
+
+var promJobTriggers = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "keeper_job_triggers",
+	Help: "The number of attempts to send job run triggers from the outbox, by outcome",
+}, []string{"outcome"})
+
+// BackoffConfig bounds the exponential backoff between attempts to send a job trigger,
+// or to restart a failed component
+type BackoffConfig struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// Delay returns the backoff before the next attempt after the given number of failed
+// attempts, doubling from Min up to Max, with a random jitter of up to half the delay
+func (config BackoffConfig) Delay(attempts uint32) time.Duration {
+	delay := config.Min
+	for i := uint32(1); i < attempts && delay < config.Max; i++ {
+		delay *= 2
+	}
+	if delay > config.Max {
+		delay = config.Max
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// TriggerDispatcher sends the job run triggers written to the outbox to the Chainlink
+// node, so that performs aren't lost while the node is unreachable. Failed triggers are
+// retried with exponential backoff until they expire, and become dead letters if they
+// expire or are rejected by the node.
+type TriggerDispatcher interface {
+	Start() error
+	Stop()
+	// Err receives the error the dispatcher failed with while running
+	Err() <-chan error
+	Status() DispatcherStatus
+}
+
+// DispatcherStatus reports the progress of the TriggerDispatcher,
+// zero times mean that the event hasn't happened yet
+type DispatcherStatus struct {
+	LastTriggerAt        time.Time
+	LastTriggerFailureAt time.Time
+}
+
+// NewTriggerDispatcher returns a TriggerDispatcher sending the due job triggers every
+// interval, latestBlockNumber returns the block number against which expiries are checked
+func NewTriggerDispatcher(keeperStore Store, clNode chainlink.Client, interval time.Duration, backoff BackoffConfig, latestBlockNumber func() int64) TriggerDispatcher {
+	return triggerDispatcher{
+		keeperStore:          keeperStore,
+		clNode:               clNode,
+		interval:             interval,
+		backoff:              backoff,
+		latestBlockNumber:    latestBlockNumber,
+		isRunning:            atomic.NewBool(false),
+		lastTriggerAt:        atomic.NewInt64(0),
+		lastTriggerFailureAt: atomic.NewInt64(0),
+		lifecycle:            newLifecycle(),
+	}
+}
+
+type triggerDispatcher struct {
+	keeperStore       Store
+	clNode            chainlink.Client
+	interval          time.Duration
+	backoff           BackoffConfig
+	latestBlockNumber func() int64
+	isRunning         *atomic.Bool
+
+	// unix nano timestamps reported in Status()
+	lastTriggerAt        *atomic.Int64
+	lastTriggerFailureAt *atomic.Int64
+
+	*lifecycle
+}
+
+func (td triggerDispatcher) Start() error {
+	if td.isRunning.Load() {
+		return errors.New("already started")
+	}
+	td.restart()
+	td.isRunning.Store(true)
+	td.spawn(td.run)
+	return nil
+}
+
+// Stop waits for the job trigger being sent, the remaining ones are sent once started again
+func (td triggerDispatcher) Stop() {
+	td.stop()
+	td.isRunning.Store(false)
+}
+
+func (td triggerDispatcher) Status() DispatcherStatus {
+	return DispatcherStatus{
+		LastTriggerAt:        unixNanoToTime(td.lastTriggerAt.Load()),
+		LastTriggerFailureAt: unixNanoToTime(td.lastTriggerFailureAt.Load()),
+	}
+}
+
+func (td triggerDispatcher) run() {
+	ticker := time.NewTicker(td.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-td.chDone:
+			return
+		case <-ticker.C:
+			td.dispatch()
+		}
+	}
+}
+
+// dispatch sends the due job triggers, dead lettering the expired ones. The triggers
+// of each node are sent concurrently, so that a node timing out doesn't delay the others.
+func (td triggerDispatcher) dispatch() {
+	triggers, err := td.keeperStore.DueJobTriggers(time.Now(), dispatchBatchSize)
+	if err != nil {
+		logger.Errorf("unable to load due job triggers: %v", err)
+		return
+	}
+
+	blockNumber := td.latestBlockNumber()
+	var nodes []uint32
+	byNode := make(map[uint32][]JobTrigger)
+	for _, trigger := range triggers {
+		if trigger.Expired(blockNumber) {
+			trigger.Status = TriggerDead
+			if trigger.LastError == "" {
+				trigger.LastError = fmt.Sprintf("expired at block %d", trigger.ExpiresAtBlock)
+			} else {
+				trigger.LastError = fmt.Sprintf("expired at block %d after: %s", trigger.ExpiresAtBlock, trigger.LastError)
+			}
+			td.update(trigger, triggerOutcomeExpired)
+			continue
+		}
+		node := nodeKey(trigger.Membership)
+		if _, ok := byNode[node]; !ok {
+			nodes = append(nodes, node)
+		}
+		byNode[node] = append(byNode[node], trigger)
+	}
+
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(triggers []JobTrigger) {
+			defer wg.Done()
+			td.sendAll(triggers)
+		}(byNode[node])
+	}
+	wg.Wait()
+}
+
+// sendAll sends the job triggers of a node in order, the remaining ones are left for
+// the next dispatch once its circuit breaker is open
+func (td triggerDispatcher) sendAll(triggers []JobTrigger) {
+	for _, trigger := range triggers {
+		if td.stopped() || !td.send(trigger) {
+			return
+		}
+	}
+}
+
+// send sends the job trigger, it returns false without counting an attempt if the
+// circuit breaker of the node is open, as the node's remaining triggers would fail too
+func (td triggerDispatcher) send(trigger JobTrigger) bool {
+	membership := trigger.Membership
+	jobID := membership.JobID.String()
+
+	runID, err := td.clNode.TriggerJob(jobID, membership.JobEndpoint, trigger.IdempotencyKey, trigger.Payload)
+	if errors.Is(err, chainlink.ErrCircuitOpen) {
+		logger.Debugw("Not sending job run triggers while the circuit breaker is open", "jobID", jobID)
+		return false
+	}
+	trigger.Attempts++
+	if err != nil {
+		td.lastTriggerFailureAt.Store(time.Now().UnixNano())
+		trigger.LastError = err.Error()
+		if isRejected(err) {
+			logger.Warnw("Job run trigger rejected by the Chainlink node", "jobID", jobID, "upkeepID", trigger.UpkeepID, "error", err)
+			trigger.Status = TriggerDead
+			td.update(trigger, triggerOutcomeRejected)
+			return true
+		}
+		logger.Warnw("Unable to send job run trigger, will retry", "jobID", jobID, "upkeepID", trigger.UpkeepID, "attempts", trigger.Attempts, "error", err)
+		trigger.NextAttemptAt = time.Now().Add(td.backoff.Delay(trigger.Attempts))
+		td.update(trigger, triggerOutcomeRetried)
+		return true
+	}
+	td.lastTriggerAt.Store(time.Now().UnixNano())
+	trigger.Status = TriggerSent
+	trigger.LastError = ""
+	td.update(trigger, triggerOutcomeSent)
+
+	if runID == "" || !td.clNode.HasAPIToken(jobID) {
+		// the status of the run can't be polled
+		return true
+	}
+	_, err = td.keeperStore.CreateJobRun(JobRun{
+		MembershipID: trigger.MembershipID,
+		UpkeepID:     trigger.UpkeepID,
+		BlockNumber:  trigger.BlockNumber,
+		RunID:        runID,
+		Status:       chainlink.RunStatusPending,
+	})
+	if err != nil {
+		logger.Errorf("unable to record run %s of job %s: %v", runID, jobID, err)
+	}
+	return true
+}
+
+func (td triggerDispatcher) update(trigger JobTrigger, outcome string) {
+	if err := td.keeperStore.UpdateJobTrigger(trigger); err != nil {
+		logger.Errorf("unable to update job trigger %d: %v", trigger.ID, err)
+		return
+	}
+	promJobTriggers.WithLabelValues(outcome).Inc()
+}
+
+// isRejected returns whether the Chainlink node answered the trigger with a client
+// error, which won't succeed when retried. Rate limited triggers are retried.
+func isRejected(err error) bool {
+	var statusErr chainlink.StatusCodeError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	return statusErr.StatusCode >= 400 && statusErr.StatusCode < 500 && statusErr.StatusCode != http.StatusTooManyRequests
+}
+
+// nodeKey identifies the node of the membership, zero being the default node
+func nodeKey(membership Membership) uint32 {
+	if membership.NodeID == nil {
+		return 0
+	}
+	return *membership.NodeID
+}
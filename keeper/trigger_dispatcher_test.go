@@ -0,0 +1,268 @@
+package keeper
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/smartcontractkit/chainlink/core/store/models"
+	"github.com/smartcontractkit/external-initiator/chainlink"
+	"github.com/smartcontractkit/external-initiator/eitest"
+	"github.com/smartcontractkit/external-initiator/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoffConfig_Delay(t *testing.T) {
+	backoff := BackoffConfig{Min: time.Second, Max: 10 * time.Second}
+	for attempts, expected := range map[uint32]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 8 * time.Second,
+		5: 10 * time.Second,
+		9: 10 * time.Second,
+	} {
+		delay := backoff.Delay(attempts)
+		assert.True(t, delay >= expected/2 && delay <= expected, "attempt %d: %s", attempts, delay)
+	}
+	assert.Zero(t, BackoffConfig{}.Delay(3))
+}
+
+func Test_TriggerDispatcher_Dispatch(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+	_, membership := createRegistry(t, db, newRegistry())
+	clMock := new(mocks.ChainlinkClient)
+	latestBlock := int64(20)
+	dispatcher := NewTriggerDispatcher(regStore, clMock, time.Hour, BackoffConfig{Min: time.Minute, Max: time.Hour}, func() int64 {
+		return latestBlock
+	}).(triggerDispatcher)
+	jobID := membership.JobID.String()
+
+	createTrigger := func(payload string, expiresAtBlock int64) JobTrigger {
+		trigger, err := regStore.CreateJobTrigger(JobTrigger{
+			MembershipID:   membership.ID,
+			UpkeepID:       1,
+			BlockNumber:    20,
+			IdempotencyKey: "key-" + payload,
+			Payload:        []byte(payload),
+			Status:         TriggerPending,
+			NextAttemptAt:  time.Now(),
+			ExpiresAtBlock: expiresAtBlock,
+		})
+		require.NoError(t, err)
+		return trigger
+	}
+	reload := func(trigger JobTrigger) JobTrigger {
+		require.NoError(t, db.First(&trigger, trigger.ID).Error)
+		return trigger
+	}
+
+	sent := createTrigger(`"sent"`, 40)
+	retried := createTrigger(`"retried"`, 40)
+	rejected := createTrigger(`"rejected"`, 40)
+	expired := createTrigger(`"expired"`, 20)
+
+	clMock.On("TriggerJob", jobID, membership.JobEndpoint, `key-"sent"`, []byte(`"sent"`)).Return("run-1", nil).Once()
+	clMock.On("HasAPIToken", jobID).Return(true).Once()
+	clMock.On("TriggerJob", jobID, membership.JobEndpoint, `key-"retried"`, []byte(`"retried"`)).Return("", errors.New("connection refused")).Once()
+	clMock.On("TriggerJob", jobID, membership.JobEndpoint, `key-"rejected"`, []byte(`"rejected"`)).Return("", chainlink.StatusCodeError{StatusCode: http.StatusUnauthorized}).Once()
+
+	dispatcher.dispatch()
+	clMock.AssertExpectations(t)
+
+	sent = reload(sent)
+	assert.Equal(t, TriggerSent, sent.Status)
+	assert.Equal(t, uint32(1), sent.Attempts)
+	runs, err := regStore.PendingJobRuns(10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "run-1", runs[0].RunID)
+	assert.Equal(t, membership.ID, runs[0].MembershipID)
+	assert.Equal(t, int64(20), runs[0].BlockNumber)
+
+	retried = reload(retried)
+	assert.Equal(t, TriggerPending, retried.Status)
+	assert.Equal(t, uint32(1), retried.Attempts)
+	assert.Equal(t, "connection refused", retried.LastError)
+	assert.True(t, retried.NextAttemptAt.After(time.Now().Add(29*time.Second)))
+
+	rejected = reload(rejected)
+	assert.Equal(t, TriggerDead, rejected.Status)
+	assert.Contains(t, rejected.LastError, "401")
+
+	expired = reload(expired)
+	assert.Equal(t, TriggerDead, expired.Status)
+	assert.Equal(t, uint32(0), expired.Attempts)
+	assert.Equal(t, "expired at block 20", expired.LastError)
+
+	status := dispatcher.Status()
+	assert.False(t, status.LastTriggerAt.IsZero())
+	assert.False(t, status.LastTriggerFailureAt.IsZero())
+
+	t.Run("doesn't send triggers before their next attempt", func(t *testing.T) {
+		dispatcher.dispatch()
+		clMock.AssertExpectations(t)
+	})
+
+	t.Run("expires triggers after failed attempts", func(t *testing.T) {
+		require.NoError(t, db.Model(&retried).UpdateColumn("next_attempt_at", time.Now()).Error)
+		latestBlock = 40
+
+		dispatcher.dispatch()
+		clMock.AssertExpectations(t)
+		retried = reload(retried)
+		assert.Equal(t, TriggerDead, retried.Status)
+		assert.Equal(t, "expired at block 40 after: connection refused", retried.LastError)
+	})
+
+	t.Run("replays dead triggers", func(t *testing.T) {
+		dead, count, err := regStore.PaginatedJobTriggers(nil, TriggerDead, 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+		assert.Equal(t, retried.ID, dead[2].ID)
+
+		replayed, err := regStore.ReplayJobTrigger(nil, retried.ID)
+		require.NoError(t, err)
+		assert.Equal(t, TriggerPending, replayed.Status)
+		assert.Zero(t, replayed.Attempts)
+		assert.Zero(t, replayed.ExpiresAtBlock)
+
+		_, err = regStore.ReplayJobTrigger(nil, sent.ID)
+		require.True(t, gorm.IsRecordNotFoundError(err))
+
+		clMock.On("TriggerJob", jobID, membership.JobEndpoint, `key-"retried"`, []byte(`"retried"`)).Return("", nil).Once()
+		dispatcher.dispatch()
+		clMock.AssertExpectations(t)
+		assert.Equal(t, TriggerSent, reload(retried).Status)
+	})
+
+	t.Run("doesn't record runs which can't be polled without an API token", func(t *testing.T) {
+		unpolled := createTrigger(`"unpolled"`, 60)
+		clMock.On("TriggerJob", jobID, membership.JobEndpoint, `key-"unpolled"`, []byte(`"unpolled"`)).Return("run-2", nil).Once()
+		clMock.On("HasAPIToken", jobID).Return(false).Once()
+		dispatcher.dispatch()
+		clMock.AssertExpectations(t)
+		assert.Equal(t, TriggerSent, reload(unpolled).Status)
+	})
+
+	eitest.AssertCount(t, db, JobRun{}, 1)
+}
+
+func Test_TriggerDispatcher_SendsThroughJobEndpoint(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+	reg, _ := createRegistry(t, db, newRegistry())
+	membership := newMembership(reg)
+	membership.From = eitest.NewAddress()
+	membership.JobEndpoint = chainlink.WebhookJobEndpoint
+	require.NoError(t, db.Create(&membership).Error)
+	clMock := new(mocks.ChainlinkClient)
+	dispatcher := NewTriggerDispatcher(regStore, clMock, time.Hour, BackoffConfig{}, func() int64 { return 0 }).(triggerDispatcher)
+
+	_, err := regStore.CreateJobTrigger(JobTrigger{MembershipID: membership.ID, IdempotencyKey: "key", Payload: []byte(`{}`), Status: TriggerPending, NextAttemptAt: time.Now()})
+	require.NoError(t, err)
+	clMock.On("TriggerJob", membership.JobID.String(), chainlink.WebhookJobEndpoint, "key", mock.Anything).Return("", nil).Once()
+
+	dispatcher.dispatch()
+	clMock.AssertExpectations(t)
+	eitest.AssertCount(t, db, JobRun{}, 0)
+}
+
+func TestRegistryStore_CreateJobTrigger_Idempotent(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+	_, membership := createRegistry(t, db, newRegistry())
+
+	trigger := JobTrigger{
+		MembershipID:   membership.ID,
+		IdempotencyKey: idempotencyKey(registryAddress, 1, 20),
+		Payload:        []byte(`{}`),
+		Status:         TriggerPending,
+		NextAttemptAt:  time.Now(),
+	}
+	created, err := regStore.CreateJobTrigger(trigger)
+	require.NoError(t, err)
+	assert.NotZero(t, created.ID)
+
+	_, err = regStore.CreateJobTrigger(trigger)
+	require.Equal(t, ErrJobTriggerExists, err)
+
+	trigger.IdempotencyKey = idempotencyKey(registryAddress, 1, 21)
+	_, err = regStore.CreateJobTrigger(trigger)
+	require.NoError(t, err)
+	eitest.AssertCount(t, db, JobTrigger{}, 2)
+}
+
+func Test_TriggerDispatcher_SkipsNodeWhileCircuitOpen(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+	reg, membership := createRegistry(t, db, newRegistry())
+	node := createNode(t, db, "other")
+	otherMembership := NewMembership(reg, eitest.NewAddress(), models.NewID())
+	otherMembership.NodeID = &node.ID
+	require.NoError(t, db.Create(&otherMembership).Error)
+	clMock := new(mocks.ChainlinkClient)
+	dispatcher := NewTriggerDispatcher(regStore, clMock, time.Hour, BackoffConfig{}, func() int64 { return 0 }).(triggerDispatcher)
+
+	for _, key := range []string{"a", "b"} {
+		_, err := regStore.CreateJobTrigger(JobTrigger{MembershipID: membership.ID, IdempotencyKey: key, Payload: []byte(`{}`), Status: TriggerPending, NextAttemptAt: time.Now()})
+		require.NoError(t, err)
+	}
+	_, err := regStore.CreateJobTrigger(JobTrigger{MembershipID: otherMembership.ID, IdempotencyKey: "c", Payload: []byte(`{}`), Status: TriggerPending, NextAttemptAt: time.Now()})
+	require.NoError(t, err)
+	clMock.On("TriggerJob", membership.JobID.String(), mock.Anything, "a", mock.Anything).Return("", chainlink.ErrCircuitOpen).Once()
+	clMock.On("TriggerJob", otherMembership.JobID.String(), mock.Anything, "c", mock.Anything).Return("", nil).Once()
+
+	dispatcher.dispatch()
+	clMock.AssertExpectations(t)
+
+	due, err := regStore.DueJobTriggers(time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Zero(t, due[0].Attempts)
+	assert.Equal(t, membership.ID, due[1].MembershipID)
+	assert.True(t, dispatcher.Status().LastTriggerFailureAt.IsZero())
+}
+
+func Test_TriggerDispatcher_SendsToNodesConcurrently(t *testing.T) {
+	db, regStore, cleanup := setupRegistryStore(t)
+	defer cleanup()
+	reg, membership := createRegistry(t, db, newRegistry())
+	node := createNode(t, db, "other")
+	otherMembership := NewMembership(reg, eitest.NewAddress(), models.NewID())
+	otherMembership.NodeID = &node.ID
+	require.NoError(t, db.Create(&otherMembership).Error)
+	clMock := new(mocks.ChainlinkClient)
+	dispatcher := NewTriggerDispatcher(regStore, clMock, time.Hour, BackoffConfig{}, func() int64 { return 0 }).(triggerDispatcher)
+
+	_, err := regStore.CreateJobTrigger(JobTrigger{MembershipID: membership.ID, IdempotencyKey: "a", Payload: []byte(`{}`), Status: TriggerPending, NextAttemptAt: time.Now()})
+	require.NoError(t, err)
+	_, err = regStore.CreateJobTrigger(JobTrigger{MembershipID: otherMembership.ID, IdempotencyKey: "b", Payload: []byte(`{}`), Status: TriggerPending, NextAttemptAt: time.Now()})
+	require.NoError(t, err)
+	// the default node only answers once the other node got its trigger
+	chOtherSent := make(chan struct{})
+	clMock.On("TriggerJob", membership.JobID.String(), mock.Anything, "a", mock.Anything).Return("", errors.New("timeout")).Once().
+		Run(func(mock.Arguments) {
+			select {
+			case <-chOtherSent:
+			case <-time.After(3 * time.Second):
+			}
+		})
+	clMock.On("TriggerJob", otherMembership.JobID.String(), mock.Anything, "b", mock.Anything).Return("", nil).Once().
+		Run(func(mock.Arguments) { close(chOtherSent) })
+
+	start := time.Now()
+	dispatcher.dispatch()
+	assert.Less(t, int64(time.Since(start)), int64(3*time.Second), "the other node waited for the default node")
+	clMock.AssertExpectations(t)
+	eitest.AssertCount(t, db, JobTrigger{}, 2)
+	due, err := regStore.DueJobTriggers(time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "timeout", due[0].LastError)
+}
@@ -0,0 +1,134 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/smartcontractkit/chainlink/core/store/models"
+	"github.com/smartcontractkit/chainlink/core/utils"
+)
+
+// TurnTakingStrategy names the algorithm a registry uses to rotate upkeeps between keepers
+type TurnTakingStrategy string
+
+const (
+	// PositioningConstantStrategy is the turn taking algorithm of the v1 KeeperRegistry
+	PositioningConstantStrategy TurnTakingStrategy = "positioning_constant"
+	// BlockHashStrategy buckets upkeeps into keepers using the hash of the block starting the turn
+	BlockHashStrategy TurnTakingStrategy = "block_hash"
+)
+
+// DefaultTurnTakingStrategy is used for registries that don't specify a strategy
+const DefaultTurnTakingStrategy = PositioningConstantStrategy
+
+// TurnTaker decides whether a membership's keeper is eligible to perform an upkeep at a given head
+type TurnTaker interface {
+	IsEligible(upkeep Registration, membership Membership, head models.Head) (bool, error)
+	// NextEligibleBlock returns the first block at or after blockNumber at which the keeper is
+	// eligible to perform the upkeep, ok is false if it can't be known in advance
+	NextEligibleBlock(upkeep Registration, membership Membership, blockNumber uint64) (next uint64, ok bool)
+}
+
+// NewTurnTaker returns the TurnTaker implementing the given strategy
+func NewTurnTaker(strategy TurnTakingStrategy) (TurnTaker, error) {
+	switch strategy {
+	case PositioningConstantStrategy, "":
+		return positioningConstantTurnTaker{}, nil
+	case BlockHashStrategy:
+		return blockHashTurnTaker{}, nil
+	default:
+		return nil, fmt.Errorf("unknown turn taking strategy %s", strategy)
+	}
+}
+
+// positioningConstantTurnTaker rotates each upkeep through the keeper list, starting at
+// the upkeep's positioning constant and moving one keeper forward every turn
+type positioningConstantTurnTaker struct{}
+
+func (positioningConstantTurnTaker) IsEligible(upkeep Registration, membership Membership, head models.Head) (bool, error) {
+	reg := membership.Registry
+	blockNumber := uint64(head.Number)
+	if !isTurnStart(reg, blockNumber) {
+		return false, nil
+	}
+	turn := blockNumber / uint64(reg.BlockCountPerTurn)
+	keeperIndex := (uint64(upkeep.PositioningConstant) + turn) % uint64(reg.NumKeepers)
+	return keeperIndex == uint64(membership.KeeperIndex), nil
+}
+
+func (positioningConstantTurnTaker) NextEligibleBlock(upkeep Registration, membership Membership, blockNumber uint64) (uint64, bool) {
+	reg := membership.Registry
+	if reg.BlockCountPerTurn == 0 || reg.NumKeepers == 0 || membership.KeeperIndex >= reg.NumKeepers {
+		return 0, false
+	}
+	blockCountPerTurn := uint64(reg.BlockCountPerTurn)
+	numKeepers := uint64(reg.NumKeepers)
+	turn := (blockNumber + blockCountPerTurn - 1) / blockCountPerTurn
+	current := (uint64(upkeep.PositioningConstant) + turn) % numKeepers
+	turnsToWait := (uint64(membership.KeeperIndex) + numKeepers - current) % numKeepers
+	return (turn + turnsToWait) * blockCountPerTurn, true
+}
+
+// blockHashTurnTaker assigns each upkeep to a keeper based on the hash of the block
+// which starts the turn, so the assignment can't be predicted ahead of time
+type blockHashTurnTaker struct{}
+
+func (blockHashTurnTaker) IsEligible(upkeep Registration, membership Membership, head models.Head) (bool, error) {
+	reg := membership.Registry
+	if !isTurnStart(reg, uint64(head.Number)) {
+		return false, nil
+	}
+	upkeepBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(upkeepBytes, upkeep.UpkeepID)
+	hash, err := utils.Keccak256(utils.ConcatBytes(head.Hash.Bytes(), upkeepBytes))
+	if err != nil {
+		return false, err
+	}
+	bucket := big.NewInt(0).Mod(big.NewInt(0).SetBytes(hash), big.NewInt(int64(reg.NumKeepers)))
+	return bucket.Uint64() == uint64(membership.KeeperIndex), nil
+}
+
+// NextEligibleBlock is unknowable for the block hash strategy until the turn's block is mined
+func (blockHashTurnTaker) NextEligibleBlock(Registration, Membership, uint64) (uint64, bool) {
+	return 0, false
+}
+
+// isTurnStart returns true if a new turn begins at blockNumber. Registries that
+// haven't been synced yet have no turns.
+func isTurnStart(reg Registry, blockNumber uint64) bool {
+	if reg.BlockCountPerTurn == 0 || reg.NumKeepers == 0 {
+		return false
+	}
+	return blockNumber%uint64(reg.BlockCountPerTurn) == 0
+}
+
+// turnEnd returns the block at which the next turn begins after blockNumber,
+// or zero for registries that haven't been synced yet.
+func turnEnd(reg Registry, blockNumber int64) int64 {
+	if reg.BlockCountPerTurn == 0 || reg.NumKeepers == 0 || blockNumber < 0 {
+		return 0
+	}
+	blockCountPerTurn := int64(reg.BlockCountPerTurn)
+	return (blockNumber/blockCountPerTurn + 1) * blockCountPerTurn
+}
+
+func CalcPositioningConstant(upkeepID uint64, registryAddress common.Address, numKeepers uint32) (uint32, error) {
+	if numKeepers == 0 {
+		return 0, errors.New("cannot calc positioning constant with 0 keepers")
+	}
+
+	upkeepBytes := make([]byte, binary.MaxVarintLen64)
+	binary.PutUvarint(upkeepBytes, upkeepID)
+	bytesToHash := utils.ConcatBytes(upkeepBytes, registryAddress.Bytes())
+	hash, err := utils.Keccak256(bytesToHash)
+	if err != nil {
+		return 0, err
+	}
+	hashUint := big.NewInt(0).SetBytes(hash)
+	constant := big.NewInt(0).Mod(hashUint, big.NewInt(int64(numKeepers)))
+
+	return uint32(constant.Uint64()), nil
+}
@@ -0,0 +1,130 @@
+package keeper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTurnTaker(t *testing.T) {
+	turnTaker, err := NewTurnTaker(PositioningConstantStrategy)
+	require.NoError(t, err)
+	assert.IsType(t, positioningConstantTurnTaker{}, turnTaker)
+
+	turnTaker, err = NewTurnTaker("")
+	require.NoError(t, err)
+	assert.IsType(t, positioningConstantTurnTaker{}, turnTaker)
+
+	turnTaker, err = NewTurnTaker(BlockHashStrategy)
+	require.NoError(t, err)
+	assert.IsType(t, blockHashTurnTaker{}, turnTaker)
+
+	_, err = NewTurnTaker("round_robin")
+	require.Error(t, err)
+}
+
+func TestPositioningConstantTurnTaker_IsEligible(t *testing.T) {
+	reg := newRegistry()
+	reg.NumKeepers = 5
+	membership := newMembership(reg)
+	membership.KeeperIndex = 2
+	upkeep := Registration{UpkeepID: 0, PositioningConstant: 4}
+
+	for _, test := range []struct {
+		blockNumber int64
+		eligible    bool
+	}{
+		{0, false},
+		{20, false},
+		{59, false},
+		{60, true}, // (4 + 60/20) % 5 = 2
+		{61, false},
+		{80, false},
+		{160, true}, // (4 + 160/20) % 5 = 2
+	} {
+		eligible, err := positioningConstantTurnTaker{}.IsEligible(upkeep, membership, newHead(test.blockNumber))
+		require.NoError(t, err)
+		assert.Equal(t, test.eligible, eligible, "block %d", test.blockNumber)
+	}
+}
+
+func TestPositioningConstantTurnTaker_NextEligibleBlock(t *testing.T) {
+	reg := newRegistry()
+	reg.NumKeepers = 5
+	membership := newMembership(reg)
+	membership.KeeperIndex = 2
+	upkeep := Registration{UpkeepID: 0, PositioningConstant: 4}
+
+	for _, test := range []struct {
+		blockNumber uint64
+		next        uint64
+	}{
+		{0, 60},
+		{59, 60},
+		{60, 60},
+		{61, 160},
+		{160, 160},
+	} {
+		next, ok := positioningConstantTurnTaker{}.NextEligibleBlock(upkeep, membership, test.blockNumber)
+		require.True(t, ok)
+		assert.Equal(t, test.next, next, "block %d", test.blockNumber)
+
+		eligible, err := positioningConstantTurnTaker{}.IsEligible(upkeep, membership, newHead(int64(next)))
+		require.NoError(t, err)
+		assert.True(t, eligible)
+	}
+
+	_, ok := positioningConstantTurnTaker{}.NextEligibleBlock(upkeep, newMembership(NewRegistry(registryAddress)), 0)
+	assert.False(t, ok)
+}
+
+func TestBlockHashTurnTaker_IsEligible(t *testing.T) {
+	reg := newRegistry()
+	reg.NumKeepers = 4
+	membership := newMembership(reg)
+
+	t.Run("assigns each upkeep to exactly one keeper per turn", func(t *testing.T) {
+		head := newHead(40)
+		for upkeepID := uint64(0); upkeepID < 20; upkeepID++ {
+			count := 0
+			for keeperIndex := uint32(0); keeperIndex < reg.NumKeepers; keeperIndex++ {
+				membership.KeeperIndex = keeperIndex
+				eligible, err := blockHashTurnTaker{}.IsEligible(Registration{UpkeepID: upkeepID}, membership, head)
+				require.NoError(t, err)
+				if eligible {
+					count++
+				}
+			}
+			assert.Equal(t, 1, count)
+		}
+	})
+
+	t.Run("is never eligible outside of turn start", func(t *testing.T) {
+		head := newHead(41)
+		for keeperIndex := uint32(0); keeperIndex < reg.NumKeepers; keeperIndex++ {
+			membership.KeeperIndex = keeperIndex
+			eligible, err := blockHashTurnTaker{}.IsEligible(Registration{UpkeepID: 0}, membership, head)
+			require.NoError(t, err)
+			assert.False(t, eligible)
+		}
+	})
+}
+
+func TestTurnTakers_UnsyncedRegistry(t *testing.T) {
+	membership := newMembership(NewRegistry(registryAddress))
+	for _, turnTaker := range []TurnTaker{positioningConstantTurnTaker{}, blockHashTurnTaker{}} {
+		eligible, err := turnTaker.IsEligible(Registration{}, membership, newHead(0))
+		require.NoError(t, err)
+		assert.False(t, eligible)
+	}
+}
+
+func TestTurnEnd(t *testing.T) {
+	reg := newRegistry()
+	reg.NumKeepers = 5
+	for blockNumber, expected := range map[int64]int64{0: 20, 19: 20, 20: 40, 61: 80} {
+		assert.Equal(t, expected, turnEnd(reg, blockNumber), "block %d", blockNumber)
+	}
+	assert.Zero(t, turnEnd(NewRegistry(registryAddress), 20))
+}
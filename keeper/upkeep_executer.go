@@ -2,18 +2,20 @@ package keeper
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/smartcontractkit/chainlink/core/logger"
 	"github.com/smartcontractkit/chainlink/core/services/eth"
 	"github.com/smartcontractkit/chainlink/core/store/models"
 	"github.com/smartcontractkit/chainlink/core/utils"
-	"github.com/smartcontractkit/external-initiator/chainlink"
 	"go.uber.org/atomic"
 )
 
@@ -25,52 +27,159 @@ const (
 	refreshInterval    = 5 * time.Second
 )
 
+const (
+	checkOutcomePerform = "perform"
+	checkOutcomeRevert  = "revert"
+	checkOutcomeError   = "error"
+)
+
 var (
-	performUpkeepHex = utils.AddHexPrefix(common.Bytes2Hex(UpkeepRegistryABI.Methods[performUpkeep].ID))
+	promHeadsReceived = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "keeper_heads_received",
+		Help: "The number of heads received by the upkeep executer",
+	})
+	promEligibleUpkeeps = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "keeper_eligible_upkeeps_per_block",
+		Help:    "The number of upkeeps we are eligible to perform per block",
+		Buckets: []float64{0, 1, 2, 5, 10, 20, 50, 100, 200, 500},
+	})
+	promCheckUpkeepDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "keeper_check_upkeep_duration_seconds",
+		Help: "The latency of checkUpkeep calls, by outcome",
+	}, []string{"outcome"})
+	promExecutionQueueInUse = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "keeper_execution_queue_in_use",
+		Help: "The number of upkeep executions in progress",
+	})
+	promExecutionQueueFull = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "keeper_execution_queue_full",
+		Help: "The number of upkeep executions which had to wait for a free slot in the execution queue",
+	})
 )
 
 type UpkeepExecuter interface {
 	Start() error
 	Stop()
+	// Err receives the error the executer failed with while running, e.g. when the
+	// head subscription is lost, it is then stopped and started again
+	Err() <-chan error
+	Status() ExecuterStatus
+	CheckUpkeep(registration Registration, membership Membership, blockNumber *big.Int) (UpkeepCheck, error)
+	PerformUpkeep(registration Registration, membership Membership) (UpkeepCheck, error)
 }
 
-func NewUpkeepExecuter(keeperStore Store, clNode chainlink.Client, ethClient eth.Client) UpkeepExecuter {
+// UpkeepCheck is the outcome of calling checkUpkeep for an upkeep. A nil
+// BlockNumber means the check ran against the latest block, and a zero
+// GasUsed means the gas wasn't estimated.
+type UpkeepCheck struct {
+	CheckUpkeepResult
+	BlockNumber  *big.Int
+	Performable  bool
+	RevertReason string
+	GasUsed      uint64
+}
+
+// ExecuterStatus reports the progress of the UpkeepExecuter,
+// zero values mean that the event hasn't happened yet
+type ExecuterStatus struct {
+	StartedAt         time.Time
+	LastHeadAt        time.Time
+	LatestBlockNumber int64
+}
+
+// NewUpkeepExecuter returns an UpkeepExecuter which writes the job run triggers
+// to perform upkeeps to the outbox, from which the TriggerDispatcher sends them.
+// Once stopped, the upkeeps being executed are waited for up to the drainTimeout.
+func NewUpkeepExecuter(keeperStore Store, ethClient eth.Client, drainTimeout time.Duration) UpkeepExecuter {
 	return upkeepExecuter{
-		blockHeight:    atomic.NewUint64(0),
-		chainlinkNode:  clNode,
+		latestHead:     &atomic.Value{},
 		ethClient:      ethClient,
 		keeperStore:    keeperStore,
 		isRunning:      atomic.NewBool(false),
+		startedAt:      atomic.NewInt64(0),
+		lastHeadAt:     atomic.NewInt64(0),
+		drainTimeout:   drainTimeout,
+		ctx:            newRenewableContext(),
 		executionQueue: make(chan struct{}, executionQueueSize),
-		chDone:         make(chan struct{}),
+		lifecycle:      newLifecycle(),
 		chSignalRun:    make(chan struct{}, 1),
 	}
 }
 
 type upkeepExecuter struct {
-	blockHeight   *atomic.Uint64
-	chainlinkNode chainlink.Client
-	ethClient     eth.Client
-	keeperStore   Store
-	isRunning     *atomic.Bool
+	latestHead  *atomic.Value
+	ethClient   eth.Client
+	keeperStore Store
+	isRunning   *atomic.Bool
+
+	// unix nano timestamps reported in Status()
+	startedAt  *atomic.Int64
+	lastHeadAt *atomic.Int64
+
+	drainTimeout time.Duration
+	// ctx is canceled once the upkeeps being executed aren't waited for anymore,
+	// and renewed when started again
+	ctx *renewableContext
 
 	executionQueue chan struct{}
-	chDone         chan struct{}
-	chSignalRun    chan struct{}
+	*lifecycle
+	chSignalRun chan struct{}
 }
 
+// Start subscribes to new heads and starts executing upkeeps on each head. Start
+// can be retried if the head subscription can't be created, and called again once
+// stopped.
 func (executer upkeepExecuter) Start() error {
 	if executer.isRunning.Load() {
 		return errors.New("already started")
 	}
+	executer.restart()
+	executer.ctx.renew()
+	headers := make(chan *models.Head)
+	sub, err := executer.ethClient.SubscribeNewHead(context.Background(), headers)
+	if err != nil {
+		return fmt.Errorf("unable to create head subscription: %w", err)
+	}
 	executer.isRunning.Store(true)
-	go executer.setRunsOnHeadSubscription()
-	go executer.run()
+	executer.startedAt.Store(time.Now().UnixNano())
+	executer.spawn(func() { executer.setRunsOnHeadSubscription(sub, headers) })
+	executer.spawn(executer.run)
 	return nil
 }
 
+func (executer upkeepExecuter) Status() ExecuterStatus {
+	head, _ := executer.latestHead.Load().(models.Head)
+	return ExecuterStatus{
+		StartedAt:         unixNanoToTime(executer.startedAt.Load()),
+		LastHeadAt:        unixNanoToTime(executer.lastHeadAt.Load()),
+		LatestBlockNumber: head.Number,
+	}
+}
+
+// Stop unsubscribes from new heads and drains the execution queue, the calls of the
+// upkeeps still being executed after the drain timeout are canceled. Stop can be
+// called more than once.
 func (executer upkeepExecuter) Stop() {
-	close(executer.chDone)
+	executer.stop()
+	defer executer.isRunning.Store(false)
+	defer executer.ctx.cancel()
+
+	deadline := time.NewTimer(executer.drainTimeout)
+	defer deadline.Stop()
+	// every slot of the queue is free once the executions are done
+	for i := 0; i < cap(executer.executionQueue); i++ {
+		select {
+		case executer.executionQueue <- struct{}{}:
+		case <-deadline.C:
+			logger.Warnw("Canceling the upkeeps still being executed", "drainTimeout", executer.drainTimeout)
+			executer.ctx.cancel()
+			executer.executionQueue <- struct{}{}
+		}
+	}
+	// the slots are freed again for the next call to Stop
+	for i := 0; i < cap(executer.executionQueue); i++ {
+		<-executer.executionQueue
+	}
 }
 
 func (executer upkeepExecuter) run() {
@@ -90,121 +199,219 @@ func (executer upkeepExecuter) processActiveRegistrations() {
 	// but will need a cap
 	logger.Debug("received new block, running checkUpkeep for keeper registrations")
 
-	activeRegistrations, err := executer.keeperStore.EligibleUpkeeps(executer.blockHeight.Load())
+	head, ok := executer.latestHead.Load().(models.Head)
+	if !ok {
+		return
+	}
+
+	activeRegistrations, err := executer.keeperStore.EligibleUpkeeps(head)
 	if err != nil {
 		logger.Errorf("unable to load active registrations: %v", err)
 		return
 	}
+	promEligibleUpkeeps.Observe(float64(len(activeRegistrations)))
 
-	for _, reg := range activeRegistrations {
-		executer.concurrentExecute(reg)
+	for _, upkeep := range activeRegistrations {
+		if !executer.concurrentExecute(upkeep, head) {
+			return
+		}
 	}
 }
 
-func (executer upkeepExecuter) concurrentExecute(registration registration) {
-	executer.executionQueue <- struct{}{}
-	go executer.execute(registration)
+// concurrentExecute executes the upkeep once the execution queue has a free slot,
+// it returns false without executing it if the executer is stopped in the meantime
+func (executer upkeepExecuter) concurrentExecute(upkeep EligibleUpkeep, head models.Head) bool {
+	select {
+	case executer.executionQueue <- struct{}{}:
+	default:
+		promExecutionQueueFull.Inc()
+		select {
+		case executer.executionQueue <- struct{}{}:
+		case <-executer.chDone:
+			return false
+		}
+	}
+	promExecutionQueueInUse.Inc()
+	go executer.execute(upkeep.Registration, upkeep.Membership, head)
+	return true
 }
 
 // execute will call checkForUpkeep and, if it succeeds, triger a job on the CL node
-func (executer upkeepExecuter) execute(registration registration) {
+// unless the job's options prevent it
+func (executer upkeepExecuter) execute(registration Registration, membership Membership, head models.Head) {
 	// pop queue when done executing
 	defer func() {
 		<-executer.executionQueue
+		promExecutionQueueInUse.Dec()
 	}()
 
-	checkPayload, err := UpkeepRegistryABI.Pack(
-		checkUpkeep,
-		big.NewInt(int64(registration.UpkeepID)),
-		registration.Registry.From,
-	)
+	check, err := executer.checkUpkeep(registration, membership, membership.CheckBlockNumber(head.Number), false)
 	if err != nil {
 		logger.Error(err)
 		return
 	}
+	if !check.Performable {
+		return
+	}
+	if !membership.AllowsGasPrice(check.GasWei) {
+		logger.Debugw("Skipping upkeep above the job's max gas price", "registry", membership.Registry.Address.Hex(), "upkeepID", registration.UpkeepID, "gasWei", check.GasWei)
+		return
+	}
+	if membership.DryRun {
+		logger.Infow("Dry run, not performing upkeep", "registry", membership.Registry.Address.Hex(), "upkeepID", registration.UpkeepID, "jobID", membership.JobID)
+		return
+	}
 
-	msg := ethereum.CallMsg{
-		From: utils.ZeroAddress,
-		To:   &registration.Registry.Address,
-		Gas:  uint64(registration.Registry.CheckGas),
-		Data: checkPayload,
+	key := idempotencyKey(membership.Registry.Address, registration.UpkeepID, head.Number)
+	if err = executer.triggerPerform(registration, membership, check.PerformData, head.Number, key); err != nil {
+		logger.Errorf("Unable to queue job run trigger: %v", err)
 	}
+}
+
+// CheckUpkeep calls checkUpkeep at the given block, or the latest block if nil,
+// as the membership's keeper, without triggering a job run. The membership must have
+// its Registry loaded.
+func (executer upkeepExecuter) CheckUpkeep(registration Registration, membership Membership, blockNumber *big.Int) (UpkeepCheck, error) {
+	return executer.checkUpkeep(registration, membership, blockNumber, true)
+}
+
+// PerformUpkeep checks the upkeep at the latest block and, if it can be performed,
+// queues the membership's job run trigger regardless of whether it is the keeper's
+// turn. Only the gas buffer of the job's options applies to manual performs, which
+// have an idempotency key of their own.
+func (executer upkeepExecuter) PerformUpkeep(registration Registration, membership Membership) (UpkeepCheck, error) {
+	check, err := executer.checkUpkeep(registration, membership, nil, true)
+	if err != nil || !check.Performable {
+		return check, err
+	}
+	logger.Infow("Manually performing upkeep", "registry", membership.Registry.Address.Hex(), "upkeepID", registration.UpkeepID, "from", membership.From.Hex())
+	head, _ := executer.latestHead.Load().(models.Head)
+	key := manualIdempotencyKey(membership.Registry.Address, registration.UpkeepID, head.Number)
+	return check, executer.triggerPerform(registration, membership, check.PerformData, head.Number, key)
+}
 
-	logger.Debugf("Checking upkeep on registry: %s, upkeepID %d", registration.Registry.Address.Hex(), registration.UpkeepID)
+// checkUpkeep calls checkUpkeep on the registry, a reverted call is reported as
+// not performable rather than as an error. The gas used is only estimated when
+// requested, as it costs an extra RPC call.
+func (executer upkeepExecuter) checkUpkeep(registration Registration, membership Membership, blockNumber *big.Int, estimateGas bool) (UpkeepCheck, error) {
+	check := UpkeepCheck{BlockNumber: blockNumber}
+	registry := membership.Registry
 
-	result, err := executer.ethClient.CallContract(context.Background(), msg, nil)
+	contract, err := NewRegistryContract(registry.Version, registry.Address, executer.ethClient)
 	if err != nil {
-		logger.Debugf("checkUpkeep failed on registry: %s, upkeepID %d", registration.Registry.Address.Hex(), registration.UpkeepID)
-		return
+		return check, err
 	}
 
-	res, err := UpkeepRegistryABI.Unpack(checkUpkeep, result)
+	checkPayload, err := contract.PackCheckUpkeep(registration.UpkeepID, membership.From)
 	if err != nil {
-		logger.Error(err)
-		return
+		return check, err
 	}
 
-	performData, ok := res[0].([]byte)
-	if !ok {
-		logger.Error("checkupkeep payload not as expected")
-		return
+	msg := ethereum.CallMsg{
+		From: utils.ZeroAddress,
+		To:   &registry.Address,
+		Gas:  uint64(registry.CheckGas),
+		Data: checkPayload,
 	}
 
-	performPayload, err := UpkeepRegistryABI.Pack(
-		performUpkeep,
-		big.NewInt(int64(registration.UpkeepID)),
-		performData,
-	)
+	logger.Debugf("Checking upkeep on registry: %s, upkeepID %d", registry.Address.Hex(), registration.UpkeepID)
+
+	checkStart := time.Now()
+	result, err := executer.ethClient.CallContract(executer.ctx.get(), msg, blockNumber)
 	if err != nil {
-		logger.Error(err)
-		return
+		promCheckUpkeepDuration.WithLabelValues(checkOutcomeRevert).Observe(time.Since(checkStart).Seconds())
+		logger.Debugf("checkUpkeep failed on registry: %s, upkeepID %d", registry.Address.Hex(), registration.UpkeepID)
+		check.RevertReason = err.Error()
+		return check, nil
 	}
 
-	performPayloadString := utils.AddHexPrefix(common.Bytes2Hex(performPayload[4:]))
+	checkResult, err := contract.UnpackCheckUpkeep(result)
+	if err != nil {
+		promCheckUpkeepDuration.WithLabelValues(checkOutcomeError).Observe(time.Since(checkStart).Seconds())
+		return check, err
+	}
+	promCheckUpkeepDuration.WithLabelValues(checkOutcomePerform).Observe(time.Since(checkStart).Seconds())
+	check.Performable = true
+	check.CheckUpkeepResult = checkResult
 
-	chainlinkPayloadJSON := map[string]interface{}{
-		"format":           "preformatted",
-		"address":          registration.Registry.Address.Hex(),
-		"functionSelector": performUpkeepHex,
-		"result":           performPayloadString,
-		"fromAddresses":    []string{registration.Registry.From.Hex()},
-		"gasLimit":         registration.ExecuteGas + gasBuffer,
+	if estimateGas {
+		check.GasUsed, err = executer.ethClient.EstimateGas(executer.ctx.get(), msg)
+		if err != nil {
+			logger.Warnf("unable to estimate checkUpkeep gas for upkeepID %d: %v", registration.UpkeepID, err)
+		}
 	}
+	return check, nil
+}
 
-	chainlinkPayload, err := json.Marshal(chainlinkPayloadJSON)
+// triggerPerform writes the job run trigger performing the upkeep with the given performData to
+// the outbox, rendering the run payload with the job's payload template. The block number is the
+// head the upkeep is performed for, or the latest head received for manual performs, and the
+// trigger expires when the turn of that block ends. A trigger already queued with the idempotency
+// key isn't queued again.
+func (executer upkeepExecuter) triggerPerform(registration Registration, membership Membership, performData []byte, blockNumber int64, key string) error {
+	registry := membership.Registry
+	contract, err := NewRegistryContract(registry.Version, registry.Address, executer.ethClient)
 	if err != nil {
-		logger.Error(err)
-		return
+		return err
 	}
 
-	logger.Debugf("Performing upkeep on registry: %s, upkeepID %d", registration.Registry.Address.Hex(), registration.UpkeepID)
-	err = executer.chainlinkNode.TriggerJob(registration.Registry.JobID.String(), chainlinkPayload)
+	performPayload, err := contract.PackPerformUpkeep(registration.UpkeepID, performData)
 	if err != nil {
-		logger.Errorf("Unable to trigger job on chainlink node: %v", err)
+		return err
 	}
-}
 
-func (executer upkeepExecuter) setRunsOnHeadSubscription() {
-	headers := make(chan *models.Head)
-	sub, err := executer.ethClient.SubscribeNewHead(context.Background(), headers)
-	defer func() { sub.Unsubscribe() }()
+	chainlinkPayload, err := membership.PayloadTemplate.Render(PayloadData{
+		UpkeepID:         registration.UpkeepID,
+		PerformData:      utils.AddHexPrefix(common.Bytes2Hex(performData)),
+		Registry:         registry.Address.Hex(),
+		From:             membership.From.Hex(),
+		GasLimit:         membership.PerformGasLimit(registration.ExecuteGas),
+		BlockNumber:      blockNumber,
+		FunctionSelector: utils.AddHexPrefix(common.Bytes2Hex(performPayload[:4])),
+		PerformArgs:      utils.AddHexPrefix(common.Bytes2Hex(performPayload[4:])),
+	})
 	if err != nil {
-		logger.Fatal("unable to create head subscription", "err", err)
+		return err
 	}
 
+	logger.Debugf("Queueing upkeep perform on registry: %s, upkeepID %d", registry.Address.Hex(), registration.UpkeepID)
+	_, err = executer.keeperStore.CreateJobTrigger(JobTrigger{
+		MembershipID:   membership.ID,
+		UpkeepID:       registration.UpkeepID,
+		BlockNumber:    blockNumber,
+		IdempotencyKey: key,
+		Payload:        chainlinkPayload,
+		Status:         TriggerPending,
+		NextAttemptAt:  time.Now(),
+		ExpiresAtBlock: turnEnd(registry, blockNumber),
+	})
+	if errors.Is(err, ErrJobTriggerExists) {
+		logger.Debugf("Upkeep perform already queued on registry: %s, upkeepID %d, block %d", registry.Address.Hex(), registration.UpkeepID, blockNumber)
+		return nil
+	}
+	return err
+}
+
+// setRunsOnHeadSubscription signals a run on every head, until the executer is stopped
+// or the subscription fails, which fails the executer so that it is started again
+func (executer upkeepExecuter) setRunsOnHeadSubscription(sub ethereum.Subscription, headers chan *models.Head) {
+	defer sub.Unsubscribe()
+
 	for {
 		select {
 		case <-executer.chDone:
 			return
 		case err := <-sub.Err():
-			logger.Warnf("error in keeper head subscription, attempting to restart: %v", err)
-			time.Sleep(3 * time.Second)
-			sub, err = executer.ethClient.SubscribeNewHead(context.Background(), headers)
-			if err != nil {
-				logger.Errorf("unable to renew head subscription", "err", err)
+			if err == nil {
+				err = errors.New("subscription closed")
 			}
+			logger.Errorf("error in keeper head subscription: %v", err)
+			executer.fail(fmt.Errorf("head subscription failed: %w", err))
+			return
 		case head := <-headers:
-			executer.blockHeight.Store(uint64(head.Number))
+			promHeadsReceived.Inc()
+			executer.latestHead.Store(*head)
+			executer.lastHeadAt.Store(time.Now().UnixNano())
 			executer.signalRun()
 		}
 	}
@@ -217,3 +424,36 @@ func (executer upkeepExecuter) signalRun() {
 	default:
 	}
 }
+
+// renewableContext is a context which can be renewed once canceled
+type renewableContext struct {
+	mu         sync.RWMutex
+	ctx        context.Context
+	cancelFunc context.CancelFunc
+}
+
+func newRenewableContext() *renewableContext {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &renewableContext{ctx: ctx, cancelFunc: cancel}
+}
+
+func (rc *renewableContext) get() context.Context {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.ctx
+}
+
+func (rc *renewableContext) cancel() {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	rc.cancelFunc()
+}
+
+// renew replaces the context if it was canceled
+func (rc *renewableContext) renew() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.ctx.Err() != nil {
+		rc.ctx, rc.cancelFunc = context.WithCancel(context.Background())
+	}
+}
@@ -1,17 +1,22 @@
 package keeper
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/jinzhu/gorm"
 	"github.com/smartcontractkit/chainlink/core/store/models"
+	"github.com/smartcontractkit/chainlink/core/utils"
 	"github.com/smartcontractkit/external-initiator/eitest"
 	"github.com/smartcontractkit/external-initiator/internal/mocks"
 	"github.com/smartcontractkit/external-initiator/store"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -19,16 +24,21 @@ import (
 func setupExecuter(t *testing.T) (
 	*gorm.DB,
 	UpkeepExecuter,
-	*mocks.ChainlinkClient,
 	*mocks.EthClient,
 	func(),
 ) {
 	db, cleanup := store.SetupTestDB(t)
-	clMock := new(mocks.ChainlinkClient)
 	ethMock := new(mocks.EthClient)
 	regStore := NewStore(db.DB())
-	executer := NewUpkeepExecuter(regStore, clMock, ethMock)
-	return db.DB(), executer, clMock, ethMock, cleanup
+	executer := NewUpkeepExecuter(regStore, ethMock, time.Second)
+	return db.DB(), executer, ethMock, cleanup
+}
+
+// lastJobTrigger returns the most recently queued job trigger
+func lastJobTrigger(t *testing.T, db *gorm.DB) JobTrigger {
+	var trigger JobTrigger
+	require.NoError(t, db.Order("id DESC").First(&trigger).Error)
+	return trigger
 }
 
 // setupHeadsSubscription sets the mock calls for the head tracker and returns a blocking
@@ -37,7 +47,7 @@ func setupHeadsSubscription(ethMock *mocks.EthClient) (func() chan<- *models.Hea
 	sub := new(mocks.EthSubscription)
 	sub.On("Err").Return(nil).Once()
 	sub.On("Unsubscribe").Return(nil).Once()
-	chchHeaders := make(chan chan<- *models.Head)
+	chchHeaders := make(chan chan<- *models.Head, 1)
 	ethMock.
 		On("SubscribeNewHead", mock.Anything, mock.Anything).
 		Return(sub, nil).
@@ -66,7 +76,7 @@ var checkUpkeepResponse = struct {
 }
 
 func Test_UpkeepExecuter_ErrorsIfStartedTwice(t *testing.T) {
-	_, executer, _, ethMock, cleanup := setupExecuter(t)
+	_, executer, ethMock, cleanup := setupExecuter(t)
 	defer cleanup()
 	setupHeadsSubscription(ethMock)
 
@@ -78,8 +88,29 @@ func Test_UpkeepExecuter_ErrorsIfStartedTwice(t *testing.T) {
 	require.Error(t, err)
 
 }
+
+func Test_UpkeepExecuter_Start_RetriesFailedSubscription(t *testing.T) {
+	_, executer, ethMock, cleanup := setupExecuter(t)
+	defer cleanup()
+
+	ethMock.
+		On("SubscribeNewHead", mock.Anything, mock.Anything).
+		Return(nil, errors.New("connection refused")).
+		Once()
+
+	err := executer.Start()
+	require.Error(t, err)
+	assert.True(t, executer.Status().StartedAt.IsZero())
+
+	setupHeadsSubscription(ethMock)
+	err = executer.Start()
+	require.NoError(t, err)
+	defer executer.Stop()
+
+	ethMock.AssertExpectations(t)
+}
 func Test_UpkeepExecuter_PerformsUpkeep_Happy(t *testing.T) {
-	db, executer, clMock, ethMock, cleanup := setupExecuter(t)
+	db, executer, ethMock, cleanup := setupExecuter(t)
 	defer cleanup()
 	getHeadsChannel, _ := setupHeadsSubscription(ethMock)
 
@@ -87,11 +118,8 @@ func Test_UpkeepExecuter_PerformsUpkeep_Happy(t *testing.T) {
 	require.NoError(t, err)
 	defer executer.Stop()
 	chHeads := getHeadsChannel()
-	chJobWasRun := make(chan struct{})
 
-	reg := newRegistry()
-	err = db.Create(&reg).Error
-	require.NoError(t, err)
+	reg, membership := createRegistry(t, db, newRegistry())
 
 	upkeep := newRegistration(reg, 0)
 	err = db.Create(&upkeep).Error
@@ -100,41 +128,31 @@ func Test_UpkeepExecuter_PerformsUpkeep_Happy(t *testing.T) {
 	registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistryABI, reg.Address)
 	registryMock.MockResponse("checkUpkeep", checkUpkeepResponse)
 
-	clMock.
-		On("TriggerJob", reg.JobID.String(), mock.Anything).
-		Return(nil).
-		Run(func(args mock.Arguments) {
-			chJobWasRun <- struct{}{}
-		})
-
 	t.Run("runs upkeep on triggering block number", func(t *testing.T) {
 		head := models.NewHead(big.NewInt(20), eitest.NewHash(), eitest.NewHash(), 1000)
 		chHeads <- &head
 
-		select {
-		case <-time.NewTimer(2 * time.Second).C:
-			t.Fatal("new job run never triggered")
-		case <-chJobWasRun:
-		}
+		eitest.WaitForCount(t, db, JobTrigger{}, 1)
+		trigger := lastJobTrigger(t, db)
+		require.Equal(t, membership.ID, trigger.MembershipID)
+		require.Equal(t, TriggerPending, trigger.Status)
+		require.Equal(t, int64(20), trigger.BlockNumber)
+		require.Equal(t, int64(40), trigger.ExpiresAtBlock)
 	})
 
 	t.Run("skips upkeep on non-triggering block number", func(t *testing.T) {
 		head := models.NewHead(big.NewInt(21), eitest.NewHash(), eitest.NewHash(), 1000)
 		chHeads <- &head
 
-		select {
-		case <-time.NewTimer(2 * time.Second).C:
-		case <-chJobWasRun:
-			t.Fatal("new job not supposed to run")
-		}
+		time.Sleep(2 * time.Second)
+		eitest.AssertCount(t, db, JobTrigger{}, 1)
 	})
 
-	clMock.AssertExpectations(t)
 	ethMock.AssertExpectations(t)
 }
 
 func Test_UpkeepExecuter_PerformsUpkeep_Error(t *testing.T) {
-	db, executer, clMock, ethMock, cleanup := setupExecuter(t)
+	db, executer, ethMock, cleanup := setupExecuter(t)
 	defer cleanup()
 	getHeadsChannel, _ := setupHeadsSubscription(ethMock)
 
@@ -143,9 +161,7 @@ func Test_UpkeepExecuter_PerformsUpkeep_Error(t *testing.T) {
 	defer executer.Stop()
 	chHeads := getHeadsChannel()
 
-	reg := newRegistry()
-	err = db.Create(&reg).Error
-	require.NoError(t, err)
+	reg, _ := createRegistry(t, db, newRegistry())
 
 	upkeep := newRegistration(reg, 0)
 	err = db.Create(&upkeep).Error
@@ -168,47 +184,242 @@ func Test_UpkeepExecuter_PerformsUpkeep_Error(t *testing.T) {
 	case <-chUpkeepCalled:
 	}
 
-	clMock.AssertExpectations(t)
+	eitest.AssertCount(t, db, JobTrigger{}, 0)
 	ethMock.AssertExpectations(t)
 }
 
-func Test_UpkeepExecuter_PerformsUpkeep_ResubscribesToNewHeads(t *testing.T) {
-	_, executer, _, ethMock, cleanup := setupExecuter(t)
+func Test_UpkeepExecuter_PerformsUpkeep_JobOptions(t *testing.T) {
+	db, executer, ethMock, cleanup := setupExecuter(t)
 	defer cleanup()
 
-	sub := new(mocks.EthSubscription)
-	chErr := make(chan error)
+	reg, membership := createRegistry(t, db, newRegistry())
+	upkeep := newRegistration(reg, 0)
+	head := newHead(20)
+	execute := func(membership Membership) {
+		// execute frees a slot in the queue when done
+		executer.(upkeepExecuter).executionQueue <- struct{}{}
+		executer.(upkeepExecuter).execute(upkeep, membership, head)
+	}
 
-	sub.On("Err").Return((<-chan error)(chErr)).Twice()
-	sub.On("Unsubscribe").Return(nil).Once()
+	t.Run("doesn't trigger the job in dry run", func(t *testing.T) {
+		registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistryABI, reg.Address)
+		registryMock.MockResponse("checkUpkeep", checkUpkeepResponse).Once()
 
-	chSubscriptionMade := make(chan struct{})
+		dryRun := membership
+		dryRun.DryRun = true
+		execute(dryRun)
+	})
 
-	ethMock.
-		On("SubscribeNewHead", mock.Anything, mock.Anything).
-		Return(sub, nil).
-		Run(func(args mock.Arguments) {
-			chSubscriptionMade <- struct{}{}
-		}).
-		Twice()
+	t.Run("doesn't trigger the job above the max gas price", func(t *testing.T) {
+		response := checkUpkeepResponse
+		response.GasWei = big.NewInt(101)
+		registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistryABI, reg.Address)
+		registryMock.MockResponse("checkUpkeep", response).Once()
 
-	err := executer.Start()
-	require.NoError(t, err)
-	defer executer.Stop()
+		capped := membership
+		capped.MaxGasPrice = utils.NewBigI(100)
+		execute(capped)
+	})
 
-	select {
-	case <-time.NewTimer(3 * time.Second).C:
-		t.Fatal("first subscription never made")
-	case <-chSubscriptionMade:
-	}
+	t.Run("checks at the confirmed block with the job's gas buffer", func(t *testing.T) {
+		ethMock.
+			On("CallContract", mock.Anything, mock.Anything, big.NewInt(17)).
+			Return(nil, errors.New("upkeep not needed")).
+			Once()
+
+		confirmed := membership
+		confirmed.CheckConfirmations = 3
+		execute(confirmed)
+	})
+
+	eitest.AssertCount(t, db, JobTrigger{}, 0)
 
+	t.Run("queues the trigger with the job's gas buffer", func(t *testing.T) {
+		registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistryABI, reg.Address)
+		registryMock.MockResponse("checkUpkeep", checkUpkeepResponse).Once()
+
+		buffered := membership
+		buffer := uint32(50_000)
+		buffered.GasBuffer = &buffer
+		execute(buffered)
+
+		var decoded struct{ GasLimit uint32 }
+		require.NoError(t, json.Unmarshal(lastJobTrigger(t, db).Payload, &decoded))
+		require.Equal(t, upkeep.ExecuteGas+50_000, decoded.GasLimit)
+	})
+
+	t.Run("doesn't queue the upkeep twice for the same block", func(t *testing.T) {
+		registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistryABI, reg.Address)
+		registryMock.MockResponse("checkUpkeep", checkUpkeepResponse).Once()
+
+		execute(membership)
+		eitest.AssertCount(t, db, JobTrigger{}, 1)
+		require.Equal(t, idempotencyKey(reg.Address, upkeep.UpkeepID, 20), lastJobTrigger(t, db).IdempotencyKey)
+	})
+
+	t.Run("queues the trigger with the job's payload template", func(t *testing.T) {
+		registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistryABI, reg.Address)
+		registryMock.MockResponse("checkUpkeep", checkUpkeepResponse).Once()
+		head = newHead(21)
+		expected := fmt.Sprintf(`{"block":21,"keeper":"%s","upkeep":"0"}`, membership.From.Hex())
+
+		templated := membership
+		templated.PayloadTemplate = PayloadTemplate(`"{\"block\":{{.BlockNumber}},\"keeper\":\"{{.From}}\",\"upkeep\":\"{{.UpkeepID}}\"}"`)
+		execute(templated)
+
+		require.Equal(t, expected, string(lastJobTrigger(t, db).Payload))
+	})
+
+	eitest.AssertCount(t, db, JobTrigger{}, 2)
+	ethMock.AssertExpectations(t)
+}
+
+func Test_UpkeepExecuter_FailsOnLostHeadSubscription(t *testing.T) {
+	ethMock := new(mocks.EthClient)
+	executer := NewUpkeepExecuter(nil, ethMock, time.Second).(upkeepExecuter)
+
+	sub := new(mocks.EthSubscription)
+	chErr := make(chan error)
+	sub.On("Err").Return((<-chan error)(chErr)).Twice()
+	sub.On("Unsubscribe").Return(nil).Twice()
+	ethMock.On("SubscribeNewHead", mock.Anything, mock.Anything).Return(sub, nil).Twice()
+
+	require.NoError(t, executer.Start())
 	chErr <- errors.New("subscription closed")
 
 	select {
-	case <-time.NewTimer(5 * time.Second).C:
-		t.Fatal("second subscription never made")
-	case <-chSubscriptionMade:
+	case <-time.After(3 * time.Second):
+		t.Fatal("the lost subscription was never reported")
+	case err := <-executer.Err():
+		assert.EqualError(t, err, "head subscription failed: subscription closed")
 	}
 
+	executer.Stop()
+	require.Error(t, executer.ctx.get().Err())
+
+	// started again with a new subscription
+	require.NoError(t, executer.Start())
+	assert.NoError(t, executer.ctx.get().Err())
+	assert.Len(t, executer.Err(), 0)
+	executer.Stop()
+
+	ethMock.AssertExpectations(t)
+	sub.AssertExpectations(t)
+}
+
+func Test_UpkeepExecuter_CheckUpkeep(t *testing.T) {
+	db, executer, ethMock, cleanup := setupExecuter(t)
+	defer cleanup()
+
+	reg, membership := createRegistry(t, db, newRegistry())
+	upkeep := newRegistration(reg, 0)
+
+	registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistryABI, reg.Address)
+	registryMock.MockResponse("checkUpkeep", checkUpkeepResponse)
+	ethMock.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(54_321), nil)
+
+	check, err := executer.CheckUpkeep(upkeep, membership, big.NewInt(20))
+	require.NoError(t, err)
+	require.True(t, check.Performable)
+	require.Equal(t, big.NewInt(20), check.BlockNumber)
+	require.Equal(t, checkUpkeepResponse.GasLimit, check.GasLimit)
+	require.Equal(t, uint64(54_321), check.GasUsed)
+
+	eitest.AssertCount(t, db, JobTrigger{}, 0)
 	ethMock.AssertExpectations(t)
 }
+
+func Test_UpkeepExecuter_PerformUpkeep(t *testing.T) {
+	db, executer, ethMock, cleanup := setupExecuter(t)
+	defer cleanup()
+
+	reg, membership := createRegistry(t, db, newRegistry())
+	upkeep := newRegistration(reg, 0)
+
+	t.Run("queues the trigger outside of the turn", func(t *testing.T) {
+		registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistryABI, reg.Address)
+		registryMock.MockResponse("checkUpkeep", checkUpkeepResponse).Once()
+		ethMock.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(54_321), nil).Once()
+
+		check, err := executer.PerformUpkeep(upkeep, membership)
+		require.NoError(t, err)
+		require.True(t, check.Performable)
+		eitest.AssertCount(t, db, JobTrigger{}, 1)
+	})
+
+	t.Run("queues the trigger once performed at the same block", func(t *testing.T) {
+		// no head was received, manual performs are for block 0
+		_, err := NewStore(db).CreateJobTrigger(JobTrigger{
+			MembershipID:   membership.ID,
+			UpkeepID:       upkeep.UpkeepID,
+			IdempotencyKey: idempotencyKey(reg.Address, upkeep.UpkeepID, 0),
+			Payload:        []byte(`{}`),
+			Status:         TriggerSent,
+			NextAttemptAt:  time.Now(),
+		})
+		require.NoError(t, err)
+		registryMock := eitest.NewContractMockReceiver(t, ethMock, UpkeepRegistryABI, reg.Address)
+		registryMock.MockResponse("checkUpkeep", checkUpkeepResponse).Once()
+		ethMock.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(54_321), nil).Once()
+
+		check, err := executer.PerformUpkeep(upkeep, membership)
+		require.NoError(t, err)
+		require.True(t, check.Performable)
+		eitest.AssertCount(t, db, JobTrigger{}, 3)
+
+		var triggers []JobTrigger
+		require.NoError(t, db.Where("status = ?", TriggerPending).Order("id").Find(&triggers).Error)
+		require.Len(t, triggers, 2)
+		assert.NotEqual(t, triggers[0].IdempotencyKey, triggers[1].IdempotencyKey)
+		assert.True(t, strings.HasPrefix(triggers[1].IdempotencyKey, idempotencyKey(reg.Address, upkeep.UpkeepID, 0)+"-manual-"))
+	})
+
+	t.Run("doesn't trigger the job if checkUpkeep reverts", func(t *testing.T) {
+		ethMock.
+			On("CallContract", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, errors.New("upkeep not needed")).
+			Once()
+
+		check, err := executer.PerformUpkeep(upkeep, membership)
+		require.NoError(t, err)
+		require.False(t, check.Performable)
+		require.Equal(t, "upkeep not needed", check.RevertReason)
+	})
+
+	eitest.AssertCount(t, db, JobTrigger{}, 3)
+	ethMock.AssertExpectations(t)
+}
+
+func Test_UpkeepExecuter_Stop_DrainsExecutions(t *testing.T) {
+	executer := NewUpkeepExecuter(nil, new(mocks.EthClient), time.Second).(upkeepExecuter)
+
+	// an execution in progress, done shortly after the stop
+	executer.executionQueue <- struct{}{}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		<-executer.executionQueue
+	}()
+
+	start := time.Now()
+	executer.Stop()
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, int64(elapsed), int64(20*time.Millisecond), "the execution is waited for")
+	assert.Less(t, int64(elapsed), int64(time.Second), "the execution finished before the drain timeout")
+	assert.NotPanics(t, executer.Stop, "stopping twice")
+}
+
+func Test_UpkeepExecuter_Stop_CancelsExecutionsAfterTimeout(t *testing.T) {
+	executer := NewUpkeepExecuter(nil, new(mocks.EthClient), 20*time.Millisecond).(upkeepExecuter)
+
+	// an execution in progress, only done once its calls are canceled
+	executer.executionQueue <- struct{}{}
+	go func() {
+		<-executer.ctx.get().Done()
+		<-executer.executionQueue
+	}()
+
+	start := time.Now()
+	executer.Stop()
+	require.Error(t, executer.ctx.get().Err())
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
@@ -0,0 +1,120 @@
+package keeper
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var promUpkeepsSkipped = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "keeper_upkeeps_skipped",
+	Help: "The number of times an upkeep was skipped by a filter in the turn it was eligible to be performed in, by reason",
+}, []string{"reason"})
+
+const (
+	skipReasonRegistryAllowlist = "registry_allowlist"
+	skipReasonRegistryDenylist  = "registry_denylist"
+	skipReasonJobFilter         = "job_filter"
+)
+
+type FilterAction string
+
+const (
+	// AllowFilter restricts the upkeeps of a registry to the ones matching any allow filter
+	AllowFilter FilterAction = "allow"
+	// DenyFilter skips the upkeeps matching it
+	DenyFilter FilterAction = "deny"
+)
+
+type FilterField string
+
+const (
+	UpkeepIDField FilterField = "upkeep_id"
+	TargetField   FilterField = "target"
+	AdminField    FilterField = "admin"
+)
+
+// UpkeepFilter allows or denies the upkeeps of a Registry with the given
+// upkeep ID, target address or admin address
+type UpkeepFilter struct {
+	ID         uint32 `gorm:"primary_key"`
+	RegistryID uint32
+	Action     FilterAction
+	Field      FilterField
+	Value      string
+	CreatedAt  time.Time
+}
+
+func (UpkeepFilter) TableName() string {
+	return "keeper_upkeep_filters"
+}
+
+// NewUpkeepFilter validates the filter, normalizing its value so that equal
+// filters are stored the same way
+func NewUpkeepFilter(registryID uint32, action FilterAction, field FilterField, value string) (UpkeepFilter, error) {
+	filter := UpkeepFilter{
+		RegistryID: registryID,
+		Action:     action,
+		Field:      field,
+	}
+	if action != AllowFilter && action != DenyFilter {
+		return filter, fmt.Errorf("unknown filter action %q", action)
+	}
+	switch field {
+	case UpkeepIDField:
+		upkeepID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return filter, errors.New("upkeep_id filters must have a decimal upkeep ID value")
+		}
+		filter.Value = strconv.FormatUint(upkeepID, 10)
+	case TargetField, AdminField:
+		if !common.IsHexAddress(value) {
+			return filter, fmt.Errorf("%s filters must have an address value", field)
+		}
+		filter.Value = common.HexToAddress(value).Hex()
+	default:
+		return filter, fmt.Errorf("unknown filter field %q", field)
+	}
+	return filter, nil
+}
+
+// Matches returns whether the upkeep has the filtered value
+func (f UpkeepFilter) Matches(upkeep Registration) bool {
+	switch f.Field {
+	case UpkeepIDField:
+		return f.Value == strconv.FormatUint(upkeep.UpkeepID, 10)
+	case TargetField:
+		return f.Value == upkeep.Target.Hex()
+	case AdminField:
+		return f.Value == upkeep.Admin.Hex()
+	default:
+		return false
+	}
+}
+
+// upkeepFilters are the filters of a single registry
+type upkeepFilters []UpkeepFilter
+
+// skipReason returns why the upkeep is filtered out, or an empty string if it isn't
+func (filters upkeepFilters) skipReason(upkeep Registration) string {
+	hasAllowFilters, allowed := false, false
+	for _, filter := range filters {
+		matches := filter.Matches(upkeep)
+		if filter.Action == DenyFilter && matches {
+			return skipReasonRegistryDenylist
+		}
+		if filter.Action == AllowFilter {
+			hasAllowFilters = true
+			allowed = allowed || matches
+		}
+	}
+	if hasAllowFilters && !allowed {
+		return skipReasonRegistryAllowlist
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package keeper
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUpkeepFilter(t *testing.T) {
+	filter, err := NewUpkeepFilter(1, DenyFilter, TargetField, "00000000000000000000000000000000000000aa")
+	require.NoError(t, err)
+	assert.Equal(t, "0x00000000000000000000000000000000000000AA", filter.Value)
+
+	filter, err = NewUpkeepFilter(1, AllowFilter, UpkeepIDField, "007")
+	require.NoError(t, err)
+	assert.Equal(t, "7", filter.Value)
+
+	for _, test := range []struct {
+		action FilterAction
+		field  FilterField
+		value  string
+	}{
+		{"skip", UpkeepIDField, "1"},
+		{AllowFilter, "executeGas", "1"},
+		{AllowFilter, UpkeepIDField, "0x1"},
+		{DenyFilter, AdminField, "0x1234"},
+	} {
+		_, err := NewUpkeepFilter(1, test.action, test.field, test.value)
+		assert.Error(t, err, "%+v", test)
+	}
+}
+
+func TestUpkeepFilters_SkipReason(t *testing.T) {
+	target := common.HexToAddress("0xaa")
+	admin := common.HexToAddress("0xbb")
+	upkeep := Registration{UpkeepID: 3, Target: target, Admin: admin}
+	newFilter := func(action FilterAction, field FilterField, value string) UpkeepFilter {
+		filter, err := NewUpkeepFilter(1, action, field, value)
+		require.NoError(t, err)
+		return filter
+	}
+
+	assert.Equal(t, "", upkeepFilters{}.skipReason(upkeep))
+	assert.Equal(t, "", upkeepFilters{newFilter(DenyFilter, UpkeepIDField, "4")}.skipReason(upkeep))
+	assert.Equal(t, skipReasonRegistryDenylist, upkeepFilters{newFilter(DenyFilter, AdminField, admin.Hex())}.skipReason(upkeep))
+	assert.Equal(t, "", upkeepFilters{
+		newFilter(AllowFilter, UpkeepIDField, "4"),
+		newFilter(AllowFilter, TargetField, target.Hex()),
+	}.skipReason(upkeep))
+	assert.Equal(t, skipReasonRegistryAllowlist, upkeepFilters{newFilter(AllowFilter, UpkeepIDField, "4")}.skipReason(upkeep))
+	// deny filters take precedence over allow filters
+	assert.Equal(t, skipReasonRegistryDenylist, upkeepFilters{
+		newFilter(AllowFilter, TargetField, target.Hex()),
+		newFilter(DenyFilter, UpkeepIDField, "3"),
+	}.skipReason(upkeep))
+}
@@ -4,6 +4,24 @@ import (
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 	"github.com/smartcontractkit/external-initiator/store/migrations/migration1611603404"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1612280000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1612370000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1612450000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1612530000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1612620000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1612700000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1612780000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1612860000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1612940000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1613030000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1613120000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1613210000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1613300000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1613400000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1613500000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1613600000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1613700000"
+	"github.com/smartcontractkit/external-initiator/store/migrations/migration1613800000"
 	"gopkg.in/gormigrate.v1"
 )
 
@@ -19,6 +37,96 @@ func Migrate(db *gorm.DB) error {
 			Migrate:  migration1611603404.Migrate,
 			Rollback: migration1611603404.Rollback,
 		},
+		{
+			ID:       "1612280000",
+			Migrate:  migration1612280000.Migrate,
+			Rollback: migration1612280000.Rollback,
+		},
+		{
+			ID:       "1612370000",
+			Migrate:  migration1612370000.Migrate,
+			Rollback: migration1612370000.Rollback,
+		},
+		{
+			ID:       "1612450000",
+			Migrate:  migration1612450000.Migrate,
+			Rollback: migration1612450000.Rollback,
+		},
+		{
+			ID:       "1612530000",
+			Migrate:  migration1612530000.Migrate,
+			Rollback: migration1612530000.Rollback,
+		},
+		{
+			ID:       "1612620000",
+			Migrate:  migration1612620000.Migrate,
+			Rollback: migration1612620000.Rollback,
+		},
+		{
+			ID:       "1612700000",
+			Migrate:  migration1612700000.Migrate,
+			Rollback: migration1612700000.Rollback,
+		},
+		{
+			ID:       "1612780000",
+			Migrate:  migration1612780000.Migrate,
+			Rollback: migration1612780000.Rollback,
+		},
+		{
+			ID:       "1612860000",
+			Migrate:  migration1612860000.Migrate,
+			Rollback: migration1612860000.Rollback,
+		},
+		{
+			ID:       "1612940000",
+			Migrate:  migration1612940000.Migrate,
+			Rollback: migration1612940000.Rollback,
+		},
+		{
+			ID:       "1613030000",
+			Migrate:  migration1613030000.Migrate,
+			Rollback: migration1613030000.Rollback,
+		},
+		{
+			ID:       "1613120000",
+			Migrate:  migration1613120000.Migrate,
+			Rollback: migration1613120000.Rollback,
+		},
+		{
+			ID:       "1613210000",
+			Migrate:  migration1613210000.Migrate,
+			Rollback: migration1613210000.Rollback,
+		},
+		{
+			ID:       "1613300000",
+			Migrate:  migration1613300000.Migrate,
+			Rollback: migration1613300000.Rollback,
+		},
+		{
+			ID:       "1613400000",
+			Migrate:  migration1613400000.Migrate,
+			Rollback: migration1613400000.Rollback,
+		},
+		{
+			ID:       "1613500000",
+			Migrate:  migration1613500000.Migrate,
+			Rollback: migration1613500000.Rollback,
+		},
+		{
+			ID:       "1613600000",
+			Migrate:  migration1613600000.Migrate,
+			Rollback: migration1613600000.Rollback,
+		},
+		{
+			ID:       "1613700000",
+			Migrate:  migration1613700000.Migrate,
+			Rollback: migration1613700000.Rollback,
+		},
+		{
+			ID:       "1613800000",
+			Migrate:  migration1613800000.Migrate,
+			Rollback: migration1613800000.Rollback,
+		},
 	}
 
 	m := gormigrate.New(db, &options, migrations)
@@ -0,0 +1,17 @@
+package migration1612280000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_registries ADD COLUMN turn_taking text NOT NULL DEFAULT 'positioning_constant';
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_registries DROP COLUMN IF EXISTS turn_taking;
+	`).Error
+}
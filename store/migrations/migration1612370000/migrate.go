@@ -0,0 +1,17 @@
+package migration1612370000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_registries ADD COLUMN version text NOT NULL DEFAULT '1.0';
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_registries DROP COLUMN IF EXISTS version;
+	`).Error
+}
@@ -0,0 +1,19 @@
+package migration1612450000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_registries ADD COLUMN paused boolean NOT NULL DEFAULT false;
+		ALTER TABLE keeper_registrations ADD COLUMN paused boolean NOT NULL DEFAULT false;
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_registries DROP COLUMN IF EXISTS paused;
+		ALTER TABLE keeper_registrations DROP COLUMN IF EXISTS paused;
+	`).Error
+}
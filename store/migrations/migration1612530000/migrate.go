@@ -0,0 +1,66 @@
+package migration1612530000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+// Migrate moves the keeper address, job and keeper index of each registry into
+// keeper_memberships, so that several keepers can share a registry's state
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		CREATE TABLE keeper_memberships (
+			id SERIAL PRIMARY KEY,
+			registry_id INT NOT NULL REFERENCES keeper_registries (id) ON DELETE CASCADE,
+			reference_id uuid UNIQUE NOT NULL,
+			job_id uuid UNIQUE NOT NULL,
+			"from" bytea NOT NULL,
+			keeper_index int NOT NULL
+		);
+
+		CREATE UNIQUE INDEX idx_keeper_memberships_unique_from_per_registry ON keeper_memberships(registry_id, "from");
+
+		INSERT INTO keeper_memberships (registry_id, reference_id, job_id, "from", keeper_index)
+		SELECT id, reference_id, job_id, "from", keeper_index FROM keeper_registries;
+
+		ALTER TABLE keeper_registries
+			DROP COLUMN reference_id,
+			DROP COLUMN job_id,
+			DROP COLUMN "from",
+			DROP COLUMN keeper_index;
+	`).Error
+}
+
+// Rollback keeps the oldest membership of each registry, registries
+// without memberships are deleted
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_registries
+			ADD COLUMN reference_id uuid UNIQUE,
+			ADD COLUMN job_id uuid UNIQUE,
+			ADD COLUMN "from" bytea,
+			ADD COLUMN keeper_index int;
+
+		UPDATE keeper_registries
+		SET
+			reference_id = m.reference_id,
+			job_id = m.job_id,
+			"from" = m."from",
+			keeper_index = m.keeper_index
+		FROM (
+			SELECT DISTINCT ON (registry_id) * FROM keeper_memberships ORDER BY registry_id, id
+		) m
+		WHERE m.registry_id = keeper_registries.id;
+
+		DELETE FROM keeper_registries WHERE job_id IS NULL;
+
+		ALTER TABLE keeper_registries
+			ALTER COLUMN reference_id SET NOT NULL,
+			ALTER COLUMN job_id SET NOT NULL,
+			ALTER COLUMN "from" SET NOT NULL,
+			ALTER COLUMN keeper_index SET NOT NULL;
+
+		CREATE UNIQUE INDEX idx_keeper_registries_unique_jobs_per_registry ON keeper_registries(address, job_id);
+
+		DROP TABLE IF EXISTS keeper_memberships;
+	`).Error
+}
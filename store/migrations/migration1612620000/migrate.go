@@ -0,0 +1,17 @@
+package migration1612620000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_memberships ADD COLUMN orphaned boolean NOT NULL DEFAULT false;
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_memberships DROP COLUMN IF EXISTS orphaned;
+	`).Error
+}
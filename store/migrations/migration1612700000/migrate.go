@@ -0,0 +1,29 @@
+package migration1612700000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_memberships
+			ADD COLUMN gas_buffer integer,
+			ADD COLUMN max_gas_price numeric(78,0),
+			ADD COLUMN allowed_upkeeps jsonb,
+			ADD COLUMN denied_upkeeps jsonb,
+			ADD COLUMN dry_run boolean NOT NULL DEFAULT false,
+			ADD COLUMN check_confirmations integer NOT NULL DEFAULT 0;
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_memberships
+			DROP COLUMN IF EXISTS gas_buffer,
+			DROP COLUMN IF EXISTS max_gas_price,
+			DROP COLUMN IF EXISTS allowed_upkeeps,
+			DROP COLUMN IF EXISTS denied_upkeeps,
+			DROP COLUMN IF EXISTS dry_run,
+			DROP COLUMN IF EXISTS check_confirmations;
+	`).Error
+}
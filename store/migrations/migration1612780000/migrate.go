@@ -0,0 +1,34 @@
+package migration1612780000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_registrations
+			ADD COLUMN target bytea NOT NULL DEFAULT decode(repeat('00', 20), 'hex'),
+			ADD COLUMN admin bytea NOT NULL DEFAULT decode(repeat('00', 20), 'hex');
+
+		CREATE TABLE keeper_upkeep_filters (
+			id SERIAL PRIMARY KEY,
+			registry_id int NOT NULL REFERENCES keeper_registries (id) ON DELETE CASCADE,
+			action text NOT NULL,
+			field text NOT NULL,
+			value text NOT NULL,
+			created_at timestamptz NOT NULL
+		);
+
+		CREATE UNIQUE INDEX idx_keeper_upkeep_filters_unique ON keeper_upkeep_filters(registry_id, action, field, value);
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		DROP TABLE IF EXISTS keeper_upkeep_filters;
+
+		ALTER TABLE keeper_registrations
+			DROP COLUMN IF EXISTS target,
+			DROP COLUMN IF EXISTS admin;
+	`).Error
+}
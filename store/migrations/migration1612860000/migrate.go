@@ -0,0 +1,17 @@
+package migration1612860000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_memberships ADD COLUMN payload_template jsonb;
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_memberships DROP COLUMN IF EXISTS payload_template;
+	`).Error
+}
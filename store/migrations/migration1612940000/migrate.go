@@ -0,0 +1,17 @@
+package migration1612940000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_memberships ADD COLUMN job_endpoint text NOT NULL DEFAULT '';
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_memberships DROP COLUMN IF EXISTS job_endpoint;
+	`).Error
+}
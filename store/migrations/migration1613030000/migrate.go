@@ -0,0 +1,30 @@
+package migration1613030000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		CREATE TABLE keeper_job_runs (
+			id SERIAL PRIMARY KEY,
+			membership_id int NOT NULL REFERENCES keeper_memberships (id) ON DELETE CASCADE,
+			upkeep_id bigint NOT NULL,
+			block_number bigint NOT NULL,
+			run_id text NOT NULL,
+			status text NOT NULL,
+			tx_hash text NOT NULL DEFAULT '',
+			error text NOT NULL DEFAULT '',
+			created_at timestamptz NOT NULL,
+			updated_at timestamptz NOT NULL
+		);
+
+		CREATE INDEX idx_keeper_job_runs_status ON keeper_job_runs(status, id);
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		DROP TABLE IF EXISTS keeper_job_runs;
+	`).Error
+}
@@ -0,0 +1,32 @@
+package migration1613120000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		CREATE TABLE keeper_job_triggers (
+			id SERIAL PRIMARY KEY,
+			membership_id int NOT NULL REFERENCES keeper_memberships (id) ON DELETE CASCADE,
+			upkeep_id bigint NOT NULL,
+			block_number bigint NOT NULL,
+			payload bytea NOT NULL,
+			status text NOT NULL,
+			attempts int NOT NULL DEFAULT 0,
+			next_attempt_at timestamptz NOT NULL,
+			expires_at_block bigint NOT NULL DEFAULT 0,
+			last_error text NOT NULL DEFAULT '',
+			created_at timestamptz NOT NULL,
+			updated_at timestamptz NOT NULL
+		);
+
+		CREATE INDEX idx_keeper_job_triggers_status ON keeper_job_triggers(status, next_attempt_at);
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		DROP TABLE IF EXISTS keeper_job_triggers;
+	`).Error
+}
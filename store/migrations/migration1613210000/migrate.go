@@ -0,0 +1,21 @@
+package migration1613210000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_job_triggers ADD COLUMN idempotency_key text;
+		UPDATE keeper_job_triggers SET idempotency_key = 'trigger-' || id;
+		ALTER TABLE keeper_job_triggers ALTER COLUMN idempotency_key SET NOT NULL;
+
+		CREATE UNIQUE INDEX idx_keeper_job_triggers_unique_idempotency_key ON keeper_job_triggers(idempotency_key);
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_job_triggers DROP COLUMN IF EXISTS idempotency_key;
+	`).Error
+}
@@ -0,0 +1,33 @@
+package migration1613300000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		CREATE TABLE chainlink_nodes (
+			id SERIAL PRIMARY KEY,
+			name text NOT NULL,
+			url text NOT NULL,
+			initiator_to_chainlink_access_key text NOT NULL,
+			initiator_to_chainlink_secret text NOT NULL,
+			chainlink_to_initiator_access_key text NOT NULL,
+			chainlink_to_initiator_secret text NOT NULL,
+			created_at timestamptz NOT NULL,
+			updated_at timestamptz NOT NULL
+		);
+
+		CREATE UNIQUE INDEX idx_chainlink_nodes_unique_name ON chainlink_nodes(name);
+		CREATE UNIQUE INDEX idx_chainlink_nodes_unique_access_key ON chainlink_nodes(chainlink_to_initiator_access_key);
+
+		ALTER TABLE keeper_memberships ADD COLUMN node_id int REFERENCES chainlink_nodes (id);
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_memberships DROP COLUMN IF EXISTS node_id;
+		DROP TABLE IF EXISTS chainlink_nodes;
+	`).Error
+}
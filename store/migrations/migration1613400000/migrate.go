@@ -0,0 +1,69 @@
+package migration1613400000
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/smartcontractkit/chainlink/core/auth"
+	"github.com/smartcontractkit/chainlink/core/utils"
+)
+
+func Migrate(tx *gorm.DB) error {
+	err := tx.Exec(`
+		CREATE TABLE access_credentials (
+			id SERIAL PRIMARY KEY,
+			node_id int REFERENCES chainlink_nodes (id) ON DELETE CASCADE,
+			access_key text NOT NULL,
+			hashed_secret text NOT NULL,
+			salt text NOT NULL,
+			expires_at timestamptz,
+			revoked_at timestamptz,
+			created_at timestamptz NOT NULL
+		);
+
+		CREATE UNIQUE INDEX idx_access_credentials_unique_access_key ON access_credentials(access_key);
+	`).Error
+	if err != nil {
+		return err
+	}
+
+	// the plaintext credentials of the nodes are replaced with their salted hash
+	var nodes []struct {
+		ID                            uint32
+		ChainlinkToInitiatorAccessKey string
+		ChainlinkToInitiatorSecret    string
+	}
+	if err = tx.Table("chainlink_nodes").Find(&nodes).Error; err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		salt := utils.NewSecret(utils.DefaultSecretSize)
+		token := auth.Token{AccessKey: node.ChainlinkToInitiatorAccessKey, Secret: node.ChainlinkToInitiatorSecret}
+		hashedSecret, err := auth.HashedSecret(&token, salt)
+		if err != nil {
+			return err
+		}
+		err = tx.Exec(`
+			INSERT INTO access_credentials (node_id, access_key, hashed_secret, salt, created_at) VALUES (?, ?, ?, ?, NOW());
+		`, node.ID, token.AccessKey, hashedSecret, salt).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Exec(`
+		DROP INDEX idx_chainlink_nodes_unique_access_key;
+		ALTER TABLE chainlink_nodes DROP COLUMN chainlink_to_initiator_access_key;
+		ALTER TABLE chainlink_nodes DROP COLUMN chainlink_to_initiator_secret;
+	`).Error
+}
+
+// Rollback can't restore the secrets of the nodes, they have to be set again
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE chainlink_nodes ADD COLUMN chainlink_to_initiator_access_key text;
+		UPDATE chainlink_nodes SET chainlink_to_initiator_access_key = 'rolled-back-' || id;
+		ALTER TABLE chainlink_nodes ALTER COLUMN chainlink_to_initiator_access_key SET NOT NULL;
+		ALTER TABLE chainlink_nodes ADD COLUMN chainlink_to_initiator_secret text NOT NULL DEFAULT '';
+		CREATE UNIQUE INDEX idx_chainlink_nodes_unique_access_key ON chainlink_nodes(chainlink_to_initiator_access_key);
+		DROP TABLE IF EXISTS access_credentials;
+	`).Error
+}
@@ -0,0 +1,17 @@
+package migration1613500000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE access_credentials ADD COLUMN encrypted_signing_secret bytea;
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE access_credentials DROP COLUMN encrypted_signing_secret;
+	`).Error
+}
@@ -0,0 +1,18 @@
+package migration1613600000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_registrations ADD COLUMN target_synced boolean NOT NULL DEFAULT false;
+		UPDATE keeper_registrations SET target_synced = true WHERE target <> decode(repeat('00', 20), 'hex');
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_registrations DROP COLUMN target_synced;
+	`).Error
+}
@@ -0,0 +1,21 @@
+package migration1613700000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE chainlink_nodes
+			ADD COLUMN api_key text NOT NULL DEFAULT '',
+			ADD COLUMN api_secret text NOT NULL DEFAULT '';
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE chainlink_nodes
+			DROP COLUMN api_key,
+			DROP COLUMN api_secret;
+	`).Error
+}
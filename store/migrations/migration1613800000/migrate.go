@@ -0,0 +1,21 @@
+package migration1613800000
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Exec(`
+		ALTER TABLE keeper_job_runs ADD COLUMN last_polled_at timestamptz;
+
+		CREATE INDEX idx_keeper_job_runs_pending ON keeper_job_runs (last_polled_at NULLS FIRST, id) WHERE status = 'pending';
+	`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Exec(`
+		DROP INDEX IF EXISTS idx_keeper_job_runs_pending;
+
+		ALTER TABLE keeper_job_runs DROP COLUMN last_polled_at;
+	`).Error
+}